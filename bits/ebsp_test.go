@@ -235,6 +235,10 @@ func TestEBSPWriter(t *testing.T) {
 			in:  []byte{0, 0, 0, 0, 0},
 			out: []byte{0, 0, 3, 0, 0, 3, 0},
 		},
+		{
+			in:  []byte{0, 1, 0, 2},
+			out: []byte{0, 1, 0, 2},
+		},
 	}
 	for _, tc := range testCases {
 		buf := bytes.Buffer{}
@@ -248,3 +252,113 @@ func TestEBSPWriter(t *testing.T) {
 		}
 	}
 }
+
+func TestEBSPWriterGolomb(t *testing.T) {
+	unsigned := []uint{0, 1, 2, 7, 8, 14, 255, 65535, 1 << 20}
+	signed := []int{0, 1, -1, 4, -4, -7, 1000, -1000}
+	buf := bytes.Buffer{}
+	w := NewEBSPWriter(&buf)
+	for _, u := range unsigned {
+		w.WriteExpGolomb(u)
+	}
+	for _, s := range signed {
+		w.WriteSignedGolomb(s)
+	}
+	w.WriteFlag(true)
+	w.WriteRbspTrailingBits()
+	if w.Error() != nil {
+		t.Fatal(w.Error())
+	}
+	r := NewEBSPReader(&buf)
+	for _, u := range unsigned {
+		if got := r.MustReadExpGolomb(); got != u {
+			t.Errorf("got %d want %d", got, u)
+		}
+	}
+	for _, s := range signed {
+		if got := r.MustReadSignedGolomb(); got != s {
+			t.Errorf("got %d want %d", got, s)
+		}
+	}
+	if !r.MustReadFlag() {
+		t.Errorf("flag not set")
+	}
+}
+
+// TestSPSRoundTrip reads the start of a known H.264 SPS and writes it back
+func TestSPSRoundTrip(t *testing.T) {
+	const (
+		u = iota // fixed-length unsigned
+		f        // flag
+		e        // ue(v)
+	)
+	sps, _ := hex.DecodeString("67640020accac05005bb0169e0000003002000000c9c4c000432380008647c12401cb1c31380")
+	fields := []struct {
+		name string
+		kind int
+		n    int
+		want uint
+	}{
+		{"nal_header", u, 8, 0x67},
+		{"profile_idc", u, 8, 100},
+		{"constraint_flags", u, 8, 0},
+		{"level_idc", u, 8, 32},
+		{"seq_parameter_set_id", e, 0, 0},
+		{"chroma_format_idc", e, 0, 1},
+		{"bit_depth_luma_minus8", e, 0, 0},
+		{"bit_depth_chroma_minus8", e, 0, 0},
+		{"qpprime_y_zero_transform_bypass_flag", f, 0, 0},
+		{"seq_scaling_matrix_present_flag", f, 0, 0},
+		{"log2_max_frame_num_minus4", e, 0, 0},
+		{"pic_order_cnt_type", e, 0, 0},
+		{"log2_max_pic_order_cnt_lsb_minus4", e, 0, 4},
+		{"max_num_ref_frames", e, 0, 2},
+		{"gaps_in_frame_num_value_allowed_flag", f, 0, 0},
+		{"pic_width_in_mbs_minus1", e, 0, 79},
+		{"pic_height_in_map_units_minus1", e, 0, 44},
+		{"frame_mbs_only_flag", f, 0, 1},
+		{"direct_8x8_inference_flag", f, 0, 1},
+		{"frame_cropping_flag", f, 0, 0},
+		{"vui_parameters_present_flag", f, 0, 1},
+	}
+	r := NewEBSPReader(bytes.NewBuffer(sps))
+	buf := bytes.Buffer{}
+	w := NewEBSPWriter(&buf)
+	for _, fl := range fields {
+		var got uint
+		switch fl.kind {
+		case u:
+			got = r.MustRead(fl.n)
+			w.Write(got, fl.n)
+		case f:
+			flag := r.MustReadFlag()
+			if flag {
+				got = 1
+			}
+			w.WriteFlag(flag)
+		case e:
+			got = r.MustReadExpGolomb()
+			w.WriteExpGolomb(got)
+		}
+		if got != fl.want {
+			t.Errorf("%s: got %d want %d", fl.name, got, fl.want)
+		}
+	}
+	// Copy the remaining VUI bits unchanged
+	for {
+		b, err := r.Read(1)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write(b, 1)
+	}
+	if w.Error() != nil {
+		t.Fatal(w.Error())
+	}
+	if !bytes.Equal(buf.Bytes(), sps) {
+		t.Errorf("got %x want %x", buf.Bytes(), sps)
+	}
+}
@@ -52,6 +52,8 @@ func (w *EBSPWriter) Write(bits uint, n int) {
 		}
 		if b == 0 {
 			w.nr0++
+		} else {
+			w.nr0 = 0
 		}
 		w.n -= 8
 	}
@@ -70,3 +72,37 @@ func (w *EBSPWriter) StuffByteWithZeros() {
 		w.Write(0, 8-w.n)
 	}
 }
+
+// WriteFlag - write a single bit from a bool
+func (w *EBSPWriter) WriteFlag(f bool) {
+	var bit uint
+	if f {
+		bit = 1
+	}
+	w.Write(bit, 1)
+}
+
+// WriteExpGolomb - write an unsigned Exp-Golomb code ue(v)
+func (w *EBSPWriter) WriteExpGolomb(u uint) {
+	v := u + 1
+	nrBits := 0
+	for x := v; x > 0; x >>= 1 {
+		nrBits++
+	}
+	w.Write(0, nrBits-1)
+	w.Write(v, nrBits)
+}
+
+// WriteSignedGolomb - write a signed Exp-Golomb code se(v)
+func (w *EBSPWriter) WriteSignedGolomb(s int) {
+	if s > 0 {
+		w.WriteExpGolomb(uint(2*s - 1))
+		return
+	}
+	w.WriteExpGolomb(uint(-2 * s))
+}
+
+// Error - error that has occurred and stopped writing
+func (w *EBSPWriter) Error() error {
+	return w.err
+}
@@ -92,8 +92,7 @@ func (s *FixedSliceReader) ReadUint24() uint32 {
 		s.err = ErrSliceRead
 		return 0
 	}
-	res := uint32(binary.BigEndian.Uint16(s.slice[s.pos : s.pos+2]))
-	res |= res<<16 | uint32(s.slice[s.pos+2])
+	res := uint32(binary.BigEndian.Uint16(s.slice[s.pos:s.pos+2]))<<8 | uint32(s.slice[s.pos+2])
 	s.pos += 3
 	return res
 }
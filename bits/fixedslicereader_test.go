@@ -0,0 +1,17 @@
+package bits
+
+import "testing"
+
+func TestFixedSliceReaderUint24(t *testing.T) {
+	sr := NewFixedSliceReader([]byte{0x01, 0x02, 0x03, 0xff, 0xff, 0xff})
+	if got := sr.ReadUint24(); got != 0x010203 {
+		t.Errorf("got %06x instead of 010203", got)
+	}
+	if got := sr.ReadUint24(); got != 0xffffff {
+		t.Errorf("got %06x instead of ffffff", got)
+	}
+	sr.ReadUint24()
+	if sr.AccError() == nil {
+		t.Errorf("no read error beyond end")
+	}
+}
@@ -2,7 +2,6 @@
 package main
 
 import (
-	"bytes"
 	"flag"
 	"fmt"
 	"log"
@@ -190,10 +189,15 @@ func parseFragmentedMp4(f *mp4.File, trackID uint32, maxNrSamples int) error {
 
 func printWvttSample(sample []byte, nr int, pts uint64, dur uint32) error {
 	fmt.Printf("Sample %d, pts=%d, dur=%d\n", nr, pts, dur)
-	buf := bytes.NewBuffer(sample)
-	box, err := mp4.DecodeBox(0, buf)
+	boxes, err := mp4.DecodeWvttSample(sample)
 	if err != nil {
 		return err
 	}
-	return box.Info(os.Stdout, "", "  ", "  ")
+	for _, box := range boxes {
+		err = box.Info(os.Stdout, "", "  ", "  ")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
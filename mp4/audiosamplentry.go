@@ -16,6 +16,7 @@ type AudioSampleEntryBox struct {
 	SampleSize         uint16
 	SampleRate         uint16 // Integer part
 	Esds               *EsdsBox
+	Dops               *DopsBox
 	Sinf               *SinfBox
 	Children           []Box
 }
@@ -54,6 +55,8 @@ func (a *AudioSampleEntryBox) AddChild(child Box) {
 	switch child.Type() {
 	case "esds":
 		a.Esds = child.(*EsdsBox)
+	case "dOps":
+		a.Dops = child.(*DopsBox)
 	case "sinf":
 		a.Sinf = child.(*SinfBox)
 	}
@@ -64,7 +67,7 @@ func (a *AudioSampleEntryBox) AddChild(child Box) {
 const nrAudioSampleBytesBeforeChildren = 36
 
 // DecodeAudioSampleEntry - decode mp4a... box
-func DecodeAudioSampleEntry(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeAudioSampleEntry(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -89,7 +92,7 @@ func DecodeAudioSampleEntry(hdr boxHeader, startPos uint64, r io.Reader) (Box, e
 
 	pos := startPos + nrAudioSampleBytesBeforeChildren // Size of all previous data
 	for {
-		box, err := DecodeBox(pos, restReader)
+		box, err := decodeBox(pos, restReader, hdr.opts)
 		if err == io.EOF {
 			break
 		} else if err != nil {
@@ -109,7 +112,7 @@ func DecodeAudioSampleEntry(hdr boxHeader, startPos uint64, r io.Reader) (Box, e
 }
 
 // DecodeAudioSampleEntry - decode mp4a... box
-func DecodeAudioSampleEntrySR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeAudioSampleEntrySR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	a := NewAudioSampleEntryBox(hdr.name)
 
 	// 14496-12 8.5.2.2 Sample entry (8 bytes)
@@ -130,7 +133,7 @@ func DecodeAudioSampleEntrySR(hdr boxHeader, startPos uint64, sr bits.SliceReade
 		if pos >= lastPos {
 			break
 		}
-		box, err := DecodeBoxSR(pos, sr)
+		box, err := decodeBoxSR(pos, sr, hdr.opts)
 		if err != nil {
 			return nil, err
 		}
@@ -156,6 +159,11 @@ func (a *AudioSampleEntryBox) Size() uint64 {
 	return totalSize
 }
 
+// GetChildren - list of child boxes
+func (a *AudioSampleEntryBox) GetChildren() []Box {
+	return a.Children
+}
+
 // Encode - write box to w
 func (a *AudioSampleEntryBox) Encode(w io.Writer) error {
 	err := EncodeHeader(a, w)
@@ -227,6 +235,22 @@ func (a *AudioSampleEntryBox) Info(w io.Writer, specificBoxLevels, indent, inden
 	return nil
 }
 
+// OriginalFormat - original (unencrypted) sample entry type from sinf/frma if present, otherwise the box type
+func (a *AudioSampleEntryBox) OriginalFormat() string {
+	if a.Sinf != nil && a.Sinf.Frma != nil {
+		return a.Sinf.Frma.DataFormat
+	}
+	return a.name
+}
+
+// Tenc - tenc box in sinf/schi, or nil if not present
+func (a *AudioSampleEntryBox) Tenc() *TencBox {
+	if a.Sinf == nil {
+		return nil
+	}
+	return a.Sinf.Tenc()
+}
+
 // RemoveEncryption - remove sinf box and set type to unencrypted type
 func (a *AudioSampleEntryBox) RemoveEncryption() (*SinfBox, error) {
 	if a.name != "enca" {
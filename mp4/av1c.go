@@ -0,0 +1,147 @@
+package mp4
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// Av1CBox - AV1CodecConfigurationBox (av1C) as defined in AV1 Codec ISO Media File Format Binding Sec. 2.3
+type Av1CBox struct {
+	Version                          byte // 7 bits, shall be 1
+	SeqProfile                       byte // 3 bits
+	SeqLevelIdx0                     byte // 5 bits
+	SeqTier0                         byte // 1 bit
+	HighBitdepth                     byte // 1 bit
+	TwelveBit                        byte // 1 bit
+	Monochrome                       byte // 1 bit
+	ChromaSubsamplingX               byte // 1 bit
+	ChromaSubsamplingY               byte // 1 bit
+	ChromaSamplePosition             byte // 2 bits
+	Reserved                         byte // 3 bits, kept verbatim
+	InitialPresentationDelayPresent  bool
+	InitialPresentationDelayMinusOne byte // 4 bits, kept verbatim also if not present
+	ConfigOBUs                       []byte
+}
+
+// DecodeAv1C - box-specific decode
+func DecodeAv1C(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeAv1CSR(hdr, startPos, sr)
+}
+
+// DecodeAv1CSR - box-specific decode
+func DecodeAv1CSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	if hdr.PayloadLen() < 4 {
+		return nil, fmt.Errorf("av1C payload size %d is less than 4", hdr.PayloadLen())
+	}
+	b := Av1CBox{}
+	byte0 := sr.ReadUint8()
+	if byte0>>7 != 1 {
+		return nil, fmt.Errorf("av1C marker bit is not 1")
+	}
+	b.Version = byte0 & 0x7f
+	byte1 := sr.ReadUint8()
+	b.SeqProfile = byte1 >> 5
+	b.SeqLevelIdx0 = byte1 & 0x1f
+	byte2 := sr.ReadUint8()
+	b.SeqTier0 = byte2 >> 7
+	b.HighBitdepth = (byte2 >> 6) & 0x1
+	b.TwelveBit = (byte2 >> 5) & 0x1
+	b.Monochrome = (byte2 >> 4) & 0x1
+	b.ChromaSubsamplingX = (byte2 >> 3) & 0x1
+	b.ChromaSubsamplingY = (byte2 >> 2) & 0x1
+	b.ChromaSamplePosition = byte2 & 0x3
+	byte3 := sr.ReadUint8()
+	b.Reserved = byte3 >> 5
+	b.InitialPresentationDelayPresent = (byte3>>4)&0x1 == 1
+	b.InitialPresentationDelayMinusOne = byte3 & 0x0f
+	if nrOBUBytes := hdr.PayloadLen() - 4; nrOBUBytes > 0 {
+		b.ConfigOBUs = sr.ReadBytes(nrOBUBytes)
+	}
+	return &b, sr.AccError()
+}
+
+// Type - box type
+func (b *Av1CBox) Type() string {
+	return "av1C"
+}
+
+// Size - calculated size of box
+func (b *Av1CBox) Size() uint64 {
+	return uint64(boxHeaderSize + 4 + len(b.ConfigOBUs))
+}
+
+// Encode - write box to w
+func (b *Av1CBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *Av1CBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteUint8(0x80 | b.Version&0x7f)
+	sw.WriteUint8(b.SeqProfile<<5 | b.SeqLevelIdx0&0x1f)
+	sw.WriteUint8(b.SeqTier0<<7 | (b.HighBitdepth&0x1)<<6 | (b.TwelveBit&0x1)<<5 | (b.Monochrome&0x1)<<4 |
+		(b.ChromaSubsamplingX&0x1)<<3 | (b.ChromaSubsamplingY&0x1)<<2 | b.ChromaSamplePosition&0x3)
+	byte3 := b.Reserved<<5 | b.InitialPresentationDelayMinusOne&0x0f
+	if b.InitialPresentationDelayPresent {
+		byte3 |= 0x10
+	}
+	sw.WriteUint8(byte3)
+	sw.WriteBytes(b.ConfigOBUs)
+	return sw.AccError()
+}
+
+// BitDepth - 8, 10, or 12 depending on HighBitdepth and TwelveBit
+func (b *Av1CBox) BitDepth() int {
+	switch {
+	case b.HighBitdepth == 0:
+		return 8
+	case b.TwelveBit == 0:
+		return 10
+	default:
+		return 12
+	}
+}
+
+// CodecString - codecs parameter like av01.0.04M.08 as specified in AV1 ISOBMFF binding Annex A
+func (b *Av1CBox) CodecString(sampleEntry string) string {
+	tier := "M"
+	if b.SeqTier0 == 1 {
+		tier = "H"
+	}
+	return fmt.Sprintf("%s.%d.%02d%s.%02d", sampleEntry, b.SeqProfile, b.SeqLevelIdx0, tier, b.BitDepth())
+}
+
+// Info - write box-specific information
+func (b *Av1CBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - version: %d", b.Version)
+	bd.write(" - seqProfile: %d seqLevelIdx0: %d seqTier0: %d", b.SeqProfile, b.SeqLevelIdx0, b.SeqTier0)
+	bd.write(" - bitDepth: %d monochrome: %d", b.BitDepth(), b.Monochrome)
+	bd.write(" - chromaSubsampling: %d%d chromaSamplePosition: %d",
+		b.ChromaSubsamplingX, b.ChromaSubsamplingY, b.ChromaSamplePosition)
+	if b.InitialPresentationDelayPresent {
+		bd.write(" - initialPresentationDelay: %d", b.InitialPresentationDelayMinusOne+1)
+	}
+	if getInfoLevel(b, specificBoxLevels) > 0 {
+		bd.write(" - configOBUs: %s", hex.EncodeToString(b.ConfigOBUs))
+	}
+	return bd.err
+}
@@ -0,0 +1,46 @@
+package mp4
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestAv1C(t *testing.T) {
+	// av1C with a sequence header OBU from an 8-bit 4:2:0 Main profile level 5.0 stream
+	av1CHex := "0000001761763143" + "810c0c00" + "0a0b00000024c43ffffc40"
+	data, err := hex.DecodeString(av1CHex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	box, err := DecodeBox(0, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	av1C := box.(*Av1CBox)
+	if av1C.Version != 1 || av1C.SeqProfile != 0 || av1C.SeqLevelIdx0 != 12 || av1C.BitDepth() != 8 ||
+		av1C.ChromaSubsamplingX != 1 || av1C.ChromaSubsamplingY != 1 || len(av1C.ConfigOBUs) != 11 {
+		t.Errorf("unexpected av1C values %+v", av1C)
+	}
+	if cs := av1C.CodecString("av01"); cs != "av01.0.12M.08" {
+		t.Errorf("got codec string %s", cs)
+	}
+	buf := bytes.Buffer{}
+	assertNoError(t, av1C.Encode(&buf))
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("got %x instead of %x", buf.Bytes(), data)
+	}
+
+	av1C = &Av1CBox{Version: 1, SeqProfile: 2, SeqLevelIdx0: 9, SeqTier0: 1, HighBitdepth: 1, TwelveBit: 1,
+		ChromaSamplePosition: 2, InitialPresentationDelayPresent: true, InitialPresentationDelayMinusOne: 3}
+	boxDiffAfterEncodeAndDecode(t, av1C)
+	if cs := av1C.CodecString("av01"); cs != "av01.2.09H.12" {
+		t.Errorf("got codec string %s", cs)
+	}
+
+	av01 := CreateVisualSampleEntryBox("av01", 1920, 1080, av1C)
+	if av01.Av1C != av1C {
+		t.Errorf("av1C not set in av01")
+	}
+	boxDiffAfterEncodeAndDecode(t, av01)
+}
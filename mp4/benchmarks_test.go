@@ -2,6 +2,7 @@ package mp4
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
 	"testing"
 )
@@ -72,3 +73,67 @@ func BenchmarkEncodeFile(b *testing.B) {
 		})
 	}
 }
+
+func makeBenchSamples(n int) []Sample {
+	samples := make([]Sample, n)
+	for i := range samples {
+		samples[i] = Sample{SyncSampleFlags, 1024, uint32(100 + i%50), 0}
+	}
+	return samples
+}
+
+func BenchmarkTrunAddSample(b *testing.B) {
+	samples := makeBenchSamples(5000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		trun := CreateTrun(0)
+		for _, s := range samples {
+			trun.AddSample(s)
+		}
+	}
+}
+
+func BenchmarkTrunAddSamples(b *testing.B) {
+	samples := makeBenchSamples(5000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		trun := CreateTrun(0)
+		trun.AddSamples(samples)
+	}
+}
+
+// makeManyFragmentsFile - fragmented file with nrFrags one-track fragments of 50 samples each
+func makeManyFragmentsFile(nrFrags int) *File {
+	f := NewFile()
+	f.isFragmented = true
+	f.Init = CreateEmptyInit()
+	f.Init.AddEmptyTrack(90000, "video", "und")
+	samples := makeBenchSamples(50)
+	var decodeTime uint64
+	for i := 0; i < nrFrags; i++ {
+		frag, _ := CreateFragment(uint32(i+1), 1)
+		for _, s := range samples {
+			frag.AddFullSample(FullSample{Sample: s, DecodeTime: decodeTime, Data: make([]byte, s.Size)})
+			decodeTime += uint64(s.Dur)
+		}
+		seg := NewMediaSegment()
+		seg.AddFragment(frag)
+		f.AddMediaSegment(seg)
+	}
+	return f
+}
+
+// BenchmarkEncodeManyFragments - encode time per fragment should not grow with the number of fragments,
+// since sizes are computed from the subtree of each box and every fragment is encoded on its own.
+func BenchmarkEncodeManyFragments(b *testing.B) {
+	for _, nrFrags := range []int{100, 1000, 10000} {
+		f := makeManyFragmentsFile(nrFrags)
+		var buf bytes.Buffer
+		b.Run(fmt.Sprintf("%dfrags", nrFrags), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				buf.Reset()
+				_ = f.Encode(&buf)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package mp4
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -20,6 +21,8 @@ var decoders map[string]BoxDecoder
 
 func init() {
 	decoders = map[string]BoxDecoder{
+		"av01":    DecodeVisualSampleEntry,
+		"av1C":    DecodeAv1C,
 		"avc1":    DecodeVisualSampleEntry,
 		"avc3":    DecodeVisualSampleEntry,
 		"avcC":    DecodeAvcC,
@@ -27,12 +30,17 @@ func init() {
 		"cdat":    DecodeCdat,
 		"cdsc":    DecodeTrefType,
 		"clap":    DecodeClap,
+		"cmov":    DecodeCmov,
+		"cmvd":    DecodeCmvd,
+		"colr":    DecodeColr,
 		"cslg":    DecodeCslg,
 		"co64":    DecodeCo64,
 		"ctim":    DecodeCtim,
 		"ctts":    DecodeCtts,
 		"data":    DecodeData,
+		"dcom":    DecodeDcom,
 		"dinf":    DecodeDinf,
+		"dOps":    DecodeDops,
 		"dpnd":    DecodeTrefType,
 		"dref":    DecodeDref,
 		"elng":    DecodeElng,
@@ -73,6 +81,7 @@ func init() {
 		"mvex":    DecodeMvex,
 		"mvhd":    DecodeMvhd,
 		"mp4a":    DecodeAudioSampleEntry,
+		"Opus":    DecodeAudioSampleEntry,
 		"nmhd":    DecodeNmhd,
 		"pasp":    DecodePasp,
 		"payl":    DecodePayl,
@@ -98,6 +107,7 @@ func init() {
 		"stsd":    DecodeStsd,
 		"stss":    DecodeStss,
 		"stsz":    DecodeStsz,
+		"stz2":    DecodeStz2,
 		"sttg":    DecodeSttg,
 		"stts":    DecodeStts,
 		"styp":    DecodeStyp,
@@ -132,26 +142,38 @@ func init() {
 	}
 }
 
-// boxHeader - 8 or 16 bytes depending on size
-type boxHeader struct {
+// BoxHeader - 8 or 16 bytes depending on size
+type BoxHeader struct {
 	name   string
 	size   uint64
 	hdrlen int
+	opts   *DecodeOptions // Decode options passed on to child boxes, nil means defaults
 }
 
-func (b boxHeader) payloadLen() int {
+// Name - box type
+func (b BoxHeader) Name() string {
+	return b.name
+}
+
+// Size - total size of box including header
+func (b BoxHeader) Size() uint64 {
+	return b.size
+}
+
+// PayloadLen - size of box excluding header
+func (b BoxHeader) PayloadLen() int {
 	return int(b.size) - b.hdrlen
 }
 
 // decodeHeader decodes a box header (size + box type + possiible largeSize)
-func decodeHeader(r io.Reader) (boxHeader, error) {
+func decodeHeader(r io.Reader) (BoxHeader, error) {
 	buf := make([]byte, boxHeaderSize)
 	n, err := r.Read(buf)
 	if err != nil {
-		return boxHeader{}, err
+		return BoxHeader{}, err
 	}
 	if n != boxHeaderSize {
-		return boxHeader{}, errors.New("Could not read full 8B header")
+		return BoxHeader{}, errors.New("Could not read full 8B header")
 	}
 	size := uint64(binary.BigEndian.Uint32(buf[0:4]))
 	headerLen := boxHeaderSize
@@ -159,17 +181,20 @@ func decodeHeader(r io.Reader) (boxHeader, error) {
 		buf := make([]byte, largeSizeLen)
 		n, err := r.Read(buf)
 		if err != nil {
-			return boxHeader{}, err
+			return BoxHeader{}, err
 		}
 		if n != largeSizeLen {
-			return boxHeader{}, fmt.Errorf("Could not read largeSize length field")
+			return BoxHeader{}, fmt.Errorf("Could not read largeSize length field")
 		}
 		size = binary.BigEndian.Uint64(buf)
 		headerLen += largeSizeLen
 	} else if size == 0 {
-		return boxHeader{}, fmt.Errorf("Size 0, meaning to end of file, not supported")
+		return BoxHeader{}, fmt.Errorf("Size 0, meaning to end of file, not supported")
 	}
-	return boxHeader{string(buf[4:8]), size, headerLen}, nil
+	if size < uint64(headerLen) {
+		return BoxHeader{}, fmt.Errorf("box %q size %d is smaller than header size %d", string(buf[4:8]), size, headerLen)
+	}
+	return BoxHeader{name: string(buf[4:8]), size: size, hdrlen: headerLen}, nil
 }
 
 // EncodeHeader - encode a box header to a writer
@@ -263,10 +288,75 @@ type Informer interface {
 }
 
 // BoxDecoder is function signature of the Box Decode method
-type BoxDecoder func(hdr boxHeader, startPos uint64, r io.Reader) (Box, error)
+type BoxDecoder func(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error)
+
+// RegisterBoxDecoder - set decoder for boxType, replacing any built-in decoder.
+// The decoder is used by both DecodeBox and DecodeBoxSR. In the latter case, the payload is
+// read from the SliceReader and provided to decoder as an io.Reader.
+// Registration is not safe for concurrent use, and should be done before any decoding starts,
+// preferably in an init function.
+func RegisterBoxDecoder(boxType string, decoder BoxDecoder) {
+	decoders[boxType] = decoder
+	decodersSR[boxType] = func(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+		data := sr.ReadBytes(hdr.PayloadLen())
+		if err := sr.AccError(); err != nil {
+			return nil, err
+		}
+		return decoder(hdr, startPos, bytes.NewReader(data))
+	}
+}
 
 // DecodeBox decodes a box
 func DecodeBox(startPos uint64, r io.Reader) (Box, error) {
+	return decodeBox(startPos, r, nil)
+}
+
+// DecodeOptions - options for DecodeBoxWithOptions and DecodeBoxSRWithOptions.
+// The options apply to the box and all its descendants.
+type DecodeOptions struct {
+	// UnknownStats - if not nil, unknown boxes at any level are tallied per box type
+	UnknownStats map[string]UnknownBoxStat
+	// KeepBoxOrder - keep the original order of the moov children instead of moving trak boxes together.
+	// Encoding the decoded moov then gives back the original bytes.
+	KeepBoxOrder bool
+	// LenientTrun - repair trun boxes whose flags signal more per-sample fields than the box size allows,
+	// instead of returning an error. See TrunBox.NormalizeFlags.
+	LenientTrun bool
+	// RepairedTruns - if not nil, incremented for every trun repaired because of LenientTrun
+	RepairedTruns *int
+}
+
+// keepBoxOrder - true if o is not nil and KeepBoxOrder is set
+func (o *DecodeOptions) keepBoxOrder() bool {
+	return o != nil && o.KeepBoxOrder
+}
+
+// lenientTrun - true if o is not nil and LenientTrun is set
+func (o *DecodeOptions) lenientTrun() bool {
+	return o != nil && o.LenientTrun
+}
+
+// addRepairedTrun - count a repaired trun if o is not nil and has RepairedTruns set
+func (o *DecodeOptions) addRepairedTrun() {
+	if o == nil || o.RepairedTruns == nil {
+		return
+	}
+	*o.RepairedTruns++
+}
+
+// UnknownBoxStat - number and total size of unknown boxes of one type
+type UnknownBoxStat struct {
+	Count      int
+	TotalBytes uint64
+}
+
+// DecodeBoxWithOptions decodes a box like DecodeBox and applies opts
+func DecodeBoxWithOptions(startPos uint64, r io.Reader, opts DecodeOptions) (Box, error) {
+	return decodeBox(startPos, r, &opts)
+}
+
+// decodeBox - decode a box and pass opts on to the decoder via the box header
+func decodeBox(startPos uint64, r io.Reader, opts *DecodeOptions) (Box, error) {
 	var err error
 	var b Box
 
@@ -274,11 +364,15 @@ func DecodeBox(startPos uint64, r io.Reader) (Box, error) {
 	if err != nil {
 		return nil, err
 	}
+	h.opts = opts
 
 	d, ok := decoders[h.name]
 
 	if !ok {
 		b, err = DecodeUnknown(h, startPos, r)
+		if err == nil {
+			opts.addUnknownStat(b)
+		}
 	} else {
 		b, err = d(h, startPos, r)
 	}
@@ -289,8 +383,24 @@ func DecodeBox(startPos uint64, r io.Reader) (Box, error) {
 	return b, nil
 }
 
+// addUnknownStat - tally the unknown box b if o is not nil and has UnknownStats set
+func (o *DecodeOptions) addUnknownStat(b Box) {
+	if o == nil || o.UnknownStats == nil {
+		return
+	}
+	stat := o.UnknownStats[b.Type()]
+	stat.Count++
+	stat.TotalBytes += b.Size()
+	o.UnknownStats[b.Type()] = stat
+}
+
 // DecodeBoxLazyMdat decodes a box but doesn't read mdat into memory
 func DecodeBoxLazyMdat(startPos uint64, r io.ReadSeeker) (Box, error) {
+	return decodeBoxLazyMdat(startPos, r, nil)
+}
+
+// decodeBoxLazyMdat - decode a box like DecodeBoxLazyMdat and pass opts on to the decoder
+func decodeBoxLazyMdat(startPos uint64, r io.ReadSeeker, opts *DecodeOptions) (Box, error) {
 	var err error
 	var b Box
 
@@ -298,6 +408,7 @@ func DecodeBoxLazyMdat(startPos uint64, r io.ReadSeeker) (Box, error) {
 	if err != nil {
 		return nil, err
 	}
+	h.opts = opts
 
 	d, ok := decoders[h.name]
 
@@ -305,6 +416,9 @@ func DecodeBoxLazyMdat(startPos uint64, r io.ReadSeeker) (Box, error) {
 
 	if !ok {
 		b, err = DecodeUnknown(h, startPos, r)
+		if err == nil {
+			opts.addUnknownStat(b)
+		}
 	} else {
 		switch h.name {
 		case "mdat":
@@ -350,7 +464,7 @@ func makebuf(b Box) []byte {
 }
 
 // readBoxBody - read box body and check length
-func readBoxBody(r io.Reader, h boxHeader) ([]byte, error) {
+func readBoxBody(r io.Reader, h BoxHeader) ([]byte, error) {
 	bodyLen := h.size - uint64(h.hdrlen)
 	if bodyLen == 0 {
 		return nil, nil
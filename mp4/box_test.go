@@ -0,0 +1,121 @@
+package mp4
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// testCustomBox - box with a single uint32 value used to test RegisterBoxDecoder
+type testCustomBox struct {
+	Value uint32
+}
+
+func decodeTestCustomBox(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	if hdr.PayloadLen() != 4 {
+		return nil, fmt.Errorf("payload length %d", hdr.PayloadLen())
+	}
+	b := &testCustomBox{}
+	err := binary.Read(r, binary.BigEndian, &b.Value)
+	return b, err
+}
+
+func (b *testCustomBox) Type() string { return "xtst" }
+
+func (b *testCustomBox) Size() uint64 { return boxHeaderSize + 4 }
+
+func (b *testCustomBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+func (b *testCustomBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteUint32(b.Value)
+	return sw.AccError()
+}
+
+func (b *testCustomBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - value: %d", b.Value)
+	return bd.err
+}
+
+func TestRegisterBoxDecoder(t *testing.T) {
+	udta := &UdtaBox{}
+	udta.AddChild(&testCustomBox{Value: 42})
+	var buf bytes.Buffer
+	err := udta.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	box, err := DecodeBox(0, bytes.NewBuffer(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := box.(*UdtaBox).Children[0].(*UnknownBox); !ok {
+		t.Errorf("unregistered box not decoded as UnknownBox")
+	}
+
+	RegisterBoxDecoder("xtst", decodeTestCustomBox)
+	defer func() {
+		delete(decoders, "xtst")
+		delete(decodersSR, "xtst")
+	}()
+
+	box, err = DecodeBox(0, bytes.NewBuffer(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	boxSR, err := DecodeBoxSR(0, bits.NewFixedSliceReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range []Box{box, boxSR} {
+		custom, ok := b.(*UdtaBox).Children[0].(*testCustomBox)
+		if !ok {
+			t.Errorf("registered box decoded as %T", b.(*UdtaBox).Children[0])
+			continue
+		}
+		if custom.Value != 42 {
+			t.Errorf("got value %d instead of 42", custom.Value)
+		}
+	}
+}
+
+func TestRegisterBoxDecoderOverride(t *testing.T) {
+	builtIn := decoders["pasp"]
+	builtInSR := decodersSR["pasp"]
+	defer func() {
+		decoders["pasp"] = builtIn
+		decodersSR["pasp"] = builtInSR
+	}()
+	RegisterBoxDecoder("pasp", DecodeUnknown)
+
+	var buf bytes.Buffer
+	err := (&PaspBox{HSpacing: 1, VSpacing: 1}).Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	box, err := DecodeBox(0, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := box.(*UnknownBox); !ok {
+		t.Errorf("built-in decoder not overridden, got %T", box)
+	}
+}
@@ -0,0 +1,161 @@
+package mp4
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// BoxesEqual - compare the box trees a and b field by field.
+// If they differ, the returned string gives the path to the first difference and both values,
+// like "moof/traf/trun.DataOffset: 120 != 124". Children of the same type are numbered from 1,
+// like "moof/traf[2]". Exported fields are compared, except those pointing to child boxes,
+// since all children are compared recursively. Boxes without children, like unknown boxes,
+// are also compared in encoded form to cover values that are not exported.
+// If such a box cannot be encoded, the boxes are not equal and the string gives the encode error.
+func BoxesEqual(a, b Box) (bool, string) {
+	if a == nil || b == nil {
+		if a == nil && b == nil {
+			return true, ""
+		}
+		return false, fmt.Sprintf("box: %s != %s", boxTypeOrNil(a), boxTypeOrNil(b))
+	}
+	diff := boxDiff(a.Type(), a, b)
+	return diff == "", diff
+}
+
+// boxDiff - description of first difference between a and b at path, or empty string if none
+func boxDiff(path string, a, b Box) string {
+	if a.Type() != b.Type() {
+		return fmt.Sprintf("%s: type %s != %s", path, a.Type(), b.Type())
+	}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Type() != vb.Type() {
+		return fmt.Sprintf("%s: %T != %T", path, a, b)
+	}
+	if va.Kind() == reflect.Ptr {
+		va, vb = va.Elem(), vb.Elem()
+	}
+	if va.Kind() == reflect.Struct {
+		t := va.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			fa, fb := va.Field(i), vb.Field(i)
+			if field.PkgPath != "" || field.Name == "Children" || isBoxJSONChild(fa) || isBoxJSONChild(fb) {
+				continue
+			}
+			if diff := valueDiff(path+"."+field.Name, fa, fb); diff != "" {
+				return diff
+			}
+		}
+	}
+	ca, cb := getChildren(a), getChildren(b)
+	if len(ca) != len(cb) {
+		return fmt.Sprintf("%s: %d children != %d", path, len(ca), len(cb))
+	}
+	if len(ca) == 0 {
+		return encodedDiff(path, a, b)
+	}
+	typeCount := make(map[string]int)
+	for _, c := range ca {
+		typeCount[c.Type()]++
+	}
+	typeNr := make(map[string]int)
+	for i := range ca {
+		childPath := path + "/" + ca[i].Type()
+		if typeCount[ca[i].Type()] > 1 {
+			typeNr[ca[i].Type()]++
+			childPath += fmt.Sprintf("[%d]", typeNr[ca[i].Type()])
+		}
+		if diff := boxDiff(childPath, ca[i], cb[i]); diff != "" {
+			return diff
+		}
+	}
+	return ""
+}
+
+// valueDiff - description of first difference between non-box values a and b, or empty string if none
+func valueDiff(path string, a, b reflect.Value) string {
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				return fmt.Sprintf("%s: %v != %v", path, a.Interface(), b.Interface())
+			}
+			return ""
+		}
+		if a.Elem().Type() != b.Elem().Type() {
+			return fmt.Sprintf("%s: %s != %s", path, a.Elem().Type(), b.Elem().Type())
+		}
+		return valueDiff(path, a.Elem(), b.Elem())
+	case reflect.Struct:
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).PkgPath != "" {
+				continue
+			}
+			if diff := valueDiff(path+"."+t.Field(i).Name, a.Field(i), b.Field(i)); diff != "" {
+				return diff
+			}
+		}
+		return ""
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() {
+			return fmt.Sprintf("%s: length %d != %d", path, a.Len(), b.Len())
+		}
+		for i := 0; i < a.Len(); i++ {
+			if diff := valueDiff(fmt.Sprintf("%s[%d]", path, i), a.Index(i), b.Index(i)); diff != "" {
+				return diff
+			}
+		}
+		return ""
+	default:
+		if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			return fmt.Sprintf("%s: %v != %v", path, a.Interface(), b.Interface())
+		}
+		return ""
+	}
+}
+
+// encodedDiff - description of first difference between the encoded boxes a and b, or empty string if none.
+// mdat is left out, since its data has already been compared. An encode error is described as a difference.
+func encodedDiff(path string, a, b Box) string {
+	if _, ok := a.(*MdatBox); ok {
+		return ""
+	}
+	ea, err := encodeBoxToBytes(a)
+	if err != nil {
+		return fmt.Sprintf("%s: encode error in first box: %v", path, err)
+	}
+	eb, err := encodeBoxToBytes(b)
+	if err != nil {
+		return fmt.Sprintf("%s: encode error in second box: %v", path, err)
+	}
+	if bytes.Equal(ea, eb) {
+		return ""
+	}
+	if len(ea) != len(eb) {
+		return fmt.Sprintf("%s: encoded size %d != %d", path, len(ea), len(eb))
+	}
+	for i := range ea {
+		if ea[i] != eb[i] {
+			return fmt.Sprintf("%s: encoded byte %d: %02x != %02x", path, i, ea[i], eb[i])
+		}
+	}
+	return ""
+}
+
+func encodeBoxToBytes(b Box) ([]byte, error) {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	return sw.Bytes(), err
+}
+
+func boxTypeOrNil(b Box) string {
+	if b == nil {
+		return "nil"
+	}
+	return b.Type()
+}
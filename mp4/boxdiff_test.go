@@ -0,0 +1,69 @@
+package mp4
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+func TestBoxesEqual(t *testing.T) {
+	decodeTwice := func() (*MoofBox, *MoofBox) {
+		var moofs []*MoofBox
+		for i := 0; i < 2; i++ {
+			fd, err := os.Open("testdata/1.m4s")
+			assertNoError(t, err)
+			f, err := DecodeFile(fd)
+			fd.Close()
+			assertNoError(t, err)
+			moofs = append(moofs, f.Segments[0].Fragments[0].Moof)
+		}
+		return moofs[0], moofs[1]
+	}
+	a, b := decodeTwice()
+	equal, diff := BoxesEqual(a, b)
+	if !equal {
+		t.Errorf("decoded twice differs: %s", diff)
+	}
+	b.Traf.Trun.DataOffset += 4
+	_, diff = BoxesEqual(a, b)
+	wanted := fmt.Sprintf("moof/traf/trun.DataOffset: %d != %d", a.Traf.Trun.DataOffset, b.Traf.Trun.DataOffset)
+	if diff != wanted {
+		t.Errorf("got diff %q instead of %q", diff, wanted)
+	}
+
+	wa := NewWvttBox()
+	wa.AddChild(&VttCBox{Config: "WEBVTT"})
+	wb := NewWvttBox()
+	wb.AddChild(&VttCBox{Config: "WEBVTT\n\nSTYLE"})
+	_, diff = BoxesEqual(wa, wb)
+	if diff != "wvtt/vttC.Config: WEBVTT != WEBVTT\n\nSTYLE" {
+		t.Errorf("got diff %q", diff)
+	}
+
+	fa := &FreeBox{Name: "free", notDecoded: []byte{1, 2}}
+	fb := &FreeBox{Name: "free", notDecoded: []byte{1, 3}}
+	_, diff = BoxesEqual(fa, fb)
+	if diff != "free: encoded byte 9: 02 != 03" {
+		t.Errorf("got diff %q", diff)
+	}
+	if equal, _ := BoxesEqual(fa, &FreeBox{Name: "free", notDecoded: []byte{1, 2}}); !equal {
+		t.Error("equal free boxes differ")
+	}
+	ua := &UnknownBox{BoxType: "abcd", RawData: []byte{1, 2}}
+	ub := &UnknownBox{BoxType: "abcd", RawData: []byte{1, 3}}
+	_, diff = BoxesEqual(ua, ub)
+	if diff != "abcd.RawData[1]: 2 != 3" {
+		t.Errorf("got diff %q", diff)
+	}
+	_, diff = BoxesEqual(ua, nil)
+	if diff != "box: abcd != nil" {
+		t.Errorf("got diff %q", diff)
+	}
+	// Boxes that cannot be encoded are not equal
+	sa := &Stz2Box{FieldSize: 3, SampleSize: []uint32{1}}
+	sb := &Stz2Box{FieldSize: 3, SampleSize: []uint32{1}}
+	equal, diff = BoxesEqual(sa, sb)
+	if equal || diff != "stz2: encode error in first box: stz2: field size 3 not 4, 8, or 16" {
+		t.Errorf("got equal=%t diff %q", equal, diff)
+	}
+}
@@ -10,6 +10,8 @@ var decodersSR map[string]BoxDecoderSR
 
 func init() {
 	decodersSR = map[string]BoxDecoderSR{
+		"av01":    DecodeVisualSampleEntrySR,
+		"av1C":    DecodeAv1CSR,
 		"avc1":    DecodeVisualSampleEntrySR,
 		"avc3":    DecodeVisualSampleEntrySR,
 		"avcC":    DecodeAvcCSR,
@@ -17,12 +19,17 @@ func init() {
 		"cdat":    DecodeCdatSR,
 		"cdsc":    DecodeTrefTypeSR,
 		"clap":    DecodeClapSR,
+		"cmov":    DecodeCmovSR,
+		"cmvd":    DecodeCmvdSR,
+		"colr":    DecodeColrSR,
 		"cslg":    DecodeCslgSR,
 		"co64":    DecodeCo64SR,
 		"ctim":    DecodeCtimSR,
 		"ctts":    DecodeCttsSR,
 		"data":    DecodeDataSR,
+		"dcom":    DecodeDcomSR,
 		"dinf":    DecodeDinfSR,
+		"dOps":    DecodeDopsSR,
 		"dpnd":    DecodeTrefTypeSR,
 		"dref":    DecodeDrefSR,
 		"elng":    DecodeElngSR,
@@ -63,6 +70,7 @@ func init() {
 		"mvex":    DecodeMvexSR,
 		"mvhd":    DecodeMvhdSR,
 		"mp4a":    DecodeAudioSampleEntrySR,
+		"Opus":    DecodeAudioSampleEntrySR,
 		"nmhd":    DecodeNmhdSR,
 		"pasp":    DecodePaspSR,
 		"payl":    DecodePaylSR,
@@ -88,6 +96,7 @@ func init() {
 		"stsd":    DecodeStsdSR,
 		"stss":    DecodeStssSR,
 		"stsz":    DecodeStszSR,
+		"stz2":    DecodeStz2SR,
 		"sttg":    DecodeSttgSR,
 		"stts":    DecodeSttsSR,
 		"styp":    DecodeStypSR,
@@ -123,10 +132,20 @@ func init() {
 }
 
 // BoxDecoderSR is function signature of the Box DecodeSR method
-type BoxDecoderSR func(hdr boxHeader, startPos uint64, sw bits.SliceReader) (Box, error)
+type BoxDecoderSR func(hdr BoxHeader, startPos uint64, sw bits.SliceReader) (Box, error)
 
 // DecodeBoxSR - decode a box from SliceReader
 func DecodeBoxSR(startPos uint64, sr bits.SliceReader) (Box, error) {
+	return decodeBoxSR(startPos, sr, nil)
+}
+
+// DecodeBoxSRWithOptions decodes a box like DecodeBoxSR and applies opts
+func DecodeBoxSRWithOptions(startPos uint64, sr bits.SliceReader, opts DecodeOptions) (Box, error) {
+	return decodeBoxSR(startPos, sr, &opts)
+}
+
+// decodeBoxSR - decode a box and pass opts on to the decoder via the box header
+func decodeBoxSR(startPos uint64, sr bits.SliceReader, opts *DecodeOptions) (Box, error) {
 	var err error
 	var b Box
 
@@ -134,11 +153,15 @@ func DecodeBoxSR(startPos uint64, sr bits.SliceReader) (Box, error) {
 	if err != nil {
 		return nil, err
 	}
+	h.opts = opts
 
 	d, ok := decodersSR[h.name]
 
 	if !ok {
 		b, err = DecodeUnknownSR(h, startPos, sr)
+		if err == nil {
+			opts.addUnknownStat(b)
+		}
 	} else {
 		b, err = d(h, startPos, sr)
 	}
@@ -150,7 +173,7 @@ func DecodeBoxSR(startPos uint64, sr bits.SliceReader) (Box, error) {
 }
 
 // decodeHeaderSR - decode a box header (size + box type + possible largeSize) from sr
-func decodeHeaderSR(sr bits.SliceReader) (boxHeader, error) {
+func decodeHeaderSR(sr bits.SliceReader) (BoxHeader, error) {
 	size := uint64(sr.ReadUint32())
 	boxType := sr.ReadFixedLengthString(4)
 	headerLen := boxHeaderSize
@@ -158,9 +181,12 @@ func decodeHeaderSR(sr bits.SliceReader) (boxHeader, error) {
 		size = sr.ReadUint64()
 		headerLen += largeSizeLen
 	} else if size == 0 {
-		return boxHeader{}, fmt.Errorf("Size 0, meaning to end of file, not supported")
+		return BoxHeader{}, fmt.Errorf("Size 0, meaning to end of file, not supported")
+	}
+	if size < uint64(headerLen) {
+		return BoxHeader{}, fmt.Errorf("box %q size %d is smaller than header size %d", boxType, size, headerLen)
 	}
-	return boxHeader{boxType, size, headerLen}, nil
+	return BoxHeader{name: boxType, size: size, hdrlen: headerLen}, nil
 }
 
 // DecodeFile - parse and decode a file from reader r with optional file options.
@@ -186,7 +212,7 @@ LoopBoxes:
 			break LoopBoxes
 		}
 
-		box, err = DecodeBoxSR(boxStartPos, sr)
+		box, err = decodeBoxSR(boxStartPos, sr, &f.decOptions)
 		if err != nil {
 			return nil, err
 		}
@@ -221,8 +247,13 @@ LoopBoxes:
 			}
 		}
 		f.AddChild(box, boxStartPos)
-		lastBoxType = boxType
+		if boxType != "prft" { // prft is accepted anywhere, also between moof and mdat
+			lastBoxType = boxType
+		}
 		boxStartPos += boxSize
+		if f.progress != nil {
+			f.progress(boxStartPos, uint64(sr.Length()))
+		}
 	}
 	return f, nil
 }
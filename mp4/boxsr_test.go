@@ -37,3 +37,24 @@ func TestDecodeEncodeSRW(t *testing.T) {
 		}
 	}
 }
+
+// TestBoxSizeSmallerThanHeader - size smaller than header should give an error
+func TestBoxSizeSmallerThanHeader(t *testing.T) {
+	testCases := []struct {
+		desc string
+		data []byte
+	}{
+		{"size 4", []byte{0, 0, 0, 4, 'f', 'r', 'e', 'e'}},
+		{"largesize 12", []byte{0, 0, 0, 1, 'f', 'r', 'e', 'e', 0, 0, 0, 0, 0, 0, 0, 12}},
+	}
+	for _, tc := range testCases {
+		_, err := DecodeBox(0, bytes.NewReader(tc.data))
+		if err == nil {
+			t.Errorf("%s: no error from DecodeBox", tc.desc)
+		}
+		_, err = DecodeBoxSR(0, bits.NewFixedSliceReader(tc.data))
+		if err == nil {
+			t.Errorf("%s: no error from DecodeBoxSR", tc.desc)
+		}
+	}
+}
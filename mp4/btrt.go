@@ -14,7 +14,7 @@ type BtrtBox struct {
 }
 
 // DecodeBtrt - box-specific decode
-func DecodeBtrt(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeBtrt(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -24,7 +24,7 @@ func DecodeBtrt(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeBtrtSR - box-specific decode
-func DecodeBtrtSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeBtrtSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	b := &BtrtBox{
 		BufferSizeDB: sr.ReadUint32(),
 		MaxBitrate:   sr.ReadUint32(),
@@ -0,0 +1,82 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+)
+
+// ChunkedSegmentWriter - write one segment as several moof+mdat chunks for low-latency CMAF.
+// All chunks share the same sequence number, and tfdt of each chunk continues
+// from the end of the previous one. Samples are added incrementally and a chunk is
+// written to the underlying writer at each Flush.
+type ChunkedSegmentWriter struct {
+	Styp           *StypBox    // Written before the first chunk if not nil
+	EncOptimize    EncOptimize // Optimizations applied to each chunk
+	w              io.Writer
+	seqNr          uint32
+	trackID        uint32
+	nextDecodeTime uint64
+	chunk          *Fragment
+	nrChunks       int
+}
+
+// NewChunkedSegmentWriter - create writer for segment with sequence number seqNr for track trackID.
+// baseMediaDecodeTime is the decode time of the first sample of the segment.
+func NewChunkedSegmentWriter(w io.Writer, seqNr, trackID uint32, baseMediaDecodeTime uint64) *ChunkedSegmentWriter {
+	return &ChunkedSegmentWriter{
+		w:              w,
+		seqNr:          seqNr,
+		trackID:        trackID,
+		nextDecodeTime: baseMediaDecodeTime,
+	}
+}
+
+// AddSample - add a sample with its data to the current chunk.
+// The decode time is given by the running time of the segment.
+func (c *ChunkedSegmentWriter) AddSample(s Sample, data []byte) error {
+	if uint32(len(data)) != s.Size {
+		return fmt.Errorf("sample size %d differs from data length %d", s.Size, len(data))
+	}
+	if c.chunk == nil {
+		chunk, err := CreateFragment(c.seqNr, c.trackID)
+		if err != nil {
+			return err
+		}
+		chunk.EncOptimize = c.EncOptimize
+		c.chunk = chunk
+	}
+	c.chunk.AddFullSample(FullSample{Sample: s, DecodeTime: c.nextDecodeTime, Data: data})
+	c.nextDecodeTime += uint64(s.Dur)
+	return nil
+}
+
+// Flush - write the current chunk as moof+mdat (preceded by styp for the first chunk).
+// Nothing is written if no samples have been added since the last flush.
+func (c *ChunkedSegmentWriter) Flush() error {
+	if c.chunk == nil {
+		return nil
+	}
+	if c.nrChunks == 0 && c.Styp != nil {
+		err := c.Styp.Encode(c.w)
+		if err != nil {
+			return err
+		}
+	}
+	err := c.chunk.Encode(c.w)
+	if err != nil {
+		return err
+	}
+	c.chunk = nil
+	c.nrChunks++
+	return nil
+}
+
+// NextDecodeTime - decode time of next sample to be added
+func (c *ChunkedSegmentWriter) NextDecodeTime() uint64 {
+	return c.nextDecodeTime
+}
+
+// NrChunks - number of chunks written so far
+func (c *ChunkedSegmentWriter) NrChunks() int {
+	return c.nrChunks
+}
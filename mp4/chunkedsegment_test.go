@@ -0,0 +1,68 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestChunkedSegmentWriter(t *testing.T) {
+	buf := bytes.Buffer{}
+	csw := NewChunkedSegmentWriter(&buf, 5, 1, 90000)
+	csw.Styp = CreateStyp()
+	chunkSizes := []int{2, 1, 3}
+	sampleNr := 0
+	for _, nrSamples := range chunkSizes {
+		for i := 0; i < nrSamples; i++ {
+			data := []byte{byte(sampleNr), byte(sampleNr)}
+			err := csw.AddSample(Sample{SyncSampleFlags, 3000, 2, 0}, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sampleNr++
+		}
+		err := csw.Flush()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := csw.Flush(); err != nil || csw.NrChunks() != len(chunkSizes) {
+		t.Errorf("empty flush changed chunk count or failed: %v", err)
+	}
+	if csw.NextDecodeTime() != 90000+6*3000 {
+		t.Errorf("got next decode time %d", csw.NextDecodeTime())
+	}
+	if err := csw.AddSample(Sample{SyncSampleFlags, 3000, 3, 0}, []byte{1}); err == nil {
+		t.Error("no error for size mismatch")
+	}
+
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Segments) != 1 {
+		t.Fatalf("got %d segments instead of 1", len(f.Segments))
+	}
+	frags := f.Segments[0].Fragments
+	if len(frags) != len(chunkSizes) {
+		t.Fatalf("got %d chunks instead of %d", len(frags), len(chunkSizes))
+	}
+	sampleNr = 0
+	for i, frag := range frags {
+		if frag.Moof.Mfhd.SequenceNumber != 5 {
+			t.Errorf("chunk %d: sequence number %d", i, frag.Moof.Mfhd.SequenceNumber)
+		}
+		samples, err := frag.GetFullSamples(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(samples) != chunkSizes[i] {
+			t.Errorf("chunk %d: %d samples instead of %d", i, len(samples), chunkSizes[i])
+		}
+		for _, s := range samples {
+			if s.DecodeTime != uint64(90000+sampleNr*3000) || s.Data[0] != byte(sampleNr) {
+				t.Errorf("sample %d: decodeTime %d data %v", sampleNr, s.DecodeTime, s.Data)
+			}
+			sampleNr++
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package mp4
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// ErrUnsupportedMoovCompression - the compression method in dcom is not supported
+var ErrUnsupportedMoovCompression = errors.New("unsupported moov compression")
+
+// CmovBox - QuickTime Compressed Movie Box (cmov)
+// Contained in a moov box and has a dcom box with the compression method and a cmvd box
+// with the compressed moov box. DecodeMoov replaces such a moov by the decompressed one.
+type CmovBox struct {
+	Dcom     *DcomBox
+	Cmvd     *CmvdBox
+	Children []Box
+}
+
+// AddChild - Add a child box
+func (b *CmovBox) AddChild(box Box) {
+	switch box.Type() {
+	case "dcom":
+		b.Dcom = box.(*DcomBox)
+	case "cmvd":
+		b.Cmvd = box.(*CmvdBox)
+	}
+	b.Children = append(b.Children, box)
+}
+
+// DecodeCmov - box-specific decode
+func DecodeCmov(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	children, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.size, r)
+	if err != nil {
+		return nil, err
+	}
+	b := &CmovBox{}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return b, nil
+}
+
+// DecodeCmovSR - box-specific decode
+func DecodeCmovSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.size, sr)
+	if err != nil {
+		return nil, err
+	}
+	b := &CmovBox{}
+	for _, c := range children {
+		b.AddChild(c)
+	}
+	return b, nil
+}
+
+// Type - box-specific type
+func (b *CmovBox) Type() string {
+	return "cmov"
+}
+
+// Size - box-specific size
+func (b *CmovBox) Size() uint64 {
+	return containerSize(b.Children)
+}
+
+// GetChildren - list of child boxes
+func (b *CmovBox) GetChildren() []Box {
+	return b.Children
+}
+
+// Encode - write cmov container to w
+func (b *CmovBox) Encode(w io.Writer) error {
+	return EncodeContainer(b, w)
+}
+
+// EncodeSW - write cmov container to sw
+func (b *CmovBox) EncodeSW(sw bits.SliceWriter) error {
+	return EncodeContainerSW(b, sw)
+}
+
+// Info - write box-specific information
+func (b *CmovBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	return ContainerInfo(b, w, specificBoxLevels, indent, indentStep)
+}
+
+// Decompress - decompress the cmvd data with the method in dcom and decode it as a moov box.
+// Only zlib is supported, and other methods give an error wrapping ErrUnsupportedMoovCompression.
+func (b *CmovBox) Decompress() (*MoovBox, error) {
+	if b.Dcom == nil || b.Cmvd == nil {
+		return nil, fmt.Errorf("cmov lacks dcom or cmvd")
+	}
+	if b.Dcom.Compression != "zlib" {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedMoovCompression, b.Dcom.Compression)
+	}
+	zr, err := zlib.NewReader(bytes.NewReader(b.Cmvd.Data))
+	if err != nil {
+		return nil, fmt.Errorf("cmvd: %w", err)
+	}
+	defer zr.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(zr, int64(b.Cmvd.UncompressedSize)+1))
+	if err != nil {
+		return nil, fmt.Errorf("cmvd: %w", err)
+	}
+	if len(data) != int(b.Cmvd.UncompressedSize) {
+		return nil, fmt.Errorf("cmvd: decompressed size %d differs from %d", len(data), b.Cmvd.UncompressedSize)
+	}
+	box, err := DecodeBoxSR(0, bits.NewFixedSliceReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decompressed moov: %w", err)
+	}
+	moov, ok := box.(*MoovBox)
+	if !ok {
+		return nil, fmt.Errorf("decompressed box is %s and not moov", box.Type())
+	}
+	return moov, nil
+}
+
+// decompressMoov - the decompressed moov if m has a cmov child, and otherwise m itself
+func decompressMoov(m *MoovBox) (*MoovBox, error) {
+	for _, c := range m.Children {
+		if cmov, ok := c.(*CmovBox); ok {
+			moov, err := cmov.Decompress()
+			if err != nil {
+				return nil, err
+			}
+			moov.StartPos = m.StartPos
+			return moov, nil
+		}
+	}
+	return m, nil
+}
+
+// DcomBox - QuickTime Data Compression Box (dcom)
+// Compression is the four-character code of the compression method, like "zlib".
+type DcomBox struct {
+	Compression string
+}
+
+// DecodeDcom - box-specific decode
+func DecodeDcom(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeDcomSR(hdr, startPos, sr)
+}
+
+// DecodeDcomSR - box-specific decode
+func DecodeDcomSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	if hdr.PayloadLen() != 4 {
+		return nil, fmt.Errorf("dcom: payload size %d instead of 4", hdr.PayloadLen())
+	}
+	return &DcomBox{Compression: sr.ReadFixedLengthString(4)}, sr.AccError()
+}
+
+// Type - box-specific type
+func (b *DcomBox) Type() string {
+	return "dcom"
+}
+
+// Size - calculated size of box
+func (b *DcomBox) Size() uint64 {
+	return boxHeaderSize + 4
+}
+
+// Encode - write box to w
+func (b *DcomBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *DcomBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	if len(b.Compression) != 4 {
+		return fmt.Errorf("dcom compression %q is not four characters", b.Compression)
+	}
+	sw.WriteString(b.Compression, false)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *DcomBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - compression: %s", b.Compression)
+	return bd.err
+}
+
+// CmvdBox - QuickTime Compressed Movie Data Box (cmvd)
+// Data is the compressed moov box, which has size UncompressedSize after decompression.
+type CmvdBox struct {
+	UncompressedSize uint32
+	Data             []byte
+}
+
+// DecodeCmvd - box-specific decode
+func DecodeCmvd(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeCmvdSR(hdr, startPos, sr)
+}
+
+// DecodeCmvdSR - box-specific decode
+func DecodeCmvdSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	if hdr.PayloadLen() < 4 {
+		return nil, fmt.Errorf("cmvd: payload size %d less than 4", hdr.PayloadLen())
+	}
+	b := &CmvdBox{UncompressedSize: sr.ReadUint32()}
+	b.Data = sr.ReadBytes(hdr.PayloadLen() - 4)
+	return b, sr.AccError()
+}
+
+// Type - box-specific type
+func (b *CmvdBox) Type() string {
+	return "cmvd"
+}
+
+// Size - calculated size of box
+func (b *CmvdBox) Size() uint64 {
+	return uint64(boxHeaderSize + 4 + len(b.Data))
+}
+
+// Encode - write box to w
+func (b *CmvdBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *CmvdBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteUint32(b.UncompressedSize)
+	sw.WriteBytes(b.Data)
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *CmvdBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - uncompressedSize: %d", b.UncompressedSize)
+	bd.write(" - compressedSize: %d", len(b.Data))
+	return bd.err
+}
@@ -0,0 +1,59 @@
+package mp4
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+func TestCompressedMoov(t *testing.T) {
+	fd, err := os.Open("testdata/golden_init_video.mp4")
+	assertNoError(t, err)
+	defer fd.Close()
+	init, err := DecodeFile(fd)
+	assertNoError(t, err)
+	moov := init.Moov
+	moov.StartPos = 0 // Same position as the compressed moov will have
+	var raw bytes.Buffer
+	err = moov.Encode(&raw)
+	assertNoError(t, err)
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	_, err = zw.Write(raw.Bytes())
+	assertNoError(t, err)
+	err = zw.Close()
+	assertNoError(t, err)
+
+	for _, method := range []string{"zlib", "adec"} {
+		cmov := &CmovBox{}
+		cmov.AddChild(&DcomBox{Compression: method})
+		cmov.AddChild(&CmvdBox{UncompressedSize: uint32(raw.Len()), Data: compressed.Bytes()})
+		outer := NewMoovBox()
+		outer.AddChild(cmov)
+		var buf bytes.Buffer
+		err = outer.Encode(&buf)
+		assertNoError(t, err)
+		for _, decodeSR := range []bool{false, true} {
+			var box Box
+			if decodeSR {
+				box, err = DecodeBoxSR(0, bits.NewFixedSliceReader(buf.Bytes()))
+			} else {
+				box, err = DecodeBox(0, bytes.NewReader(buf.Bytes()))
+			}
+			if method != "zlib" {
+				if !errors.Is(err, ErrUnsupportedMoovCompression) {
+					t.Errorf("got error %v for method %s", err, method)
+				}
+				continue
+			}
+			assertNoError(t, err)
+			if equal, diff := BoxesEqual(moov, box); !equal {
+				t.Errorf("decompressed moov differs: %s", diff)
+			}
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// Colour types for ColrBox
+const (
+	ColorTypeNclx          = "nclx" // on-screen colours
+	ColorTypeRestrictedICC = "rICC" // restricted ICC profile
+	ColorTypeICC           = "prof" // unrestricted ICC profile
+)
+
+// Transfer characteristics for HDR signaling (ISO/IEC 23091-2)
+const (
+	TransferCharacteristicsPQ  = 16 // SMPTE ST 2084
+	TransferCharacteristicsHLG = 18 // ARIB STD-B67
+)
+
+// ColrBox - Colour Information Box, ISO/IEC 14496-12 2020 Sec. 12.1.5
+//
+// For nclx, the colour fields are set, and any bytes after them are kept as UnknownPayload.
+// For rICC and prof, ICCProfile holds the raw profile. Other colour types are kept as UnknownPayload.
+type ColrBox struct {
+	ColorType               string
+	ColorPrimaries          uint16
+	TransferCharacteristics uint16
+	MatrixCoefficients      uint16
+	FullRangeFlag           bool
+	ICCProfile              []byte
+	UnknownPayload          []byte
+}
+
+// CreateNclxColrBox - Create a new ColrBox of type nclx
+func CreateNclxColrBox(colorPrimaries, transferCharacteristics, matrixCoefficients uint16, fullRange bool) *ColrBox {
+	return &ColrBox{
+		ColorType:               ColorTypeNclx,
+		ColorPrimaries:          colorPrimaries,
+		TransferCharacteristics: transferCharacteristics,
+		MatrixCoefficients:      matrixCoefficients,
+		FullRangeFlag:           fullRange,
+	}
+}
+
+// DecodeColr - box-specific decode
+func DecodeColr(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeColrSR(hdr, startPos, sr)
+}
+
+// DecodeColrSR - box-specific decode
+func DecodeColrSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	payloadLen := hdr.PayloadLen()
+	if payloadLen < 4 {
+		return nil, fmt.Errorf("colr: too short payload %d bytes", payloadLen)
+	}
+	b := &ColrBox{}
+	b.ColorType = sr.ReadFixedLengthString(4)
+	switch b.ColorType {
+	case ColorTypeNclx:
+		if payloadLen < 11 {
+			return nil, fmt.Errorf("colr nclx: payload size %d less than 11", payloadLen)
+		}
+		b.ColorPrimaries = sr.ReadUint16()
+		b.TransferCharacteristics = sr.ReadUint16()
+		b.MatrixCoefficients = sr.ReadUint16()
+		b.FullRangeFlag = sr.ReadUint8()&0x80 != 0
+		if payloadLen > 11 {
+			b.UnknownPayload = sr.ReadBytes(payloadLen - 11)
+		}
+	case ColorTypeRestrictedICC, ColorTypeICC:
+		b.ICCProfile = sr.ReadBytes(payloadLen - 4)
+	default:
+		b.UnknownPayload = sr.ReadBytes(payloadLen - 4)
+	}
+	return b, sr.AccError()
+}
+
+// Type - box type
+func (b *ColrBox) Type() string {
+	return "colr"
+}
+
+// Size - calculated size of box
+func (b *ColrBox) Size() uint64 {
+	switch b.ColorType {
+	case ColorTypeNclx:
+		return uint64(boxHeaderSize + 11 + len(b.UnknownPayload))
+	case ColorTypeRestrictedICC, ColorTypeICC:
+		return uint64(boxHeaderSize + 4 + len(b.ICCProfile))
+	default:
+		return uint64(boxHeaderSize + 4 + len(b.UnknownPayload))
+	}
+}
+
+// Encode - write box to w
+func (b *ColrBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *ColrBox) EncodeSW(sw bits.SliceWriter) error {
+	if len(b.ColorType) != 4 {
+		return fmt.Errorf("colr: colour type %q is not 4 characters", b.ColorType)
+	}
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteString(b.ColorType, false)
+	switch b.ColorType {
+	case ColorTypeNclx:
+		sw.WriteUint16(b.ColorPrimaries)
+		sw.WriteUint16(b.TransferCharacteristics)
+		sw.WriteUint16(b.MatrixCoefficients)
+		var fullRange byte
+		if b.FullRangeFlag {
+			fullRange = 0x80
+		}
+		sw.WriteUint8(fullRange)
+		sw.WriteBytes(b.UnknownPayload)
+	case ColorTypeRestrictedICC, ColorTypeICC:
+		sw.WriteBytes(b.ICCProfile)
+	default:
+		sw.WriteBytes(b.UnknownPayload)
+	}
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *ColrBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - colorType: %s", b.ColorType)
+	switch b.ColorType {
+	case ColorTypeNclx:
+		bd.write(" - ColorPrimaries: %d, TransferCharacteristics: %d, MatrixCoefficients: %d, FullRange: %t",
+			b.ColorPrimaries, b.TransferCharacteristics, b.MatrixCoefficients, b.FullRangeFlag)
+		if len(b.UnknownPayload) > 0 {
+			bd.write(" - trailing bytes: %d", len(b.UnknownPayload))
+		}
+	case ColorTypeRestrictedICC, ColorTypeICC:
+		bd.write(" - ICCProfile: %d bytes", len(b.ICCProfile))
+	default:
+		bd.write(" - payload: %d bytes", len(b.UnknownPayload))
+	}
+	return bd.err
+}
@@ -0,0 +1,43 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncDecColr(t *testing.T) {
+	testCases := []*ColrBox{
+		CreateNclxColrBox(9, TransferCharacteristicsPQ, 9, false),
+		CreateNclxColrBox(1, 1, 1, true),
+		{ColorType: ColorTypeNclx, ColorPrimaries: 1, TransferCharacteristics: 1, MatrixCoefficients: 1,
+			UnknownPayload: []byte{0, 0}}, // Trailing bytes after the nclx fields
+		{ColorType: ColorTypeRestrictedICC, ICCProfile: []byte{0, 1, 2, 3, 4, 5}},
+		{ColorType: ColorTypeICC, ICCProfile: []byte{0xff, 0xfe}},
+		{ColorType: "nclc", UnknownPayload: []byte{0, 1, 0, 1, 0, 1}},
+	}
+	for _, colr := range testCases {
+		boxDiffAfterEncodeAndDecode(t, colr)
+	}
+}
+
+func TestColrInVisualSampleEntry(t *testing.T) {
+	vse := CreateVisualSampleEntryBox("hvc1", 1920, 1080, nil)
+	vse.AddChild(CreateNclxColrBox(9, TransferCharacteristicsHLG, 9, false))
+
+	buf := bytes.Buffer{}
+	err := vse.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	box, err := DecodeBox(0, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	colr := box.(*VisualSampleEntryBox).Colr
+	if colr == nil {
+		t.Fatal("no colr box in decoded sample entry")
+	}
+	if colr.TransferCharacteristics != TransferCharacteristicsHLG {
+		t.Errorf("got transfer characteristics %d instead of %d", colr.TransferCharacteristics, TransferCharacteristicsHLG)
+	}
+}
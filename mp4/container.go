@@ -17,20 +17,27 @@ type ContainerBox interface {
 	Info(w io.Writer, specificBoxLevels, indent, indentStep string) error
 }
 
+// containerSize - header plus children size. A largesize header is used if the total does not fit 32 bits
 func containerSize(children []Box) uint64 {
 	var contentSize uint64 = 0
 	for _, child := range children {
 		contentSize += child.Size()
 	}
+	if boxHeaderSize+contentSize > maxNormalBoxSize {
+		return boxHeaderSize + largeSizeLen + contentSize
+	}
 	return boxHeaderSize + contentSize
 }
 
+// maxNormalBoxSize - largest box size that fits in a 4-byte size field
+const maxNormalBoxSize = (1 << 32) - 1
+
 // DecodeContainerChildren decodes a container box
-func DecodeContainerChildren(hdr boxHeader, startPos, endPos uint64, r io.Reader) ([]Box, error) {
+func DecodeContainerChildren(hdr BoxHeader, startPos, endPos uint64, r io.Reader) ([]Box, error) {
 	children := make([]Box, 0, 8)
 	pos := startPos
 	for {
-		child, err := DecodeBox(pos, r)
+		child, err := decodeBox(pos, r, hdr.opts)
 		if err == io.EOF {
 			return children, nil
 		}
@@ -48,7 +55,7 @@ func DecodeContainerChildren(hdr boxHeader, startPos, endPos uint64, r io.Reader
 }
 
 // DecodeContainerChildren decodes a container box
-func DecodeContainerChildrenSR(hdr boxHeader, startPos, endPos uint64, sr bits.SliceReader) ([]Box, error) {
+func DecodeContainerChildrenSR(hdr BoxHeader, startPos, endPos uint64, sr bits.SliceReader) ([]Box, error) {
 	children := make([]Box, 0, 8) // Good initial size
 	pos := startPos
 	initPos := sr.GetPos()
@@ -59,7 +66,7 @@ func DecodeContainerChildrenSR(hdr boxHeader, startPos, endPos uint64, sr bits.S
 		if pos == endPos {
 			break
 		}
-		child, err := DecodeBoxSR(pos, sr)
+		child, err := decodeBoxSR(pos, sr, hdr.opts)
 		if err != nil {
 			return children, err
 		}
@@ -75,7 +82,8 @@ func DecodeContainerChildrenSR(hdr boxHeader, startPos, endPos uint64, sr bits.S
 
 // EncodeContainer - marshal container c to w
 func EncodeContainer(c ContainerBox, w io.Writer) error {
-	err := EncodeHeader(c, w)
+	size := c.Size()
+	err := EncodeHeaderWithSize(c.Type(), size, size > maxNormalBoxSize, w)
 	if err != nil {
 		return err
 	}
@@ -90,7 +98,8 @@ func EncodeContainer(c ContainerBox, w io.Writer) error {
 
 // EncodeContainerSW - marshal container c to sw
 func EncodeContainerSW(c ContainerBox, sw bits.SliceWriter) error {
-	err := EncodeHeaderSW(c, sw)
+	size := c.Size()
+	err := EncodeHeaderWithSizeSW(c.Type(), size, size > maxNormalBoxSize, sw)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,75 @@
+package mp4
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// sizeOnlyBox - box reporting a size without writing any payload
+type sizeOnlyBox struct {
+	size uint64
+}
+
+func (b *sizeOnlyBox) Type() string                       { return "free" }
+func (b *sizeOnlyBox) Size() uint64                       { return b.size }
+func (b *sizeOnlyBox) Encode(w io.Writer) error           { return nil }
+func (b *sizeOnlyBox) EncodeSW(sw bits.SliceWriter) error { return nil }
+func (b *sizeOnlyBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	return nil
+}
+
+func TestContainerLargeSize(t *testing.T) {
+	testCases := []struct {
+		childSize    uint64
+		expectedSize uint64
+		largeSize    bool
+	}{
+		{childSize: 100, expectedSize: 108, largeSize: false},
+		{childSize: maxNormalBoxSize - 8, expectedSize: maxNormalBoxSize, largeSize: false},
+		{childSize: maxNormalBoxSize - 7, expectedSize: maxNormalBoxSize + 9, largeSize: true},
+		{childSize: 1 << 33, expectedSize: 1<<33 + 16, largeSize: true},
+	}
+	for _, tc := range testCases {
+		edts := &EdtsBox{Children: []Box{&sizeOnlyBox{tc.childSize}}}
+		if edts.Size() != tc.expectedSize {
+			t.Errorf("child size %d: got container size %d instead of %d", tc.childSize, edts.Size(), tc.expectedSize)
+		}
+		buf := bytes.Buffer{}
+		err := edts.Encode(&buf)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		sw := bits.NewFixedSliceWriter(16)
+		err = edts.EncodeSW(sw)
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if !bytes.Equal(buf.Bytes(), sw.Bytes()) {
+			t.Errorf("child size %d: Encode and EncodeSW headers differ", tc.childSize)
+		}
+		hdr := buf.Bytes()
+		var encSize uint64
+		if tc.largeSize {
+			if len(hdr) != 16 || binary.BigEndian.Uint32(hdr) != 1 {
+				t.Errorf("child size %d: no largesize header", tc.childSize)
+				continue
+			}
+			encSize = binary.BigEndian.Uint64(hdr[8:])
+		} else {
+			if len(hdr) != 8 {
+				t.Errorf("child size %d: header size %d instead of 8", tc.childSize, len(hdr))
+				continue
+			}
+			encSize = uint64(binary.BigEndian.Uint32(hdr))
+		}
+		if encSize != tc.expectedSize {
+			t.Errorf("child size %d: encoded size %d instead of %d", tc.childSize, encSize, tc.expectedSize)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package mp4
+
+import "fmt"
+
+// RecalculateCounts - set count fields from the lengths of the lists they describe in all boxes
+// in and below root, which can be any box, *File, or *Fragment.
+// This fixes boxes that have been modified by hand so that count and list disagree.
+// The fields handled are the sample counts in trun, stsz, saiz, and senc, and the
+// entry counts in stsd and dref. The VttC, Vlab, and Btrt fields of wvtt are also made to agree
+// with its children, which are what is encoded. Box sizes need no such pass, see RecalculateSizes.
+// One line per changed field is returned.
+func RecalculateCounts(root interface{}) []string {
+	var changes []string
+	fix := func(b Box, field string, count *uint32, length int) {
+		if *count != uint32(length) {
+			changes = append(changes, fmt.Sprintf("%s: %s %d -> %d", b.Type(), field, *count, length))
+			*count = uint32(length)
+		}
+	}
+	for _, b := range collectBoxes(root) {
+		switch box := b.(type) {
+		case *TrunBox:
+			fix(box, "sampleCount", &box.sampleCount, len(box.Samples))
+		case *StsdBox:
+			fix(box, "SampleCount", &box.SampleCount, len(box.Children))
+		case *DrefBox:
+			fix(box, "EntryCount", &box.EntryCount, len(box.Children))
+		case *StszBox:
+			if box.SampleUniformSize == 0 {
+				fix(box, "SampleNumber", &box.SampleNumber, len(box.SampleSize))
+			}
+		case *SaizBox:
+			if box.DefaultSampleInfoSize == 0 {
+				fix(box, "SampleCount", &box.SampleCount, len(box.SampleInfo))
+			}
+		case *WvttBox:
+			changes = append(changes, fixWvttChildren(box)...)
+		case *SencBox:
+			// Without IVs and subsamples, the sample count cannot be derived
+			n := len(box.IVs)
+			if len(box.SubSamples) > n {
+				n = len(box.SubSamples)
+			}
+			if !box.readButNotParsed && n > 0 {
+				fix(box, "SampleCount", &box.SampleCount, n)
+			}
+		}
+	}
+	return changes
+}
+
+// RecalculateSizes - does nothing and returns no changes, since box sizes are not stored in the boxes,
+// but always calculated from their content by Size() and when encoding.
+// It is the counterpart of RecalculateCounts, and root can be the same kinds of values.
+func RecalculateSizes(root interface{}) []string {
+	return nil
+}
+
+// fixWvttChildren - make the VttC, Vlab, and Btrt fields of wvtt agree with its children, since only
+// the children are encoded. A field that is not among the children replaces the first child of the same
+// type, or is added as a child. A nil field is set to the first child of its type.
+func fixWvttChildren(b *WvttBox) []string {
+	var changes []string
+	fixChild := func(field Box) {
+		idx := -1
+		for i, c := range b.Children {
+			if c == field {
+				return
+			}
+			if c.Type() == field.Type() && idx < 0 {
+				idx = i
+			}
+		}
+		if idx >= 0 {
+			b.Children[idx] = field
+			changes = append(changes, fmt.Sprintf("wvtt: %s child replaced by field", field.Type()))
+			return
+		}
+		b.Children = append(b.Children, field)
+		changes = append(changes, fmt.Sprintf("wvtt: %s field added as child", field.Type()))
+	}
+	if b.VttC != nil {
+		fixChild(b.VttC)
+	}
+	if b.Vlab != nil {
+		fixChild(b.Vlab)
+	}
+	if b.Btrt != nil {
+		fixChild(b.Btrt)
+	}
+	for _, c := range b.Children {
+		switch box := c.(type) {
+		case *VttCBox:
+			if b.VttC == nil {
+				b.VttC = box
+				changes = append(changes, "wvtt: VttC set from child")
+			}
+		case *VlabBox:
+			if b.Vlab == nil {
+				b.Vlab = box
+				changes = append(changes, "wvtt: Vlab set from child")
+			}
+		case *BtrtBox:
+			if b.Btrt == nil {
+				b.Btrt = box
+				changes = append(changes, "wvtt: Btrt set from child")
+			}
+		}
+	}
+	return changes
+}
@@ -0,0 +1,70 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestRecalculateCounts(t *testing.T) {
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		frag.AddFullSample(FullSample{Sample: NewSample(SyncSampleFlags, 1000, 2, 0), DecodeTime: uint64(i * 1000), Data: []byte{1, 2}})
+	}
+	if changes := RecalculateCounts(frag); changes != nil {
+		t.Errorf("changes for consistent fragment: %v", changes)
+	}
+	trun := frag.Moof.Traf.Trun
+	trun.Samples = trun.Samples[:2]
+	frag.Mdat.Data = frag.Mdat.Data[:4]
+
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	stsd := init.Moov.Trak.Mdia.Minf.Stbl.Stsd
+	stsd.SampleCount = 2
+
+	wanted := []string{"trun: sampleCount 3 -> 2"}
+	if diff := deep.Equal(RecalculateCounts(frag), wanted); diff != nil {
+		t.Errorf("fragment: %v", diff)
+	}
+	wanted = []string{"stsd: SampleCount 2 -> 0"}
+	if diff := deep.Equal(RecalculateCounts(init), wanted); diff != nil {
+		t.Errorf("init: %v", diff)
+	}
+	if changes := RecalculateSizes(init); changes != nil {
+		t.Errorf("size changes %v", changes)
+	}
+
+	wvtt := NewWvttBox()
+	wvtt.AddChild(&VttCBox{Config: "WEBVTT"})
+	wvtt.VttC = &VttCBox{Config: "WEBVTT\n\nSTYLE"} // Set by hand, so not encoded
+	wvtt.Children = append(wvtt.Children, &VlabBox{SourceLabel: "en"})
+	wanted = []string{"wvtt: vttC child replaced by field", "wvtt: Vlab set from child"}
+	if diff := deep.Equal(RecalculateCounts(wvtt), wanted); diff != nil {
+		t.Errorf("wvtt: %v", diff)
+	}
+	if wvtt.Children[0] != wvtt.VttC || wvtt.Children[1] != wvtt.Vlab {
+		t.Errorf("wvtt fields and children differ")
+	}
+
+	var buf bytes.Buffer
+	err = frag.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples, err := f.Segments[0].Fragments[0].GetFullSamples(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 2 {
+		t.Errorf("got %d samples instead of 2", len(samples))
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
+	"fmt"
 	"io"
+
+	"github.com/edgeware/mp4ff/bits"
 )
 
 // DecryptBytesCTR - decrypt or encrypt sample using CTR mode, provided key, iv and sumsamplePattern
@@ -54,3 +57,183 @@ func DecryptSampleCenc(sample []byte, key []byte, iv []byte, subSamplePatterns [
 	}
 	return decSample, nil
 }
+
+// SampleEncryption - encryption parameters for one sample as needed for decryption.
+// IV is the per-sample IV, or the constant IV from tenc if there is no per-sample IV.
+// SubSamples is nil if the whole sample is encrypted.
+type SampleEncryption struct {
+	IV             InitializationVector
+	SubSamples     []SubSamplePattern
+	KID            UUID
+	CryptByteBlock byte
+	SkipByteBlock  byte
+}
+
+// GetEncryptionInfo - encryption parameters for every sample in the traf given by the senc box.
+// The senc box is parsed if needed, with the per-sample IV size taken from tenc.
+// For content without senc, use Fragment.GetEncryptionInfo which reads data given by saio and saiz.
+func (t *TrafBox) GetEncryptionInfo(tenc *TencBox) ([]SampleEncryption, error) {
+	if tenc == nil {
+		return nil, fmt.Errorf("no tenc box")
+	}
+	if t.Senc == nil {
+		return nil, fmt.Errorf("no senc box in traf for track %d", t.Tfhd.TrackID)
+	}
+	if t.Senc.readButNotParsed {
+		err := t.Senc.ParseReadBox(tenc.DefaultPerSampleIVSize, t.Saiz)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sencSamples, err := t.Senc.GetSamples()
+	if err != nil {
+		return nil, err
+	}
+	if nrSamples := t.nrSamples(); nrSamples != len(sencSamples) {
+		return nil, fmt.Errorf("senc has %d samples, but truns have %d", len(sencSamples), nrSamples)
+	}
+	return sampleEncryptions(sencSamples, tenc), nil
+}
+
+// GetEncryptionInfo - encryption parameters for every sample of the track given by trex
+// (the first track if trex is nil). The senc box is used if present. Otherwise, the sample
+// auxiliary information is read from the positions given by saio and saiz, which requires
+// that the data is in the moof or in a non-lazy mdat. The fragment is not changed.
+func (f *Fragment) GetEncryptionInfo(trex *TrexBox, tenc *TencBox) ([]SampleEncryption, error) {
+	if f.Moof == nil {
+		return nil, fmt.Errorf("moof not set in fragment")
+	}
+	traf := f.trafForTrex(trex)
+	if traf == nil {
+		return nil, noTrafError(trex)
+	}
+	if traf.Senc != nil {
+		return traf.GetEncryptionInfo(tenc)
+	}
+	if tenc == nil {
+		return nil, fmt.Errorf("no tenc box")
+	}
+	if traf.Saio == nil || traf.Saiz == nil {
+		return nil, fmt.Errorf("no senc, or saio and saiz, in traf for track %d", traf.Tfhd.TrackID)
+	}
+	saio, saiz := traf.Saio, traf.Saiz
+	nrSamples := traf.nrSamples()
+	if int(saiz.SampleCount) != nrSamples {
+		return nil, fmt.Errorf("saiz has %d samples, but truns have %d", saiz.SampleCount, nrSamples)
+	}
+	var moofData []byte
+	var sencSamples []SencSample
+	var pos uint64
+	sampleNr := 0
+	for i, trun := range traf.Truns {
+		switch {
+		case i == 0:
+			if len(saio.Offset) == 0 {
+				return nil, fmt.Errorf("no offset in saio")
+			}
+			pos = f.auxInfoBase(traf.Tfhd) + uint64(saio.Offset[0])
+		case len(saio.Offset) == len(traf.Truns):
+			pos = f.auxInfoBase(traf.Tfhd) + uint64(saio.Offset[i])
+		case len(saio.Offset) != 1:
+			return nil, fmt.Errorf("%d saio offsets for %d truns", len(saio.Offset), len(traf.Truns))
+		}
+		for j := 0; j < len(trun.Samples); j++ {
+			size := uint64(saiz.DefaultSampleInfoSize)
+			if size == 0 {
+				size = uint64(saiz.SampleInfo[sampleNr])
+			}
+			var data []byte
+			var err error
+			data, moofData, err = f.auxInfoData(pos, size, moofData)
+			if err != nil {
+				return nil, err
+			}
+			s, err := parseSampleAuxInfo(data, tenc.DefaultPerSampleIVSize)
+			if err != nil {
+				return nil, fmt.Errorf("sample %d: %w", sampleNr+1, err)
+			}
+			sencSamples = append(sencSamples, s)
+			pos += size
+			sampleNr++
+		}
+	}
+	return sampleEncryptions(sencSamples, tenc), nil
+}
+
+// auxInfoBase - absolute position which saio offsets are relative to
+func (f *Fragment) auxInfoBase(tfhd *TfhdBox) uint64 {
+	if tfhd.HasBaseDataOffset() {
+		return tfhd.BaseDataOffset
+	}
+	return f.Moof.StartPos
+}
+
+// auxInfoData - size bytes at absolute position pos in moof or mdat.
+// The encoded moof is returned to be reused in subsequent calls.
+func (f *Fragment) auxInfoData(pos, size uint64, moofData []byte) ([]byte, []byte, error) {
+	moofStart, moofEnd := f.Moof.StartPos, f.Moof.StartPos+f.Moof.Size()
+	if pos >= moofStart && pos+size <= moofEnd {
+		if f.Mdat != nil && f.inMemoryLayout() {
+			return nil, moofData, fmt.Errorf("auxiliary information in moof of fragment built in memory")
+		}
+		if moofData == nil {
+			sw := bits.NewFixedSliceWriter(int(f.Moof.Size()))
+			err := f.Moof.EncodeSW(sw)
+			if err != nil {
+				return nil, nil, err
+			}
+			moofData = sw.Bytes()
+		}
+		return moofData[pos-moofStart : pos-moofStart+size], moofData, nil
+	}
+	if f.Mdat == nil || f.Mdat.IsLazy() {
+		return nil, moofData, fmt.Errorf("auxiliary information at %d is not in moof, and mdat data is not available", pos)
+	}
+	payloadStart := f.mdatPayloadStart()
+	if pos < payloadStart || pos+size > payloadStart+uint64(len(f.Mdat.Data)) {
+		return nil, moofData, fmt.Errorf("auxiliary information at %d is outside moof and mdat", pos)
+	}
+	return f.Mdat.Data[pos-payloadStart : pos-payloadStart+size], moofData, nil
+}
+
+// parseSampleAuxInfo - parse cenc sample auxiliary information with IV and optional subsamples
+func parseSampleAuxInfo(data []byte, perSampleIVSize byte) (SencSample, error) {
+	s := SencSample{}
+	sr := bits.NewFixedSliceReader(data)
+	if perSampleIVSize > 0 {
+		s.IV = sr.ReadBytes(int(perSampleIVSize))
+	}
+	if sr.NrRemainingBytes() > 0 {
+		subsampleCount := int(sr.ReadUint16())
+		s.SubSamples = make([]SubSamplePattern, subsampleCount)
+		for i := range s.SubSamples {
+			s.SubSamples[i].BytesOfClearData = sr.ReadUint16()
+			s.SubSamples[i].BytesOfProtectedData = sr.ReadUint32()
+		}
+	}
+	if err := sr.AccError(); err != nil {
+		return s, err
+	}
+	if sr.NrRemainingBytes() != 0 {
+		return s, fmt.Errorf("%d bytes left in auxiliary information", sr.NrRemainingBytes())
+	}
+	return s, nil
+}
+
+// sampleEncryptions - senc samples complemented with defaults from tenc
+func sampleEncryptions(sencSamples []SencSample, tenc *TencBox) []SampleEncryption {
+	encs := make([]SampleEncryption, len(sencSamples))
+	for i, s := range sencSamples {
+		encs[i] = SampleEncryption{
+			IV:             s.IV,
+			SubSamples:     s.SubSamples,
+			KID:            tenc.DefaultKID,
+			CryptByteBlock: tenc.DefaultCryptByteBlock,
+			SkipByteBlock:  tenc.DefaultSkipByteBlock,
+		}
+		if len(encs[i].IV) == 0 {
+			encs[i].IV = tenc.DefaultConstantIV
+		}
+	}
+	return encs
+}
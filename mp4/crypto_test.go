@@ -1,6 +1,12 @@
 package mp4
 
-import "testing"
+import (
+	"bytes"
+	"testing"
+
+	"github.com/edgeware/mp4ff/bits"
+	"github.com/go-test/deep"
+)
 
 // For information about encryption, see https://github.com/gpac/gpac/wiki/Common-Encryption
 
@@ -24,3 +30,127 @@ func TestEncryptDecrypt(t *testing.T) {
 	}
 
 }
+
+func TestGetEncryptionInfo(t *testing.T) {
+	kid := UUID([]byte("0123456789abcdef"))
+	tenc := &TencBox{DefaultIsProtected: 1, DefaultPerSampleIVSize: 8, DefaultKID: kid}
+	sencSamples := []SencSample{
+		{IV: []byte("iv000001"), SubSamples: []SubSamplePattern{{10, 100}}},
+		{IV: []byte("iv000002"), SubSamples: []SubSamplePattern{{20, 200}, {5, 16}}},
+	}
+	wanted := make([]SampleEncryption, len(sencSamples))
+	for i, s := range sencSamples {
+		wanted[i] = SampleEncryption{IV: s.IV, SubSamples: s.SubSamples, KID: kid}
+	}
+
+	t.Run("senc", func(t *testing.T) {
+		frag, err := CreateFragment(1, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		senc := CreateSencBox()
+		for i, s := range sencSamples {
+			frag.AddFullSample(FullSample{Sample: NewSample(SyncSampleFlags, 1000, 4, 0), DecodeTime: uint64(i * 1000), Data: []byte{1, 2, 3, 4}})
+			err = senc.AddSample(s)
+			assertNoError(t, err)
+		}
+		_ = frag.Moof.Traf.AddChild(senc)
+		frag.Moof.Traf.Trun.DataOffset = 8
+		var buf bytes.Buffer
+		err = frag.Moof.Traf.Encode(&buf)
+		assertNoError(t, err)
+		box, err := DecodeBox(0, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := box.(*TrafBox).GetEncryptionInfo(tenc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := deep.Equal(got, wanted); diff != nil {
+			t.Error(diff)
+		}
+	})
+
+	t.Run("saio and saiz", func(t *testing.T) {
+		frag, err := CreateFragment(1, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var auxData []byte
+		saiz := &SaizBox{SampleCount: uint32(len(sencSamples))}
+		for i, s := range sencSamples {
+			frag.AddFullSample(FullSample{Sample: NewSample(SyncSampleFlags, 1000, 4, 0), DecodeTime: uint64(i * 1000), Data: []byte{1, 2, 3, 4}})
+			sw := bits.NewFixedSliceWriter(len(s.IV) + 2 + 6*len(s.SubSamples))
+			sw.WriteBytes(s.IV)
+			sw.WriteUint16(uint16(len(s.SubSamples)))
+			for _, ss := range s.SubSamples {
+				sw.WriteUint16(ss.BytesOfClearData)
+				sw.WriteUint32(ss.BytesOfProtectedData)
+			}
+			auxData = append(auxData, sw.Bytes()...)
+			saiz.SampleInfo = append(saiz.SampleInfo, byte(len(sw.Bytes())))
+		}
+		saio := &SaioBox{Offset: []int64{0}}
+		traf := frag.Moof.Traf
+		_ = traf.AddChild(saiz)
+		_ = traf.AddChild(saio)
+		// Auxiliary information after the sample data in mdat
+		saio.Offset[0] = int64(frag.Moof.Size() + frag.Mdat.HeaderSize() + uint64(len(frag.Mdat.Data)))
+		frag.Mdat.AddSampleData(auxData)
+
+		got, err := frag.GetEncryptionInfo(nil, tenc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := deep.Equal(got, wanted); diff != nil {
+			t.Error(diff)
+		}
+
+		var buf bytes.Buffer
+		err = frag.Encode(&buf)
+		assertNoError(t, err)
+		f, err := DecodeFile(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err = f.Segments[0].Fragments[0].GetEncryptionInfo(nil, tenc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := deep.Equal(got, wanted); diff != nil {
+			t.Error(diff)
+		}
+		if _, err = traf.GetEncryptionInfo(tenc); err == nil {
+			t.Error("no error for traf without senc")
+		}
+	})
+	t.Run("cbcs constant IV", func(t *testing.T) {
+		cbcsTenc := &TencBox{Version: 1, DefaultCryptByteBlock: 1, DefaultSkipByteBlock: 9, DefaultIsProtected: 1,
+			DefaultKID: kid, DefaultConstantIV: []byte("0123456789abcdef")}
+		traf := createTestTrafBox()
+		traf.Trun.AddSample(NewSample(SyncSampleFlags, 1000, 4, 0))
+		senc := CreateSencBox()
+		err := senc.AddSample(SencSample{SubSamples: []SubSamplePattern{{4, 0}}})
+		assertNoError(t, err)
+		_ = traf.AddChild(senc)
+		got, err := traf.GetEncryptionInfo(cbcsTenc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []SampleEncryption{{IV: cbcsTenc.DefaultConstantIV, SubSamples: []SubSamplePattern{{4, 0}},
+			KID: kid, CryptByteBlock: 1, SkipByteBlock: 9}}
+		if diff := deep.Equal(got, want); diff != nil {
+			t.Error(diff)
+		}
+	})
+	t.Run("nil trex and no traf", func(t *testing.T) {
+		moof := &MoofBox{}
+		assertNoError(t, moof.AddChild(CreateMfhd(1)))
+		frag := NewFragment()
+		frag.AddChild(moof)
+		if _, err := frag.GetEncryptionInfo(nil, tenc); err == nil {
+			t.Error("no error for fragment without traf")
+		}
+	})
+}
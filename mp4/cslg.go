@@ -1,7 +1,9 @@
 package mp4
 
 import (
+	"fmt"
 	"io"
+	"math"
 
 	"github.com/edgeware/mp4ff/bits"
 )
@@ -19,8 +21,78 @@ type CslgBox struct {
 	CompositionEndTime           int64
 }
 
+// CreateCslg - create cslg box from the sample durations in stts and composition time offsets in ctts.
+// A nil ctts means that all composition time offsets are zero, giving a zero shift.
+// Version 1 is used if any value does not fit in 32 bits.
+func CreateCslg(stts *SttsBox, ctts *CttsBox) (*CslgBox, error) {
+	if stts == nil {
+		return nil, fmt.Errorf("no stts")
+	}
+	var decTime int64
+	var nrSamples, cttsNr, cttsSampleNr uint32
+	b := &CslgBox{
+		LeastDecodeToDisplayDelta:    math.MaxInt64,
+		GreatestDecodeToDisplayDelta: math.MinInt64,
+		CompositionStartTime:         math.MaxInt64,
+		CompositionEndTime:           math.MinInt64,
+	}
+	for i, count := range stts.SampleCount {
+		dur := int64(stts.SampleTimeDelta[i])
+		for j := uint32(0); j < count; j++ {
+			var offset int64
+			if ctts != nil {
+				for cttsNr < uint32(len(ctts.SampleCount)) && cttsSampleNr == ctts.SampleCount[cttsNr] {
+					cttsNr++
+					cttsSampleNr = 0
+				}
+				if cttsNr == uint32(len(ctts.SampleCount)) {
+					return nil, fmt.Errorf("ctts has fewer samples than stts")
+				}
+				offset = int64(ctts.SampleOffset[cttsNr])
+				cttsSampleNr++
+			}
+			if offset < b.LeastDecodeToDisplayDelta {
+				b.LeastDecodeToDisplayDelta = offset
+			}
+			if offset > b.GreatestDecodeToDisplayDelta {
+				b.GreatestDecodeToDisplayDelta = offset
+			}
+			if decTime+offset < b.CompositionStartTime {
+				b.CompositionStartTime = decTime + offset
+			}
+			if decTime+offset+dur > b.CompositionEndTime {
+				b.CompositionEndTime = decTime + offset + dur
+			}
+			decTime += dur
+			nrSamples++
+		}
+	}
+	if nrSamples == 0 {
+		return nil, fmt.Errorf("no samples in stts")
+	}
+	if ctts != nil {
+		var nrCttsSamples uint32
+		for _, count := range ctts.SampleCount {
+			nrCttsSamples += count
+		}
+		if nrCttsSamples != nrSamples {
+			return nil, fmt.Errorf("ctts has %d samples, but stts has %d", nrCttsSamples, nrSamples)
+		}
+	}
+	if b.LeastDecodeToDisplayDelta < 0 {
+		b.CompositionToDTSShift = -b.LeastDecodeToDisplayDelta
+	}
+	for _, v := range []int64{b.CompositionToDTSShift, b.LeastDecodeToDisplayDelta, b.GreatestDecodeToDisplayDelta,
+		b.CompositionStartTime, b.CompositionEndTime} {
+		if v < math.MinInt32 || v > math.MaxInt32 {
+			b.Version = 1
+		}
+	}
+	return b, nil
+}
+
 // DecodeCslg - box-specific decode
-func DecodeCslg(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeCslg(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -30,7 +102,7 @@ func DecodeCslg(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeCslgSR - box-specific decode
-func DecodeCslgSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeCslgSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	versionAndFlags := sr.ReadUint32()
 	b := CslgBox{
 		Version: byte(versionAndFlags >> 24),
@@ -1,6 +1,10 @@
 package mp4
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
 
 func TestCslgEncodeDecode(t *testing.T) {
 	cslg := CslgBox{
@@ -25,3 +29,43 @@ func TestCslgEncodeDecode(t *testing.T) {
 
 	boxDiffAfterEncodeAndDecode(t, &cslg)
 }
+
+func TestCreateCslg(t *testing.T) {
+	// I P B B with decode times 0, 10, 20, 30 and presentation times 20, 50, 30, 40
+	stts := &SttsBox{SampleCount: []uint32{4}, SampleTimeDelta: []uint32{10}}
+	ctts := &CttsBox{SampleCount: []uint32{1, 1, 2}, SampleOffset: []int32{20, 40, 10}}
+	cslg, err := CreateCslg(stts, ctts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &CslgBox{
+		LeastDecodeToDisplayDelta:    10,
+		GreatestDecodeToDisplayDelta: 40,
+		CompositionStartTime:         20,
+		CompositionEndTime:           60,
+	}
+	if diff := deep.Equal(cslg, want); diff != nil {
+		t.Error(diff)
+	}
+	ctts.SampleOffset[0] = -(1 << 31)
+	cslg, err = CreateCslg(stts, ctts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cslg.Version != 1 || cslg.CompositionToDTSShift != 1<<31 {
+		t.Errorf("got version %d and shift %d", cslg.Version, cslg.CompositionToDTSShift)
+	}
+	ctts.SampleCount = []uint32{1, 1, 1}
+	_, err = CreateCslg(stts, ctts)
+	if err == nil {
+		t.Error("no error for too few ctts samples")
+	}
+	cslg, err = CreateCslg(stts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = &CslgBox{CompositionStartTime: 0, CompositionEndTime: 40}
+	if diff := deep.Equal(cslg, want); diff != nil {
+		t.Error(diff)
+	}
+}
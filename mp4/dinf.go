@@ -25,7 +25,7 @@ func (d *DinfBox) AddChild(box Box) {
 }
 
 // DecodeDinf - box-specific decode
-func DecodeDinf(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeDinf(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	l, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.size, r)
 	if err != nil {
 		return nil, err
@@ -38,7 +38,7 @@ func DecodeDinf(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeDinfSR - box-specific decode
-func DecodeDinfSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeDinfSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.size, sr)
 	if err != nil {
 		return nil, err
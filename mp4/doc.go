@@ -25,6 +25,11 @@ FoooBox should then implement the Box interface methods:
 but also its own decode method `DecodeFooo`, and register that method in the `decoders` map in `box.go`.
 For a simple example, look at the `prft` box in `prft.go`.
 
+Reserved and pre-defined fields should be stored in the box struct when decoded
+and written back as they were read, instead of being written as zero.
+This way non-zero values survive a decode-encode round trip.
+See for example `PreDefined` in `hdlr.go` and `Reserved` in `smhd.go`.
+
 Container Boxes
 
 Container boxes like moof, have a list of all their children called Children,
@@ -37,6 +42,11 @@ element like a TfhdBox as
 When there may be multiple children with the same name, there may be both a
 pointer to a slice Trafs with all boxes and Traf that points to the first.
 
+The size of a container is calculated from its children every time Size() is called,
+so encoding calculates the size of a box once for each of its ancestors.
+Since box trees are shallow, and fragments are encoded one at a time, encoding time grows
+linearly with the number of fragments. See BenchmarkEncodeManyFragments.
+
 Media Sample Data Structures
 
 To handle media sample data there are two structures:
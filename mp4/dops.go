@@ -0,0 +1,117 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// DopsBox - OpusSpecificBox (dOps) as defined in Encapsulation of Opus in ISO Base Media File Format Sec. 4.3.2
+// Note that all values are big-endian, unlike in the Ogg Opus identification header.
+type DopsBox struct {
+	Version              byte
+	OutputChannelCount   byte
+	PreSkip              uint16 // Samples at 48kHz to discard at the start of the stream
+	InputSampleRate      uint32
+	OutputGain           int16 // Q7.8 dB
+	ChannelMappingFamily byte
+	StreamCount          byte   // Only present if ChannelMappingFamily != 0
+	CoupledCount         byte   // Only present if ChannelMappingFamily != 0
+	ChannelMapping       []byte // OutputChannelCount entries if ChannelMappingFamily != 0
+}
+
+// DecodeDops - box-specific decode
+func DecodeDops(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeDopsSR(hdr, startPos, sr)
+}
+
+// DecodeDopsSR - box-specific decode
+func DecodeDopsSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	b := DopsBox{}
+	b.Version = sr.ReadUint8()
+	b.OutputChannelCount = sr.ReadUint8()
+	b.PreSkip = sr.ReadUint16()
+	b.InputSampleRate = sr.ReadUint32()
+	b.OutputGain = sr.ReadInt16()
+	b.ChannelMappingFamily = sr.ReadUint8()
+	if b.ChannelMappingFamily != 0 {
+		b.StreamCount = sr.ReadUint8()
+		b.CoupledCount = sr.ReadUint8()
+		b.ChannelMapping = sr.ReadBytes(int(b.OutputChannelCount))
+	}
+	if err := sr.AccError(); err != nil {
+		return nil, err
+	}
+	if b.Size() != hdr.size {
+		return nil, fmt.Errorf("dOps size %d differs from %d given by channel mapping family %d",
+			hdr.size, b.Size(), b.ChannelMappingFamily)
+	}
+	return &b, nil
+}
+
+// Type - box type
+func (b *DopsBox) Type() string {
+	return "dOps"
+}
+
+// Size - calculated size of box
+func (b *DopsBox) Size() uint64 {
+	size := uint64(boxHeaderSize + 11)
+	if b.ChannelMappingFamily != 0 {
+		size += uint64(2 + len(b.ChannelMapping))
+	}
+	return size
+}
+
+// Encode - write box to w
+func (b *DopsBox) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *DopsBox) EncodeSW(sw bits.SliceWriter) error {
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	sw.WriteUint8(b.Version)
+	sw.WriteUint8(b.OutputChannelCount)
+	sw.WriteUint16(b.PreSkip)
+	sw.WriteUint32(b.InputSampleRate)
+	sw.WriteInt16(b.OutputGain)
+	sw.WriteUint8(b.ChannelMappingFamily)
+	if b.ChannelMappingFamily != 0 {
+		sw.WriteUint8(b.StreamCount)
+		sw.WriteUint8(b.CoupledCount)
+		sw.WriteBytes(b.ChannelMapping)
+	}
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *DopsBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd.write(" - version: %d", b.Version)
+	bd.write(" - outputChannelCount: %d", b.OutputChannelCount)
+	bd.write(" - preSkip: %d", b.PreSkip)
+	bd.write(" - inputSampleRate: %d", b.InputSampleRate)
+	bd.write(" - outputGain: %d", b.OutputGain)
+	bd.write(" - channelMappingFamily: %d", b.ChannelMappingFamily)
+	if b.ChannelMappingFamily != 0 {
+		bd.write(" - streamCount: %d coupledCount: %d", b.StreamCount, b.CoupledCount)
+		bd.write(" - channelMapping: %v", b.ChannelMapping)
+	}
+	return bd.err
+}
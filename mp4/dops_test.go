@@ -0,0 +1,23 @@
+package mp4
+
+import "testing"
+
+func TestDops(t *testing.T) {
+	family0 := &DopsBox{OutputChannelCount: 2, PreSkip: 312, InputSampleRate: 48000, OutputGain: -256}
+	boxDiffAfterEncodeAndDecode(t, family0)
+	if family0.Size() != 19 {
+		t.Errorf("got size %d instead of 19 for family 0", family0.Size())
+	}
+	family1 := &DopsBox{OutputChannelCount: 6, PreSkip: 312, InputSampleRate: 48000, ChannelMappingFamily: 1,
+		StreamCount: 4, CoupledCount: 2, ChannelMapping: []byte{0, 4, 1, 2, 3, 5}}
+	boxDiffAfterEncodeAndDecode(t, family1)
+	if family1.Size() != 27 {
+		t.Errorf("got size %d instead of 27 for family 1", family1.Size())
+	}
+
+	opus := CreateAudioSampleEntryBox("Opus", 2, 16, 48000, family0)
+	if opus.Dops != family0 {
+		t.Errorf("dOps not set in Opus sample entry")
+	}
+	boxDiffAfterEncodeAndDecode(t, opus)
+}
@@ -36,7 +36,7 @@ func (d *DrefBox) AddChild(box Box) {
 }
 
 // DecodeDref - box-specific decode
-func DecodeDref(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeDref(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	var versionAndFlags, entryCount uint32
 	err := binary.Read(r, binary.BigEndian, &versionAndFlags)
 	if err != nil {
@@ -68,7 +68,7 @@ func DecodeDref(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeDrefSR - box-specific decode
-func DecodeDrefSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeDrefSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	versionAndFlags := sr.ReadUint32()
 	entryCount := sr.ReadUint32()
 
@@ -103,6 +103,11 @@ func (d *DrefBox) Size() uint64 {
 	return containerSize(d.Children) + 8
 }
 
+// GetChildren - list of child boxes
+func (d *DrefBox) GetChildren() []Box {
+	return d.Children
+}
+
 // Encode - write dref box to w including children
 func (d *DrefBox) Encode(w io.Writer) error {
 	err := EncodeHeader(d, w)
@@ -1,13 +1,18 @@
 package mp4
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/edgeware/mp4ff/bits"
 )
 
-// ElngBox - Extended Language Box
+// ElngBox - Extended Language Box (elng)
+// ISO/IEC 14496-12 Section 8.4.6. Language is a BCP-47 tag like "en-US-x-captions".
+// Contained in Media Box (mdia)
 type ElngBox struct {
+	Version  byte
+	Flags    uint32
 	Language string
 }
 
@@ -17,21 +22,25 @@ func CreateElng(language string) *ElngBox {
 }
 
 // DecodeElng - box-specific decode
-func DecodeElng(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeElng(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
 	}
-	b := &ElngBox{
-		Language: string(data[:len(data)-1]),
-	}
-	return b, nil
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeElngSR(hdr, startPos, sr)
 }
 
 // DecodeElngSR - box-specific decode
-func DecodeElngSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeElngSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	if hdr.PayloadLen() < 5 {
+		return nil, fmt.Errorf("elng: payload size %d less than 5", hdr.PayloadLen())
+	}
+	versionAndFlags := sr.ReadUint32()
 	b := &ElngBox{
-		Language: string(sr.ReadZeroTerminatedString(hdr.payloadLen())),
+		Version:  byte(versionAndFlags >> 24),
+		Flags:    versionAndFlags & flagsMask,
+		Language: sr.ReadZeroTerminatedString(hdr.PayloadLen() - 4),
 	}
 	return b, sr.AccError()
 }
@@ -43,7 +52,7 @@ func (b *ElngBox) Type() string {
 
 // Size - calculated size of box
 func (b *ElngBox) Size() uint64 {
-	return uint64(boxHeaderSize + len(b.Language) + 1)
+	return uint64(boxHeaderSize + 4 + len(b.Language) + 1)
 }
 
 // Encode - write box to w
@@ -63,13 +72,15 @@ func (b *ElngBox) EncodeSW(sw bits.SliceWriter) error {
 	if err != nil {
 		return err
 	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
 	sw.WriteString(b.Language, true)
 	return sw.AccError()
 }
 
 // Info - write box-specific information
 func (b *ElngBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
-	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
 	bd.write(" - language: %s", b.Language)
 	return bd.err
 }
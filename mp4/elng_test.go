@@ -1,6 +1,8 @@
 package mp4
 
 import (
+	"bytes"
+	"encoding/hex"
 	"testing"
 )
 
@@ -8,4 +10,27 @@ func TestDecodeElng(t *testing.T) {
 
 	elng := &ElngBox{Language: "en-US"}
 	boxDiffAfterEncodeAndDecode(t, elng)
+	boxDiffAfterEncodeAndDecode(t, CreateElng("en-US-x-captions"))
+
+	var buf bytes.Buffer
+	err := CreateElng("sv-FI").Encode(&buf)
+	assertNoError(t, err)
+	wanted := "00000012656c6e6700000000" + "73762d464900"
+	if got := hex.EncodeToString(buf.Bytes()); got != wanted {
+		t.Errorf("got %s instead of %s", got, wanted)
+	}
+}
+
+func TestMdiaLanguage(t *testing.T) {
+	mdia := NewMdiaBox()
+	mdhd := &MdhdBox{}
+	mdhd.SetLanguage("eng")
+	mdia.AddChild(mdhd)
+	if lang := mdia.GetLanguage(); lang != "eng" {
+		t.Errorf("got language %q from mdhd", lang)
+	}
+	mdia.AddChild(CreateElng("en-US-x-captions"))
+	if lang := mdia.GetLanguage(); lang != "en-US-x-captions" {
+		t.Errorf("got language %q from elng", lang)
+	}
 }
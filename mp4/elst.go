@@ -24,7 +24,7 @@ type ElstEntry struct {
 }
 
 // DecodeElst - box-specific decode
-func DecodeElst(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeElst(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -34,7 +34,7 @@ func DecodeElst(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeElstSR - box-specific decode
-func DecodeElstSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeElstSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	versionAndFlags := sr.ReadUint32()
 	version := byte(versionAndFlags >> 24)
 	entryCount := sr.ReadUint32()
@@ -125,3 +125,49 @@ func (b *ElstBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string
 	}
 	return bd.err
 }
+
+// ApplyEditList - map media times (typically presentation times of samples) to the movie timeline.
+// The result is in mediaTimescale, while segment durations are in movieTimescale as in the elst box.
+// Empty edits (MediaTime == -1) and dwell edits (MediaRateInteger == 0) delay the following edits.
+// A time is mapped by the first normal edit whose media interval contains it, where a zero
+// SegmentDuration (as in fragmented files) means that the edit lasts to the end of the media.
+// Times outside all normal edits are mapped using the first normal edit.
+// Without normal edits, or if elst is nil, the times are only shifted by the empty edits.
+func ApplyEditList(elst *ElstBox, sampleTimes []uint64, mediaTimescale, movieTimescale uint32) []int64 {
+	type mapping struct {
+		mediaStart, mediaEnd uint64 // mediaEnd == 0 means no end
+		movieStart           int64  // in mediaTimescale
+	}
+	var mappings []mapping
+	var movieOffset uint64 // in movieTimescale
+	if elst != nil {
+		for _, e := range elst.Entries {
+			if e.MediaTime >= 0 && e.MediaRateInteger != 0 {
+				start := uint64(e.MediaTime)
+				movieStart := int64(RescaleTime(movieOffset, movieTimescale, mediaTimescale))
+				m := mapping{mediaStart: start, movieStart: movieStart}
+				if e.SegmentDuration > 0 {
+					m.mediaEnd = start + RescaleTime(e.SegmentDuration, movieTimescale, mediaTimescale)
+				}
+				mappings = append(mappings, m)
+			}
+			movieOffset += e.SegmentDuration
+		}
+	}
+	if len(mappings) == 0 {
+		movieStart := int64(RescaleTime(movieOffset, movieTimescale, mediaTimescale))
+		mappings = append(mappings, mapping{movieStart: movieStart})
+	}
+	times := make([]int64, len(sampleTimes))
+	for i, t := range sampleTimes {
+		m := mappings[0]
+		for _, cand := range mappings {
+			if cand.mediaStart <= t && (cand.mediaEnd == 0 || t < cand.mediaEnd) {
+				m = cand
+				break
+			}
+		}
+		times[i] = m.movieStart + int64(t) - int64(m.mediaStart)
+	}
+	return times
+}
@@ -27,3 +27,36 @@ func TestElst(t *testing.T) {
 		boxDiffAfterEncodeAndDecode(t, elst)
 	}
 }
+
+func TestApplyEditList(t *testing.T) {
+	// Empty edit of 2s in movie timescale 600, followed by an edit starting at media time 1000 (timescale 1000)
+	elst := &ElstBox{Entries: []ElstEntry{
+		{SegmentDuration: 1200, MediaTime: -1, MediaRateInteger: 1},
+		{SegmentDuration: 3000, MediaTime: 1000, MediaRateInteger: 1},
+	}}
+	testCases := []struct {
+		desc        string
+		elst        *ElstBox
+		sampleTimes []uint64
+		wanted      []int64
+	}{
+		{"empty then normal edit", elst, []uint64{1000, 1500, 5999, 500},
+			[]int64{2000, 2500, 6999, 1500}},
+		{"no edit list", nil, []uint64{0, 40}, []int64{0, 40}},
+		{"only empty edit", &ElstBox{Entries: []ElstEntry{{SegmentDuration: 300, MediaTime: -1, MediaRateInteger: 1}}},
+			[]uint64{0, 40}, []int64{500, 540}},
+		{"fragmented with zero duration", &ElstBox{Entries: []ElstEntry{{SegmentDuration: 0, MediaTime: 2000, MediaRateInteger: 1}}},
+			[]uint64{2000, 1 << 40}, []int64{0, 1<<40 - 2000}},
+	}
+	for _, tc := range testCases {
+		got := ApplyEditList(tc.elst, tc.sampleTimes, 1000, 600)
+		if len(got) != len(tc.wanted) {
+			t.Fatalf("%s: got %d times", tc.desc, len(got))
+		}
+		for i := range got {
+			if got[i] != tc.wanted[i] {
+				t.Errorf("%s: time %d mapped to %d instead of %d", tc.desc, tc.sampleTimes[i], got[i], tc.wanted[i])
+			}
+		}
+	}
+}
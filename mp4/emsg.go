@@ -1,6 +1,7 @@
 package mp4
 
 import (
+	"encoding/hex"
 	"fmt"
 	"io"
 
@@ -18,10 +19,11 @@ type EmsgBox struct {
 	ID                    uint32
 	SchemeIDURI           string
 	Value                 string
+	MessageData           []byte
 }
 
 // DecodeEmsg - box-specific decode
-func DecodeEmsg(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeEmsg(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -31,7 +33,7 @@ func DecodeEmsg(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeEmsgSR - box-specific decode
-func DecodeEmsgSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeEmsgSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	initPos := sr.GetPos()
 	versionAndFlags := sr.ReadUint32()
 	version := byte(versionAndFlags >> 24)
@@ -44,14 +46,14 @@ func DecodeEmsgSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, err
 		b.PresentationTime = sr.ReadUint64()
 		b.EventDuration = sr.ReadUint32()
 		b.ID = sr.ReadUint32()
-		maxLen := hdr.payloadLen() - (sr.GetPos() - initPos) - 1
+		maxLen := hdr.PayloadLen() - (sr.GetPos() - initPos) - 1
 		b.SchemeIDURI = sr.ReadZeroTerminatedString(maxLen)
-		maxLen = hdr.payloadLen() - (sr.GetPos() - initPos)
+		maxLen = hdr.PayloadLen() - (sr.GetPos() - initPos)
 		b.Value = sr.ReadZeroTerminatedString(maxLen)
 	} else if version == 0 {
-		maxLen := hdr.payloadLen() - (sr.GetPos() - initPos) - 17
+		maxLen := hdr.PayloadLen() - (sr.GetPos() - initPos) - 17
 		b.SchemeIDURI = sr.ReadZeroTerminatedString(maxLen)
-		maxLen = hdr.payloadLen() - (sr.GetPos() - initPos) - 16
+		maxLen = hdr.PayloadLen() - (sr.GetPos() - initPos) - 16
 		b.Value = sr.ReadZeroTerminatedString(maxLen)
 		b.TimeScale = sr.ReadUint32()
 		b.PresentationTimeDelta = sr.ReadUint32()
@@ -60,6 +62,9 @@ func DecodeEmsgSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, err
 	} else {
 		return nil, fmt.Errorf("Unknown version for emsg")
 	}
+	if rest := hdr.PayloadLen() - (sr.GetPos() - initPos); rest > 0 {
+		b.MessageData = sr.ReadBytes(rest)
+	}
 	return b, sr.AccError()
 }
 
@@ -71,9 +76,9 @@ func (b *EmsgBox) Type() string {
 // Size - calculated size of box
 func (b *EmsgBox) Size() uint64 {
 	if b.Version == 1 {
-		return uint64(boxHeaderSize + 4 + 4 + 8 + 4 + 4 + len(b.SchemeIDURI) + 1 + len(b.Value) + 1)
+		return uint64(boxHeaderSize + 4 + 4 + 8 + 4 + 4 + len(b.SchemeIDURI) + 1 + len(b.Value) + 1 + len(b.MessageData))
 	}
-	return uint64(boxHeaderSize + 4 + len(b.SchemeIDURI) + 1 + len(b.Value) + 1 + 4 + 4 + 4 + 4) // m.Version == 0
+	return uint64(boxHeaderSize + 4 + len(b.SchemeIDURI) + 1 + len(b.Value) + 1 + 4 + 4 + 4 + 4 + len(b.MessageData)) // m.Version == 0
 }
 
 // Encode - write box to w
@@ -110,6 +115,7 @@ func (b *EmsgBox) EncodeSW(sw bits.SliceWriter) error {
 		sw.WriteUint32(b.EventDuration)
 		sw.WriteUint32(b.ID)
 	}
+	sw.WriteBytes(b.MessageData)
 	return sw.AccError()
 }
 
@@ -127,5 +133,12 @@ func (b *EmsgBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string
 	if b.Version == 0 {
 		bd.write(" - presentationTimeDelta: %d", b.PresentationTimeDelta)
 	}
+	if len(b.MessageData) > 0 {
+		if getInfoLevel(b, specificBoxLevels) > 0 {
+			bd.write(" - messageData: %s", hex.EncodeToString(b.MessageData))
+		} else {
+			bd.write(" - messageData: %d bytes", len(b.MessageData))
+		}
+	}
 	return bd.err
 }
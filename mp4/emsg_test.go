@@ -1,6 +1,8 @@
 package mp4
 
 import (
+	"bytes"
+	"encoding/hex"
 	"testing"
 )
 
@@ -22,9 +24,44 @@ func TestEmsg(t *testing.T) {
 			ID:                    42,
 			SchemeIDURI:           "schid",
 			Value:                 "special"},
+		&EmsgBox{Version: 1,
+			TimeScale:        90000,
+			PresentationTime: 1 << 35,
+			EventDuration:    0xffffffff,
+			ID:               7,
+			SchemeIDURI:      "urn:scte:scte35:2013:bin",
+			Value:            "",
+			MessageData:      []byte{0xfc, 0x30, 0x11, 0x00},
+		},
+		&EmsgBox{Version: 0,
+			TimeScale:   1000,
+			ID:          8,
+			SchemeIDURI: "urn:mpeg:dash:event:2012",
+			Value:       "1",
+			MessageData: []byte{0, 1, 2}},
 	}
 
 	for _, inBox := range boxes {
 		boxDiffAfterEncodeAndDecode(t, inBox)
 	}
 }
+
+func TestEmsgStrings(t *testing.T) {
+	// Version 0 emsg with scheme "a:b", value "v", and message data 0x00ff after the fixed fields
+	raw, _ := hex.DecodeString("00000024" + "656d7367" + "00000000" + "613a6200" + "7600" +
+		"000003e8" + "00000064" + "000000c8" + "00000005" + "00ff")
+	box, err := DecodeBox(0, bytes.NewReader(raw))
+	assertNoError(t, err)
+	emsg := box.(*EmsgBox)
+	if emsg.SchemeIDURI != "a:b" || emsg.Value != "v" || emsg.TimeScale != 1000 ||
+		emsg.PresentationTimeDelta != 100 || emsg.EventDuration != 200 || emsg.ID != 5 ||
+		!bytes.Equal(emsg.MessageData, []byte{0x00, 0xff}) {
+		t.Errorf("got %+v", emsg)
+	}
+	var buf bytes.Buffer
+	err = emsg.Encode(&buf)
+	assertNoError(t, err)
+	if !bytes.Equal(buf.Bytes(), raw) {
+		t.Errorf("encoded %x instead of %x", buf.Bytes(), raw)
+	}
+}
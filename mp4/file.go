@@ -36,6 +36,9 @@ type File struct {
 	EncOptimize  EncOptimize     // Bit field with optimizations being done at encoding
 	isFragmented bool
 	fileDecMode  DecFileMode
+	decOptions   DecodeOptions
+	pendingPrft  *PrftBox // prft waiting for next fragment during decoding
+	progress     ProgressFunc
 }
 
 // EncFragFileMode - mode for writing file
@@ -137,6 +140,11 @@ func DecodeFile(r io.Reader, options ...Option) (*File, error) {
 	var boxStartPos uint64 = 0
 	lastBoxType := ""
 
+	var totalBytes uint64
+	if f.progress != nil {
+		totalBytes = remainingBytes(r)
+	}
+
 	var rs io.ReadSeeker
 	if f.fileDecMode == DecModeLazyMdat {
 		ok := false
@@ -151,9 +159,9 @@ LoopBoxes:
 		var box Box
 		var err error
 		if f.fileDecMode == DecModeLazyMdat {
-			box, err = DecodeBoxLazyMdat(boxStartPos, rs)
+			box, err = decodeBoxLazyMdat(boxStartPos, rs, &f.decOptions)
 		} else {
-			box, err = DecodeBox(boxStartPos, r)
+			box, err = decodeBox(boxStartPos, r, &f.decOptions)
 		}
 		if err == io.EOF {
 			break LoopBoxes
@@ -192,12 +200,38 @@ LoopBoxes:
 			}
 		}
 		f.AddChild(box, boxStartPos)
-		lastBoxType = boxType
+		if boxType != "prft" { // prft is accepted anywhere, also between moof and mdat
+			lastBoxType = boxType
+		}
 		boxStartPos += boxSize
+		if f.progress != nil {
+			f.progress(boxStartPos, totalBytes)
+		}
 	}
 	return f, nil
 }
 
+// remainingBytes - number of bytes from current position to end if r is an io.Seeker, otherwise 0
+func remainingBytes(r io.Reader) uint64 {
+	s, ok := r.(io.Seeker)
+	if !ok {
+		return 0
+	}
+	cur, err := s.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0
+	}
+	end, err := s.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0
+	}
+	_, err = s.Seek(cur, io.SeekStart)
+	if err != nil || end < cur {
+		return 0
+	}
+	return uint64(end - cur)
+}
+
 // Size - total size of all boxes
 func (f *File) Size() uint64 {
 	var totSize uint64 = 0
@@ -248,7 +282,19 @@ func (f *File) AddChild(box Box, boxStartPos uint64) {
 		}
 		newFragment := NewFragment()
 		currentSegment.AddFragment(newFragment)
+		if f.pendingPrft != nil {
+			newFragment.AddChild(f.pendingPrft)
+			f.pendingPrft = nil
+		}
 		newFragment.AddChild(moof)
+	case "prft":
+		// prft normally precedes moof, but may also be placed between moof and mdat
+		prft := box.(*PrftBox)
+		if lastFrag := f.lastFragmentWithoutMdat(); lastFrag != nil {
+			lastFrag.AddChild(prft)
+		} else {
+			f.pendingPrft = prft
+		}
 	case "mdat":
 		mdat := box.(*MdatBox)
 		if !f.isFragmented {
@@ -261,6 +307,18 @@ func (f *File) AddChild(box Box, boxStartPos uint64) {
 	f.Children = append(f.Children, box)
 }
 
+// lastFragmentWithoutMdat - last fragment if it has a moof, but no mdat yet
+func (f *File) lastFragmentWithoutMdat() *Fragment {
+	if len(f.Segments) == 0 || len(f.LastSegment().Fragments) == 0 {
+		return nil
+	}
+	frag := f.LastSegment().LastFragment()
+	if frag.Moof == nil || frag.Mdat != nil {
+		return nil
+	}
+	return frag
+}
+
 // DumpWithSampleData - print information about file and its children boxes
 func (f *File) DumpWithSampleData(w io.Writer, specificBoxLevels string) error {
 	if f.isFragmented {
@@ -303,6 +361,9 @@ func (f *File) DumpWithSampleData(w io.Writer, specificBoxLevels string) error {
 // Encode - encode a file to a Writer
 // Fragmented files are encoded based on InitSegment and MediaSegments, unless EncodeVerbatim is set.
 func (f *File) Encode(w io.Writer) error {
+	if f.progress != nil {
+		w = &progressWriter{w: w, progress: f.progress, totalBytes: f.encodedSize()}
+	}
 	if f.isFragmented {
 		switch f.FragEncMode {
 		case EncModeSegment:
@@ -349,6 +410,40 @@ func (f *File) Encode(w io.Writer) error {
 	return nil
 }
 
+// encodedSize - expected size of the encoded file.
+// For EncModeSegment, trun optimization during encoding may make the actual size smaller.
+func (f *File) encodedSize() uint64 {
+	if !f.isFragmented || f.FragEncMode != EncModeSegment {
+		return f.Size()
+	}
+	var size uint64
+	if f.Init != nil {
+		size += f.Init.Size()
+	}
+	if f.Sidx != nil {
+		size += f.Sidx.Size()
+	}
+	for _, seg := range f.Segments {
+		size += seg.Size()
+	}
+	return size
+}
+
+// progressWriter - writer calling progress after every write
+type progressWriter struct {
+	w            io.Writer
+	progress     ProgressFunc
+	bytesWritten uint64
+	totalBytes   uint64
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.bytesWritten += uint64(n)
+	p.progress(p.bytesWritten, p.totalBytes)
+	return n, err
+}
+
 // EncodeSW - encode a file to a SliceWriter
 // Fragmented files are encoded based on InitSegment and MediaSegments, unless EncodeVerbatim is set.
 func (f *File) EncodeSW(sw bits.SliceWriter) error {
@@ -419,6 +514,27 @@ func (f *File) IsFragmented() bool {
 	return f.isFragmented
 }
 
+// GetChildren - top-level boxes in order
+func (f *File) GetChildren() []Box {
+	return f.Children
+}
+
+// GetPsshs - all pssh boxes in moov and moof boxes in file order
+func (f *File) GetPsshs() []*PsshBox {
+	var psshs []*PsshBox
+	if f.Moov != nil {
+		psshs = append(psshs, f.Moov.Psshs...)
+	}
+	for _, seg := range f.Segments {
+		for _, frag := range seg.Fragments {
+			if frag.Moof != nil {
+				psshs = append(psshs, frag.Moof.Psshs...)
+			}
+		}
+	}
+	return psshs
+}
+
 // ApplyOptions - applies options for decoding or encoding a file
 func (f *File) ApplyOptions(opts ...Option) {
 	for _, opt := range opts {
@@ -440,6 +556,38 @@ func WithDecodeMode(mode DecFileMode) Option {
 	return func(f *File) { f.fileDecMode = mode }
 }
 
+// WithUnknownBoxStats sets up tallying of unknown boxes per box type into stats during decoding
+func WithUnknownBoxStats(stats map[string]UnknownBoxStat) Option {
+	return func(f *File) { f.decOptions.UnknownStats = stats }
+}
+
+// WithKeepBoxOrder sets up decoding to keep the original order of the boxes in moov.
+// Together with EncModeBoxTree, the boxes are then encoded in the same order as in the input.
+func WithKeepBoxOrder() Option {
+	return func(f *File) { f.decOptions.KeepBoxOrder = true }
+}
+
+// WithLenientTrun sets up decoding to repair trun boxes whose flags signal more per-sample fields
+// than the box size allows. See TrunBox.NormalizeFlags.
+func WithLenientTrun() Option {
+	return func(f *File) { f.decOptions.LenientTrun = true }
+}
+
+// WithRepairedTrunCount sets up counting of the trun boxes repaired because of WithLenientTrun into count
+func WithRepairedTrunCount(count *int) Option {
+	return func(f *File) { f.decOptions.RepairedTruns = count }
+}
+
+// ProgressFunc - callback with bytes processed so far and total number of bytes (0 if unknown)
+type ProgressFunc func(bytesProcessed, totalBytes uint64)
+
+// WithProgress sets up a callback called after every top-level box during decoding,
+// and after every write during encoding.
+// When decoding, totalBytes is only known if the reader is an io.Seeker or a SliceReader.
+func WithProgress(progress ProgressFunc) Option {
+	return func(f *File) { f.progress = progress }
+}
+
 // CopySampleData - copy sample data from a track in a progressive mp4 file to w. Use rs if lazy read.
 func (f *File) CopySampleData(w io.Writer, rs io.ReadSeeker, trak *TrakBox, startSampleNr, endSampleNr uint32) error {
 	if f.isFragmented {
@@ -457,17 +605,14 @@ func (f *File) CopySampleData(w io.Writer, rs io.ReadSeeker, trak *TrakBox, star
 	if err != nil {
 		return err
 	}
-	var chunkOffsets []uint64
-	if stbl.Stco != nil {
-		chunkOffsets = make([]uint64, len(stbl.Stco.ChunkOffset))
-		for i := range stbl.Stco.ChunkOffset {
-			chunkOffsets[i] = uint64(stbl.Stco.ChunkOffset[i])
-		}
-	} else if stbl.Co64 != nil {
-		chunkOffsets = stbl.Co64.ChunkOffset
-	} else {
+	chunkOffsets := stbl.chunkOffsets()
+	if chunkOffsets == nil {
 		return fmt.Errorf("neither stco nor co64 available")
 	}
+	sampleSizes := stbl.SampleSizes()
+	if sampleSizes == nil {
+		return fmt.Errorf("neither stsz nor stz2 available")
+	}
 	var startNr, endNr uint32
 	var offset uint64
 	for i, chunk := range chunks {
@@ -476,7 +621,7 @@ func (f *File) CopySampleData(w io.Writer, rs io.ReadSeeker, trak *TrakBox, star
 		offset = chunkOffsets[chunk.ChunkNr-1]
 		if i == 0 {
 			for sNr := chunk.StartSampleNr; sNr < startSampleNr; sNr++ {
-				offset += uint64(stbl.Stsz.GetSampleSize(int(sNr)))
+				offset += uint64(sampleSizes.GetSampleSize(int(sNr)))
 			}
 			startNr = startSampleNr
 		}
@@ -486,7 +631,7 @@ func (f *File) CopySampleData(w io.Writer, rs io.ReadSeeker, trak *TrakBox, star
 		}
 		var size int64
 		for sNr := startNr; sNr <= endNr; sNr++ {
-			size += int64(stbl.Stsz.GetSampleSize(int(sNr)))
+			size += int64(sampleSizes.GetSampleSize(int(sNr)))
 		}
 		if mdat.IsLazy() {
 			_, err := rs.Seek(int64(offset), io.SeekStart)
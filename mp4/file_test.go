@@ -122,3 +122,50 @@ func TestDecodeEncodeProgressiveSliceWriter(t *testing.T) {
 		t.Errorf("output differs from input")
 	}
 }
+
+func TestDecodeEncodeWithProgress(t *testing.T) {
+	rawInput, err := ioutil.ReadFile("./testdata/1.m4s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decCalls int
+	var lastDec, decTotal uint64
+	decProgress := func(processed, total uint64) {
+		if processed <= lastDec {
+			t.Errorf("progress %d not larger than previous %d", processed, lastDec)
+		}
+		decCalls++
+		lastDec, decTotal = processed, total
+	}
+	f, err := DecodeFile(bytes.NewReader(rawInput), WithProgress(decProgress), WithEncodeMode(EncModeBoxTree))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decCalls != len(f.Children) || lastDec != uint64(len(rawInput)) || decTotal != uint64(len(rawInput)) {
+		t.Errorf("got %d calls and %d/%d bytes for %d boxes and %d bytes",
+			decCalls, lastDec, decTotal, len(f.Children), len(rawInput))
+	}
+
+	var lastEnc, encTotal uint64
+	f.ApplyOptions(WithProgress(func(processed, total uint64) { lastEnc, encTotal = processed, total }))
+	buf := bytes.Buffer{}
+	err = f.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), rawInput) {
+		t.Errorf("encoded file differs from input")
+	}
+	if lastEnc != uint64(len(rawInput)) || encTotal != uint64(len(rawInput)) {
+		t.Errorf("got encode progress %d/%d instead of %d", lastEnc, encTotal, len(rawInput))
+	}
+
+	lastDec = 0
+	_, err = DecodeFileSR(bits.NewFixedSliceReader(rawInput), WithProgress(decProgress))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastDec != uint64(len(rawInput)) || decTotal != uint64(len(rawInput)) {
+		t.Errorf("got SR decode progress %d/%d instead of %d", lastDec, decTotal, len(rawInput))
+	}
+}
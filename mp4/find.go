@@ -0,0 +1,44 @@
+package mp4
+
+// FindBoxes - all boxes of type boxType below root in depth-first order.
+// root can be any box, *File, or *Fragment. Children are found via GetChildren(),
+// so the search goes through all container boxes, but root itself is not included.
+func FindBoxes(root interface{}, boxType string) []Box {
+	var found []Box
+	for _, child := range getChildren(root) {
+		if child.Type() == boxType {
+			found = append(found, child)
+		}
+		found = append(found, FindBoxes(child, boxType)...)
+	}
+	return found
+}
+
+// FindFirstBox - first box below root reached via the box types in path, or nil if none.
+// For example, FindFirstBox(file, "moov", "trak", "mdia", "minf") returns the minf box of the first
+// trak that has one. An empty path returns nil.
+func FindFirstBox(root interface{}, path ...string) Box {
+	if len(path) == 0 {
+		return nil
+	}
+	for _, child := range getChildren(root) {
+		if child.Type() != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			return child
+		}
+		if box := FindFirstBox(child, path[1:]...); box != nil {
+			return box
+		}
+	}
+	return nil
+}
+
+// getChildren - children of parent, or nil if it has no GetChildren method
+func getChildren(parent interface{}) []Box {
+	if p, ok := parent.(interface{ GetChildren() []Box }); ok {
+		return p.GetChildren()
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package mp4
+
+import "testing"
+
+func TestFindBoxes(t *testing.T) {
+	frag := NewFragment()
+	moof := &MoofBox{}
+	_ = moof.AddChild(&MfhdBox{SequenceNumber: 1})
+	for _, trackID := range []uint32{1, 2} {
+		traf := &TrafBox{}
+		_ = traf.AddChild(&TfhdBox{TrackID: trackID})
+		_ = traf.AddChild(&TfdtBox{BaseMediaDecodeTime: 0})
+		_ = traf.AddChild(CreateTrun(0))
+		_ = moof.AddChild(traf)
+	}
+	frag.AddChild(moof)
+	frag.AddChild(&MdatBox{})
+
+	tfhds := FindBoxes(frag, "tfhd")
+	if len(tfhds) != 2 || tfhds[0].(*TfhdBox).TrackID != 1 || tfhds[1].(*TfhdBox).TrackID != 2 {
+		t.Errorf("got %d tfhd boxes, not trackID 1 and 2 in order", len(tfhds))
+	}
+	if n := len(FindBoxes(moof, "trun")); n != 2 {
+		t.Errorf("got %d trun boxes instead of 2", n)
+	}
+	if n := len(FindBoxes(moof, "moof")); n != 0 {
+		t.Errorf("root included in result")
+	}
+	tfdt := FindFirstBox(frag, "moof", "traf", "tfdt")
+	if tfdt != moof.Trafs[0].Tfdt {
+		t.Errorf("got %v instead of tfdt in first traf", tfdt)
+	}
+	if box := FindFirstBox(frag, "moof", "trak"); box != nil {
+		t.Errorf("got %s for missing path", box.Type())
+	}
+	if box := FindFirstBox(moof); box != nil {
+		t.Errorf("got %s for empty path", box.Type())
+	}
+
+	f := NewFile()
+	f.AddChild(moof, 0)
+	if box := FindFirstBox(f, "moof", "mfhd"); box != moof.Mfhd {
+		t.Errorf("mfhd not found from file")
+	}
+	if n := len(FindBoxes(&MdatBox{}, "mdat")); n != 0 {
+		t.Errorf("found %d boxes below leaf box", n)
+	}
+}
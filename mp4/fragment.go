@@ -1,22 +1,29 @@
 package mp4
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"sort"
 
 	"github.com/edgeware/mp4ff/bits"
 )
 
-// Fragment - MP4 Fragment ([prft] + moof + mdat)
+// Fragment - MP4 Fragment ([styp] + [emsg]* + [prft] + moof + mdat)
+// A styp box is only part of a fragment when added explicitly, as for low-latency CMAF chunks.
 type Fragment struct {
-	Prft        *PrftBox
-	Moof        *MoofBox
-	Mdat        *MdatBox
-	Children    []Box       // All top-level boxes in order
-	nextTrunNr  uint32      // To handle multi-trun cases
-	EncOptimize EncOptimize // Bit field with optimizations being done at encoding
+	Styp              *StypBox
+	Emsgs             []*EmsgBox
+	Prft              *PrftBox
+	Moof              *MoofBox
+	Mdat              *MdatBox
+	Children          []Box           // All top-level boxes in order
+	nextTrunNr        uint32          // To handle multi-trun cases
+	newTrunTrackIDs   map[uint32]bool // Tracks where the next added sample starts a new trun
+	EncOptimize       EncOptimize     // Bit field with optimizations being done at encoding
+	inMemoryPositions bool            // Moof and mdat positions are derived for a fragment built in memory
 }
 
 // NewFragment - New empty one-track MP4 Fragment
@@ -68,9 +75,130 @@ func CreateMultiTrackFragment(seqNumber uint32, trackIDs []uint32) (*Fragment, e
 	return f, nil
 }
 
+// FragmentFromSamples - create a single-track fragment from samples, like those from GetFullSamples.
+// tfdt is set from the decode time of the first sample, and the trun has per-sample values.
+// Since the decode times follow from the durations in trun, the duration of every sample but
+// the last is set to the difference to the next decode time. A gap after a sample, for example
+// from dropped frames, thus extends its duration. The input samples are not changed.
+// An error is returned if the decode times are not increasing.
+func FragmentFromSamples(seqNr, trackID uint32, samples []*FullSample) (*Fragment, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no samples")
+	}
+	f, err := CreateFragment(seqNr, trackID)
+	if err != nil {
+		return nil, err
+	}
+	for i, s := range samples {
+		fs := *s
+		if i+1 < len(samples) {
+			next := samples[i+1].DecodeTime
+			if next <= s.DecodeTime || next-s.DecodeTime > math.MaxUint32 {
+				return nil, fmt.Errorf("sample %d: decode time %d does not fit after %d", i+2, next, s.DecodeTime)
+			}
+			fs.Dur = uint32(next - s.DecodeTime)
+		}
+		err = f.AddFullSampleChecked(fs)
+		if err != nil {
+			return nil, fmt.Errorf("sample %d: %w", i+1, err)
+		}
+	}
+	return f, nil
+}
+
+// SplitFragment - split a single-track fragment with sample data in memory into two fragments,
+// where after starts with the sample with decode time splitTime. trex provides sample defaults.
+// before keeps the sequence number and the other top-level boxes of f, like styp, emsg, and prft,
+// which are placed before moof. after gets the next sequence number, so later fragments may need
+// to be renumbered. Both fragments get a trun with per-sample values.
+// Per-sample boxes like senc, saiz, saio, sbgp, sgpd, sdtp, and subs are not split, so an error is
+// returned if the traf has any other boxes than tfhd, tfdt, and trun, or if moof has a pssh box.
+// An error with the nearest sample boundaries is returned if splitTime is not at a sample boundary
+// strictly inside the fragment.
+func SplitFragment(f *Fragment, splitTime uint64, trex *TrexBox) (before, after *Fragment, err error) {
+	if f.Moof == nil || f.Mdat == nil {
+		return nil, nil, fmt.Errorf("fragment lacks moof or mdat")
+	}
+	if len(f.Moof.Trafs) != 1 {
+		return nil, nil, fmt.Errorf("splitting needs exactly one traf, not %d", len(f.Moof.Trafs))
+	}
+	for _, c := range f.Moof.Children {
+		if c.Type() != "mfhd" && c.Type() != "traf" {
+			return nil, nil, fmt.Errorf("cannot split moof with %s box", c.Type())
+		}
+	}
+	for _, c := range f.Moof.Traf.Children {
+		switch c.Type() {
+		case "tfhd", "tfdt", "trun":
+		default:
+			return nil, nil, fmt.Errorf("cannot split traf with %s box", c.Type())
+		}
+	}
+	samples, err := f.GetFullSamples(trex)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(samples) < 2 {
+		return nil, nil, fmt.Errorf("cannot split fragment with %d samples", len(samples))
+	}
+	splitIdx := -1
+	for i := 1; i < len(samples); i++ {
+		if samples[i].DecodeTime == splitTime {
+			splitIdx = i
+			break
+		}
+	}
+	if splitIdx < 0 {
+		lower, upper := samples[1].DecodeTime, samples[len(samples)-1].DecodeTime
+		for i := 1; i < len(samples); i++ {
+			if samples[i].DecodeTime < splitTime {
+				lower = samples[i].DecodeTime
+			} else {
+				upper = samples[i].DecodeTime
+				break
+			}
+		}
+		return nil, nil, fmt.Errorf("split time %d is not a sample boundary inside the fragment, nearest are %d and %d",
+			splitTime, lower, upper)
+	}
+	parts := make([][]*FullSample, 2)
+	for i := range samples {
+		part := 0
+		if i >= splitIdx {
+			part = 1
+		}
+		parts[part] = append(parts[part], &samples[i])
+	}
+	seqNr := f.Moof.Mfhd.SequenceNumber
+	trackID := f.Moof.Traf.Tfhd.TrackID
+	before, err = FragmentFromSamples(seqNr, trackID, parts[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	after, err = FragmentFromSamples(seqNr+1, trackID, parts[1])
+	if err != nil {
+		return nil, nil, err
+	}
+	children := before.Children
+	before.Children = nil
+	for _, c := range f.Children {
+		if c != f.Moof && c != f.Mdat {
+			before.AddChild(c)
+		}
+	}
+	for _, c := range children {
+		before.AddChild(c)
+	}
+	return before, after, nil
+}
+
 // AddChild - Add a top-level box to Fragment
 func (f *Fragment) AddChild(b Box) {
 	switch b.Type() {
+	case "styp":
+		f.Styp = b.(*StypBox)
+	case "emsg":
+		f.Emsgs = append(f.Emsgs, b.(*EmsgBox))
 	case "prft":
 		f.Prft = b.(*PrftBox)
 	case "moof":
@@ -91,53 +219,338 @@ func (f *Fragment) Size() uint64 {
 	return size
 }
 
-// GetFullSamples - Get full samples including media and accumulated time
+// GetFullSamples - Get full samples including media and accumulated time.
+// For a fragment built in memory, mdat is assumed to follow moof in the order of the children,
+// with the sample data laid out in trun write order. The sample data may be stored in mdat
+// as one slice or as multiple parts. The fragment is not changed.
 func (f *Fragment) GetFullSamples(trex *TrexBox) ([]FullSample, error) {
-	moof := f.Moof
 	mdat := f.Mdat
-	//seqNr := moof.Mfhd.SequenceNumber
-	var traf *TrafBox
-	foundTrak := false
-	if trex != nil {
-		for _, traf = range moof.Trafs {
-			if traf.Tfhd.TrackID == trex.TrackID {
-				foundTrak = true
-				break
-			}
-		}
-		if !foundTrak {
-			return nil, nil // This trackID may not exist for this fragment
-		}
-	} else {
-		traf = moof.Traf // The first one
+	traf := f.trafForTrex(trex)
+	if traf == nil {
+		return nil, nil // This trackID may not exist for this fragment
 	}
 	tfhd := traf.Tfhd
 	baseTime := traf.Tfdt.BaseMediaDecodeTime
-	moofStartPos := moof.StartPos
-	var samples []FullSample
+	samples := make([]FullSample, 0) // Empty, not nil, for heartbeat fragments without samples
 	for _, trun := range traf.Truns {
-		totalDur := trun.AddSampleDefaultValues(tfhd, trex)
-		var baseOffset uint64
-		if tfhd.HasBaseDataOffset() {
-			baseOffset = tfhd.BaseDataOffset
-		} else if tfhd.DefaultBaseIfMoof() {
-			baseOffset = moofStartPos
-		}
-		if trun.HasDataOffset() {
-			baseOffset = uint64(int64(trun.DataOffset) + int64(baseOffset))
-		}
-		mdatDataLength := uint64(len(mdat.Data)) // len should be fine for 64-bit
-		offsetInMdat := baseOffset - mdat.PayloadAbsoluteOffset()
-		if offsetInMdat > mdatDataLength {
-			return nil, errors.New("Offset in mdata beyond size")
-		}
-		samples = append(samples, trun.GetFullSamples(uint32(offsetInMdat), baseTime, mdat)...)
+		offsetInMdat, err := f.trunOffsetInMdat(tfhd, trex, trun)
+		if err != nil {
+			return nil, err
+		}
+		filled, totalDur := trun.withDefaultValues(tfhd, trex)
+		data, err := mdat.dataRange(offsetInMdat, filled.SizeOfData())
+		if err != nil {
+			return nil, fmt.Errorf("Offset in mdata beyond size: %w", err)
+		}
+		samples = append(samples, filled.GetFullSamples(0, baseTime, &MdatBox{Data: data})...)
 		baseTime += totalDur // Next trun start after this
 	}
 
 	return samples, nil
 }
 
+// GetFullSamplesFromReader - like GetFullSamples, but also works for lazy mdat.
+// For lazy mdat, the sample data of one trun at a time is read from rs, which should be
+// positioned like the source the fragment was decoded from. rs is not used if the mdat data is in memory.
+func (f *Fragment) GetFullSamplesFromReader(trex *TrexBox, rs io.ReadSeeker) ([]FullSample, error) {
+	if !f.Mdat.IsLazy() {
+		return f.GetFullSamples(trex)
+	}
+	traf := f.trafForTrex(trex)
+	if traf == nil {
+		return nil, nil // This trackID may not exist for this fragment
+	}
+	tfhd := traf.Tfhd
+	baseTime := traf.Tfdt.BaseMediaDecodeTime
+	payloadStart := f.mdatPayloadStart()
+	samples := make([]FullSample, 0)
+	for _, trun := range traf.Truns {
+		filled, totalDur := trun.withDefaultValues(tfhd, trex)
+		offsetInMdat, err := f.trunOffsetInMdat(tfhd, trex, trun)
+		if err != nil {
+			return nil, err
+		}
+		size := filled.SizeOfData()
+		if offsetInMdat+size > f.Mdat.payloadSize() {
+			return nil, fmt.Errorf("trun data for track %d beyond end of mdat", tfhd.TrackID)
+		}
+		trunMdat := &MdatBox{}
+		if size > 0 {
+			trunMdat.Data, err = f.Mdat.ReadData(int64(payloadStart+offsetInMdat), int64(size), rs)
+			if err != nil {
+				return nil, err
+			}
+		}
+		samples = append(samples, filled.GetFullSamples(0, baseTime, trunMdat)...)
+		baseTime += totalDur
+	}
+	return samples, nil
+}
+
+// SampleFileOffsets - get absolute file offset of every sample of the track given by trex.
+// The first track is used if trex is nil. Works also for lazy mdat since no sample data is accessed.
+// The fragment is not changed.
+func (f *Fragment) SampleFileOffsets(trex *TrexBox) ([]uint64, error) {
+	if f.Moof == nil || f.Mdat == nil {
+		return nil, fmt.Errorf("fragment lacks moof or mdat")
+	}
+	traf := f.trafForTrex(trex)
+	if traf == nil {
+		return nil, noTrafError(trex)
+	}
+	tfhd := traf.Tfhd
+	payloadStart := f.mdatPayloadStart()
+	mdatDataLength := f.Mdat.payloadSize()
+	offsets := make([]uint64, 0)
+	for _, trun := range traf.Truns {
+		filled, _ := trun.withDefaultValues(tfhd, trex)
+		offsetInMdat, err := f.trunOffsetInMdat(tfhd, trex, trun)
+		if err != nil {
+			return nil, err
+		}
+		if offsetInMdat+filled.SizeOfData() > mdatDataLength {
+			return nil, fmt.Errorf("trun data for track %d beyond end of mdat", tfhd.TrackID)
+		}
+		pos := payloadStart + offsetInMdat
+		for _, s := range filled.Samples {
+			offsets = append(offsets, pos)
+			pos += uint64(s.Size)
+		}
+	}
+	return offsets, nil
+}
+
+// Validate - check that the sample data of every trun lies within the mdat payload,
+// that the data of different truns does not overlap, and that the sample sizes add up to the payload size.
+// The trun data offsets are checked as they are, so for a fragment built in memory,
+// call SetTrunDataOffsets (done by Encode) first.
+// Sample sizes must be present in the truns or as tfhd defaults, since trex is not available.
+// The fragment is not changed.
+func (f *Fragment) Validate() error {
+	if f.Moof == nil || f.Mdat == nil {
+		return fmt.Errorf("fragment lacks moof or mdat")
+	}
+	payloadStart := f.mdatPayloadStart()
+	type dataRange struct {
+		trackID    uint32
+		start, end uint64
+	}
+	var ranges []dataRange
+	payloadSize := f.Mdat.payloadSize()
+	var totalSize uint64
+	for _, traf := range f.Moof.Trafs {
+		tfhd := traf.Tfhd
+		for i, trun := range traf.Truns {
+			if !trun.HasSampleSize() && !tfhd.HasDefaultSampleSize() && trun.SampleCount() > 0 {
+				return fmt.Errorf("track %d trun %d: no sample sizes in trun or tfhd", tfhd.TrackID, i+1)
+			}
+			offsetInMdat, err := f.statedTrunOffset(tfhd, nil, trun, payloadStart)
+			if err != nil {
+				return fmt.Errorf("track %d trun %d: %w", tfhd.TrackID, i+1, err)
+			}
+			size := trunDataSize(tfhd, nil, trun)
+			if offsetInMdat+size > payloadSize {
+				return fmt.Errorf("track %d trun %d: data [%d, %d) beyond mdat payload size %d",
+					tfhd.TrackID, i+1, offsetInMdat, offsetInMdat+size, payloadSize)
+			}
+			ranges = append(ranges, dataRange{tfhd.TrackID, offsetInMdat, offsetInMdat + size})
+			totalSize += size
+		}
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].start < ranges[j].start })
+	for i := 1; i < len(ranges); i++ {
+		if ranges[i].start < ranges[i-1].end {
+			return fmt.Errorf("trun data of track %d overlaps trun data of track %d at offset %d in mdat",
+				ranges[i].trackID, ranges[i-1].trackID, ranges[i].start)
+		}
+	}
+	if totalSize != payloadSize {
+		return fmt.Errorf("sum of sample sizes %d differs from mdat payload size %d", totalSize, payloadSize)
+	}
+	return nil
+}
+
+// trafForTrex - traf with same trackID as trex, or first traf if trex is nil
+func (f *Fragment) trafForTrex(trex *TrexBox) *TrafBox {
+	if trex == nil {
+		return f.Moof.Traf
+	}
+	for _, traf := range f.Moof.Trafs {
+		if traf.Tfhd.TrackID == trex.TrackID {
+			return traf
+		}
+	}
+	return nil
+}
+
+// noTrafError - error for trafForTrex returning nil
+func noTrafError(trex *TrexBox) error {
+	if trex == nil {
+		return fmt.Errorf("no traf in fragment")
+	}
+	return fmt.Errorf("no traf for trackID %d", trex.TrackID)
+}
+
+// SyncSamples - get zero-based indices of sync samples for the track given by trex.
+// The first track is used if trex is nil. Sample data in mdat is not accessed,
+// and the trun samples are not changed.
+func (f *Fragment) SyncSamples(trex *TrexBox) ([]uint32, error) {
+	if f.Moof == nil {
+		return nil, fmt.Errorf("moof not set in fragment")
+	}
+	traf := f.trafForTrex(trex)
+	if traf == nil {
+		return nil, noTrafError(trex)
+	}
+	var syncIndices []uint32
+	var nr uint32
+	for _, trun := range traf.Truns {
+		filled, _ := trun.withDefaultValues(traf.Tfhd, trex)
+		for i := range filled.Samples {
+			if filled.Samples[i].IsSync() {
+				syncIndices = append(syncIndices, nr)
+			}
+			nr++
+		}
+	}
+	return syncIndices, nil
+}
+
+// Duration - total sample duration of the track given by trex (the first track if trex is nil).
+// Default durations are taken from tfhd or trex as in GetFullSamples, but mdat is not accessed
+// and the trun samples are not changed. Zero is returned if the track is not in the fragment.
+func (f *Fragment) Duration(trex *TrexBox) uint64 {
+	if f.Moof == nil {
+		return 0
+	}
+	traf := f.trafForTrex(trex)
+	if traf == nil {
+		return 0
+	}
+	var defaultSampleDuration uint32
+	if traf.Tfhd.HasDefaultSampleDuration() {
+		defaultSampleDuration = traf.Tfhd.DefaultSampleDuration
+	} else if trex != nil {
+		defaultSampleDuration = trex.DefaultSampleDuration
+	}
+	var dur uint64
+	for _, trun := range traf.Truns {
+		dur += trun.Duration(defaultSampleDuration)
+	}
+	return dur
+}
+
+// SampleReaders - get one reader per sample for the track given by trex (the first track if trex is nil).
+// The readers are bounded views that alias the mdat data buffer or parts, so sample data is only copied
+// for a sample that spans several mdat data parts.
+// The mdat data must therefore not be modified while the readers are in use.
+// An error is returned for a fragment without moof or mdat, and for lazy mdat, since its data is not in memory.
+func (f *Fragment) SampleReaders(trex *TrexBox) ([]io.Reader, error) {
+	if f.Moof == nil || f.Mdat == nil {
+		return nil, errors.New("fragment lacks moof or mdat")
+	}
+	if f.Mdat.IsLazy() {
+		return nil, errors.New("no sample data in lazy mdat")
+	}
+	traf := f.trafForTrex(trex)
+	if traf == nil {
+		return nil, nil // This trackID may not exist for this fragment
+	}
+	readers := make([]io.Reader, 0)
+	for _, trun := range traf.Truns {
+		filled, _ := trun.withDefaultValues(traf.Tfhd, trex)
+		offset, err := f.trunOffsetInMdat(traf.Tfhd, trex, trun)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range filled.Samples {
+			data, err := f.Mdat.dataRange(offset, uint64(s.Size))
+			if err != nil {
+				return nil, fmt.Errorf("sample data beyond end of mdat: %w", err)
+			}
+			readers = append(readers, bytes.NewReader(data))
+			offset += uint64(s.Size)
+		}
+	}
+	return readers, nil
+}
+
+// trunOffsetInMdat - offset of first sample of trun relative to the start of mdat payload.
+// For a fragment built in memory, the offset is given by the trun write order, like in SetTrunDataOffsets.
+// Otherwise, the offset is given by the data offsets in tfhd and trun, see statedTrunOffset.
+func (f *Fragment) trunOffsetInMdat(tfhd *TfhdBox, trex *TrexBox, trun *TrunBox) (uint64, error) {
+	if f.inMemoryLayout() {
+		var offset uint64
+		for _, t := range f.trunsInWriteOrder() {
+			if t == trun {
+				return offset, nil
+			}
+			offset += t.SizeOfData()
+		}
+		return 0, fmt.Errorf("trun not in fragment")
+	}
+	return f.statedTrunOffset(tfhd, trex, trun, f.Mdat.PayloadAbsoluteOffset())
+}
+
+// statedTrunOffset - offset of first sample of trun relative to the mdat payload at payloadStart,
+// as given by the data offsets in tfhd and trun.
+// A trun without data offset continues right after the data of the previous trun in the same traf,
+// while a first trun without data offset starts at the base data offset.
+// trex, which may be nil, provides the default sample size if not in trun or tfhd.
+func (f *Fragment) statedTrunOffset(tfhd *TfhdBox, trex *TrexBox, trun *TrunBox, payloadStart uint64) (uint64, error) {
+	if !trun.HasDataOffset() {
+		if prev := f.previousTrun(tfhd, trun); prev != nil {
+			prevOffset, err := f.statedTrunOffset(tfhd, trex, prev, payloadStart)
+			if err != nil {
+				return 0, err
+			}
+			return prevOffset + trunDataSize(tfhd, trex, prev), nil
+		}
+	}
+	var baseOffset uint64
+	if tfhd.HasBaseDataOffset() {
+		baseOffset = tfhd.BaseDataOffset
+	} else if tfhd.DefaultBaseIfMoof() {
+		baseOffset = f.Moof.StartPos
+	}
+	if trun.HasDataOffset() {
+		baseOffset = uint64(int64(trun.DataOffset) + int64(baseOffset))
+	}
+	if baseOffset < payloadStart {
+		return 0, fmt.Errorf("trun data at %d starts before mdat payload at %d", baseOffset, payloadStart)
+	}
+	return baseOffset - payloadStart, nil
+}
+
+// previousTrun - trun before trun in the traf with tfhd, or nil if trun is the first one
+func (f *Fragment) previousTrun(tfhd *TfhdBox, trun *TrunBox) *TrunBox {
+	for _, traf := range f.Moof.Trafs {
+		if traf.Tfhd != tfhd {
+			continue
+		}
+		for i, t := range traf.Truns {
+			if t == trun && i > 0 {
+				return traf.Truns[i-1]
+			}
+		}
+	}
+	return nil
+}
+
+// trunDataSize - size of trun sample data. If sizes are not in the trun, the default
+// sample size is taken from tfhd, or from trex if not nil, like in AddSampleDefaultValues.
+func trunDataSize(tfhd *TfhdBox, trex *TrexBox, trun *TrunBox) uint64 {
+	switch {
+	case trun.HasSampleSize():
+		return trun.SizeOfData()
+	case tfhd.HasDefaultSampleSize():
+		return uint64(trun.SampleCount()) * uint64(tfhd.DefaultSampleSize)
+	case trex != nil:
+		return uint64(trun.SampleCount()) * uint64(trex.DefaultSampleSize)
+	}
+	return trun.SizeOfData()
+}
+
 // AddFullSample - add a full sample to the first (and only) trun of a track
 // AddFullSampleToTrack is the more general function
 func (f *Fragment) AddFullSample(s FullSample) {
@@ -151,6 +564,16 @@ func (f *Fragment) AddFullSample(s FullSample) {
 	mdat.AddSampleData(s.Data)
 }
 
+// AddFullSampleChecked - like AddFullSample, but an error is returned and nothing is added
+// if the sample size differs from the length of the sample data.
+func (f *Fragment) AddFullSampleChecked(s FullSample) error {
+	if int(s.Size) != len(s.Data) {
+		return fmt.Errorf("sample size %d differs from data length %d", s.Size, len(s.Data))
+	}
+	f.AddFullSample(s)
+	return nil
+}
+
 // AddFullSampleToTrack - allows for adding samples to any track
 // New trun boxes will be created if latest trun of fragment is not in this track
 func (f *Fragment) AddFullSampleToTrack(s FullSample, trackID uint32) error {
@@ -192,23 +615,35 @@ func (f *Fragment) AddSamples(ss []Sample, baseMediaDecodeTime uint64) {
 	f.Mdat.lazyDataSize += accSize
 }
 
+// SetUniformSampleDuration - set dur as default_sample_duration in tfhd of all tracks
+// and remove per-sample durations from their truns.
+// The durations of the samples already in the truns are set to dur, so call this after adding samples.
+// TrafBox.OptimizeTfhdTrun() leaves the durations alone afterwards, since the truns have none.
+func (f *Fragment) SetUniformSampleDuration(dur uint32) {
+	for _, traf := range f.Moof.Trafs {
+		traf.Tfhd.Flags |= defaultSampleDurationPresent
+		traf.Tfhd.DefaultSampleDuration = dur
+		for _, trun := range traf.Truns {
+			trun.flags &= ^sampleDurationPresentFlag
+			for i := range trun.Samples {
+				trun.Samples[i].Dur = dur
+			}
+		}
+	}
+}
+
 // AddSampleToTrack - allows for adding samples to any track
 // New trun boxes will be created if latest trun of fragment is not in this track
 // baseMediaDecodeTime will be used only for first sample in a trun
 func (f *Fragment) AddSampleToTrack(s Sample, trackID uint32, baseMediaDecodeTime uint64) error {
-	var traf *TrafBox
-	for _, traf = range f.Moof.Trafs {
-		if traf.Tfhd.TrackID == trackID {
-			break
-		}
-	}
-	if traf == nil {
-		return fmt.Errorf("No track with trackID=%d", trackID)
+	traf, err := f.trafForTrackID(trackID)
+	if err != nil {
+		return err
 	}
 	if len(traf.Truns) == 0 { // Create first trun if needed
 		trun := CreateTrun(f.nextTrunNr)
 		f.nextTrunNr++
-		err := traf.AddChild(trun)
+		err = traf.AddChild(trun)
 		if err != nil {
 			return err
 		}
@@ -218,11 +653,13 @@ func (f *Fragment) AddSampleToTrack(s Sample, trackID uint32, baseMediaDecodeTim
 		tfdt.SetBaseMediaDecodeTime(baseMediaDecodeTime)
 	}
 	trun := traf.Truns[len(traf.Truns)-1] // latest of this track
-	if trun.writeOrderNr != f.nextTrunNr-1 {
-		// We are not in the latest trun. Must make a new one
+	newTrun := f.newTrunTrackIDs[trackID] && trun.SampleCount() > 0
+	delete(f.newTrunTrackIDs, trackID)
+	if trun.writeOrderNr != f.nextTrunNr-1 || newTrun {
+		// We are not in the latest trun, or a new one has been requested. Must make a new one
 		trun = CreateTrun(f.nextTrunNr)
 		f.nextTrunNr++
-		err := traf.AddChild(trun)
+		err = traf.AddChild(trun)
 		if err != nil {
 			return err
 		}
@@ -232,6 +669,34 @@ func (f *Fragment) AddSampleToTrack(s Sample, trackID uint32, baseMediaDecodeTim
 	return nil
 }
 
+// StartNewTrun - start a new trun in the track, so that the next sample added
+// to the track by AddSampleToTrack or AddFullSampleToTrack ends up in a new run.
+// The trun is created when that sample is added, so no empty trun is left
+// if no more samples are added to the track.
+func (f *Fragment) StartNewTrun(trackID uint32) error {
+	if _, err := f.trafForTrackID(trackID); err != nil {
+		return err
+	}
+	if f.newTrunTrackIDs == nil {
+		f.newTrunTrackIDs = make(map[uint32]bool)
+	}
+	f.newTrunTrackIDs[trackID] = true
+	return nil
+}
+
+// trafForTrackID - traf with trackID, or error if none
+func (f *Fragment) trafForTrackID(trackID uint32) (*TrafBox, error) {
+	if f.Moof == nil {
+		return nil, fmt.Errorf("moof not set in fragment")
+	}
+	for _, traf := range f.Moof.Trafs {
+		if traf.Tfhd.TrackID == trackID {
+			return traf, nil
+		}
+	}
+	return nil, fmt.Errorf("No track with trackID=%d", trackID)
+}
+
 // DumpSampleData - Get Sample data and print out
 func (f *Fragment) DumpSampleData(w io.Writer, trex *TrexBox) error {
 	samples, err := f.GetFullSamples(trex)
@@ -240,8 +705,8 @@ func (f *Fragment) DumpSampleData(w io.Writer, trex *TrexBox) error {
 	}
 	for i, s := range samples {
 		if i < 9 {
-			fmt.Printf("%4d %8d %8d %6x %d %d\n", i, s.DecodeTime, s.PresentationTime(),
-				s.Flags, s.Size, len(s.Data))
+			fmt.Printf("%4d %8d %8d %6x (sync=%t dependsOn=%d isDependedOn=%d) %d %d\n", i, s.DecodeTime,
+				s.PresentationTime(), s.Flags, s.IsSync(), s.DependsOn(), s.IsDependedOn(), s.Size, len(s.Data))
 		}
 		toAnnexB(s.Data)
 		if w != nil {
@@ -254,7 +719,8 @@ func (f *Fragment) DumpSampleData(w io.Writer, trex *TrexBox) error {
 	return nil
 }
 
-// Encode - write fragment via writer
+// Encode - write fragment via writer.
+// The output is deterministic, so encoding the same fragment again gives identical bytes.
 func (f *Fragment) Encode(w io.Writer) error {
 	if f.Moof == nil {
 		return fmt.Errorf("moof not set in fragment")
@@ -279,7 +745,45 @@ func (f *Fragment) Encode(w io.Writer) error {
 	return nil
 }
 
-// EncodeSW - write fragment via SliceWriter
+// EncodeWithStartPos - encode fragment to w and set moof and mdat start positions
+// given that the fragment starts at startPos. endPos is the position after the fragment,
+// so that it can be used as a running offset for the next fragment.
+func (f *Fragment) EncodeWithStartPos(w io.Writer, startPos uint64) (endPos uint64, err error) {
+	if f.Moof == nil {
+		return startPos, fmt.Errorf("moof not set in fragment")
+	}
+	moofPos := startPos
+	for _, c := range f.Children {
+		if c == f.Moof {
+			break
+		}
+		moofPos += c.Size()
+	}
+	err = f.Encode(w)
+	if err != nil {
+		return startPos, err
+	}
+	f.SetMoofStartPos(moofPos)
+	return startPos + f.Size(), nil
+}
+
+// SetMoofStartPos - set absolute position of moof box.
+// For a fragment built in memory, mdat is assumed to follow moof in the order of the children,
+// otherwise mdat is moved by the same amount as moof.
+func (f *Fragment) SetMoofStartPos(pos uint64) {
+	if f.Moof == nil {
+		return
+	}
+	if f.Mdat == nil || f.Mdat.StartPos == 0 || f.inMemoryPositions {
+		f.Moof.StartPos = pos
+		f.setInMemoryStartPositions()
+		return
+	}
+	f.Mdat.SetStartPos(f.Mdat.StartPos - f.Moof.StartPos + pos)
+	f.Moof.StartPos = pos
+}
+
+// EncodeSW - write fragment via SliceWriter. The output is identical to that of Encode.
 func (f *Fragment) EncodeSW(sw bits.SliceWriter) error {
 	if f.Moof == nil {
 		return fmt.Errorf("moof not set in fragment")
@@ -315,6 +819,65 @@ func (f *Fragment) Info(w io.Writer, specificBoxLevels, indent, indentStep strin
 	return nil
 }
 
+// DumpOptions - options for Fragment.InfoWithOptions
+type DumpOptions struct {
+	ResolveSampleValues bool     // Add the per-sample values resolved from trun, tfhd, and trex defaults
+	Trex                *TrexBox // Defaults for the track with the same trackID. May be nil
+}
+
+// InfoWithOptions - write box information like Info, and additional information given by opts.
+// With ResolveSampleValues, the effective decode time, duration, size, flags, and composition time offset
+// of every sample is written for each traf after the boxes. The fragment is not changed.
+func (f *Fragment) InfoWithOptions(w io.Writer, specificBoxLevels, indent, indentStep string, opts DumpOptions) error {
+	err := f.Info(w, specificBoxLevels, indent, indentStep)
+	if err != nil || !opts.ResolveSampleValues || f.Moof == nil {
+		return err
+	}
+	for _, traf := range f.Moof.Trafs {
+		var trex *TrexBox
+		if opts.Trex != nil && opts.Trex.TrackID == traf.Tfhd.TrackID {
+			trex = opts.Trex
+		}
+		_, err = fmt.Fprintf(w, "%sresolved samples for track %d\n", indent, traf.Tfhd.TrackID)
+		if err != nil {
+			return err
+		}
+		decTime := traf.Tfdt.BaseMediaDecodeTime
+		var nr int
+		for _, trun := range traf.Truns {
+			_, _, _, err = ResolveSampleDefaults(trun, traf.Tfhd, trex)
+			if err != nil {
+				return fmt.Errorf("track %d: %w", traf.Tfhd.TrackID, err)
+			}
+			filled, _ := trun.withDefaultValues(traf.Tfhd, trex)
+			for _, s := range filled.Samples {
+				nr++
+				_, err = fmt.Fprintf(w, "%s - sample[%d]: decodeTime=%d dur=%d size=%d flags=%08x (%s) compositionTimeOffset=%d\n",
+					indent+indentStep, nr, decTime, s.Dur, s.Size, s.Flags, DecodeSampleFlags(s.Flags), s.CompositionTimeOffset)
+				if err != nil {
+					return err
+				}
+				decTime += uint64(s.Dur)
+			}
+		}
+	}
+	return nil
+}
+
+// SetExplicitSampleValues - write duration, size, and flags explicitly in the truns of the track
+// given by trex (the first track if trex is nil). See TrafBox.SetExplicitSampleValues.
+// This is a debug mode, so EncOptimize should not include OptimizeTrun when encoding afterwards.
+func (f *Fragment) SetExplicitSampleValues(trex *TrexBox) error {
+	if f.Moof == nil {
+		return fmt.Errorf("moof not set in fragment")
+	}
+	traf := f.trafForTrex(trex)
+	if traf == nil {
+		return noTrafError(trex)
+	}
+	return traf.SetExplicitSampleValues(trex)
+}
+
 // GetChildren - return children boxes
 func (f *Fragment) GetChildren() []Box {
 	return f.Children
@@ -322,18 +885,78 @@ func (f *Fragment) GetChildren() []Box {
 
 // SetTrunDataOffsets - set DataOffset in trun depending on size and writeOrder
 func (f *Fragment) SetTrunDataOffsets() {
+	dataOffset := f.moofToMdatPayload()
+	for _, trun := range f.trunsInWriteOrder() {
+		trun.DataOffset = int32(dataOffset)
+		dataOffset += trun.SizeOfData()
+	}
+}
+
+// trunsInWriteOrder - all truns of the fragment in the order their sample data is written to mdat
+func (f *Fragment) trunsInWriteOrder() []*TrunBox {
 	var truns []*TrunBox
 	for _, traf := range f.Moof.Trafs {
 		truns = append(truns, traf.Truns...)
 	}
-	sort.Slice(truns, func(i, j int) bool {
+	sort.SliceStable(truns, func(i, j int) bool {
 		return truns[i].writeOrderNr < truns[j].writeOrderNr
 	})
-	dataOffset := f.Moof.Size() + f.Mdat.HeaderSize()
-	for _, trun := range truns {
-		trun.DataOffset = int32(dataOffset)
-		dataOffset += trun.SizeOfData()
+	return truns
+}
+
+// inMemoryLayout - true for a fragment built in memory, where the mdat position is not known
+// or derived, so that mdat is assumed to follow moof and sample data is found via the trun write order
+func (f *Fragment) inMemoryLayout() bool {
+	return f.Mdat.StartPos == 0 || f.inMemoryPositions
+}
+
+// mdatPayloadStart - absolute position of the mdat payload, also for a fragment built in memory
+func (f *Fragment) mdatPayloadStart() uint64 {
+	if f.inMemoryLayout() {
+		return f.Moof.StartPos + f.moofToMdatPayload()
 	}
+	return f.Mdat.PayloadAbsoluteOffset()
+}
+
+// moofToMdatPayload - distance from start of moof to start of mdat payload.
+// Boxes between moof and mdat, like a misplaced prft, are included.
+func (f *Fragment) moofToMdatPayload() uint64 {
+	var dist uint64
+	inside := false
+	for _, c := range f.Children {
+		if c == f.Moof {
+			inside = true
+		}
+		if c == f.Mdat {
+			break
+		}
+		if inside {
+			dist += c.Size()
+		}
+	}
+	if !inside { // moof not among children
+		dist = f.Moof.Size()
+	}
+	return dist + f.Mdat.HeaderSize()
+}
+
+// ReorderTopLevel - put styp and prft boxes first, in that order, followed by
+// the other boxes in their original order. This normalizes a prft placed after moof.
+func (f *Fragment) ReorderTopLevel() {
+	children := make([]Box, 0, len(f.Children))
+	for _, boxType := range []string{"styp", "prft"} {
+		for _, c := range f.Children {
+			if c.Type() == boxType {
+				children = append(children, c)
+			}
+		}
+	}
+	for _, c := range f.Children {
+		if t := c.Type(); t != "styp" && t != "prft" {
+			children = append(children, c)
+		}
+	}
+	f.Children = children
 }
 
 // GetSampleNrFromTime - look up sample number from a specified time. Return error if no matching time
@@ -357,7 +980,8 @@ func (f *Fragment) GetSampleNrFromTime(trex *TrexBox, sampleTime uint64) (uint32
 	return traf.Trun.GetSampleNrForRelativeTime(deltaTime, defaultSampleDuration)
 }
 
-// GetSampleInterval - get SampleInterval for a fragment with only one track
+// GetSampleInterval - get SampleInterval for a fragment with only one track.
+// The fragment is not changed.
 func (f *Fragment) GetSampleInterval(trex *TrexBox, startSampleNr, endSampleNr uint32) (SampleInterval, error) {
 	moof := f.Moof
 	if len(moof.Trafs) != 1 {
@@ -368,19 +992,12 @@ func (f *Fragment) GetSampleInterval(trex *TrexBox, startSampleNr, endSampleNr u
 		return SampleInterval{}, fmt.Errorf("Not exactly 1, but %d trun boxes", len(traf.Truns))
 	}
 	tfhd, trun := traf.Tfhd, traf.Trun
-	moofStartPos := moof.StartPos
-	_ = trun.AddSampleDefaultValues(tfhd, trex)
-	var baseOffset uint64
-	if tfhd.HasBaseDataOffset() {
-		baseOffset = tfhd.BaseDataOffset
-	} else if tfhd.DefaultBaseIfMoof() {
-		baseOffset = moofStartPos
-	}
-	if trun.HasDataOffset() {
-		baseOffset = uint64(int64(trun.DataOffset) + int64(baseOffset))
+	offsetInMdat, err := f.trunOffsetInMdat(tfhd, trex, trun)
+	if err != nil {
+		return SampleInterval{}, err
 	}
-	offsetInMdat := uint32(baseOffset - f.Mdat.PayloadAbsoluteOffset())
-	return trun.GetSampleInterval(startSampleNr, endSampleNr, traf.Tfdt.BaseMediaDecodeTime, f.Mdat, offsetInMdat)
+	filled, _ := trun.withDefaultValues(tfhd, trex)
+	return filled.GetSampleInterval(startSampleNr, endSampleNr, traf.Tfdt.BaseMediaDecodeTime, f.Mdat, offsetInMdat)
 }
 
 // AddSampleInterval - add SampleInterval for a fragment with only one track
@@ -395,3 +1012,642 @@ func (f *Fragment) AddSampleInterval(sItvl SampleInterval) error {
 	f.Mdat.AddSampleDataPart(sItvl.Data)
 	return nil
 }
+
+// MergeFragments - merge single-track fragments into one multi-track fragment.
+// All fragments must have the same sequence number.
+// The trafs of the input fragments are copied to the new fragment, and the
+// sample data of all tracks is gathered in one mdat with trun data offsets updated.
+// The input fragments are not changed.
+// Sample sizes must be available in trun or tfhd, since trex is not known here.
+func MergeFragments(frags []*Fragment) (*Fragment, error) {
+	if len(frags) == 0 {
+		return nil, fmt.Errorf("no fragments to merge")
+	}
+	for i, frag := range frags {
+		if frag.Moof == nil || frag.Mdat == nil {
+			return nil, fmt.Errorf("fragment %d lacks moof or mdat", i)
+		}
+		if frag.Mdat.IsLazy() {
+			return nil, fmt.Errorf("fragment %d has lazy mdat", i)
+		}
+	}
+	seqNr := frags[0].Moof.Mfhd.SequenceNumber
+	for i, frag := range frags {
+		if frag.Moof.Mfhd.SequenceNumber != seqNr {
+			return nil, fmt.Errorf("sequence number %d of fragment %d differs from %d",
+				frag.Moof.Mfhd.SequenceNumber, i, seqNr)
+		}
+	}
+
+	out := NewFragment()
+	moof := &MoofBox{}
+	out.AddChild(moof)
+	_ = moof.AddChild(CreateMfhd(seqNr))
+	mdat := &MdatBox{}
+	out.AddChild(mdat)
+
+	for i, frag := range frags {
+		for _, inTraf := range frag.Moof.Trafs {
+			traf, err := inTraf.copy()
+			if err != nil {
+				return nil, fmt.Errorf("fragment %d: %w", i, err)
+			}
+			tfhd := traf.Tfhd
+			for j, trun := range traf.Truns {
+				data, err := frag.trunData(inTraf.Tfhd, inTraf.Truns[j])
+				if err != nil {
+					return nil, fmt.Errorf("fragment %d: %w", i, err)
+				}
+				trun.AddSampleDefaultValues(tfhd, nil) // Sample sizes are needed for the data offsets
+				mdat.AddSampleData(data)
+				trun.flags |= dataOffsetPresentFlag
+				trun.writeOrderNr = out.nextTrunNr
+				out.nextTrunNr++
+			}
+			tfhd.Flags &= ^baseDataOffsetPresent
+			tfhd.Flags |= defaultBaseIsMoof
+			tfhd.BaseDataOffset = 0
+			_ = moof.AddChild(traf)
+		}
+	}
+	out.SetTrunDataOffsets()
+	return out, nil
+}
+
+// setInMemoryStartPositions - set start positions for a fragment built in memory,
+// where sample data is laid out in trun write order, so that trun data can be found in mdat.
+// The moof start position is kept, and mdat is assumed to follow directly after moof.
+// Once set, the positions are recalculated at each call, since samples may have been added.
+func (f *Fragment) setInMemoryStartPositions() {
+	if f.Moof == nil || f.Mdat == nil || (f.Mdat.StartPos != 0 && !f.inMemoryPositions) {
+		return
+	}
+	f.SetTrunDataOffsets()
+	f.Mdat.SetStartPos(f.Moof.StartPos + f.moofToMdatPayload() - f.Mdat.HeaderSize())
+	f.inMemoryPositions = true
+}
+
+// trunData - sample data of trun in the mdat payload.
+// Sample sizes must be in trun or as tfhd default, since trex is not known here.
+func (f *Fragment) trunData(tfhd *TfhdBox, trun *TrunBox) ([]byte, error) {
+	if !trun.HasSampleSize() && !tfhd.HasDefaultSampleSize() && trun.SampleCount() > 0 {
+		return nil, fmt.Errorf("no sample size in trun or tfhd for track %d", tfhd.TrackID)
+	}
+	offsetInMdat, err := f.trunOffsetInMdat(tfhd, nil, trun)
+	if err != nil {
+		return nil, err
+	}
+	end := offsetInMdat + trunDataSize(tfhd, nil, trun)
+	if end > uint64(len(f.Mdat.Data)) {
+		return nil, fmt.Errorf("trun data for track %d beyond end of mdat", tfhd.TrackID)
+	}
+	return f.Mdat.Data[offsetInMdat:end], nil
+}
+
+// RemoveTrack - remove the traf of trackID and its sample data from a multi-track fragment.
+// The sample data of the remaining tracks is kept in one mdat with trun data offsets updated.
+// Use MoovBox.RemoveTrack() to remove the track from the init segment.
+func (f *Fragment) RemoveTrack(trackID uint32) error {
+	if f.Moof == nil || f.Mdat == nil {
+		return fmt.Errorf("fragment lacks moof or mdat")
+	}
+	if f.Mdat.IsLazy() {
+		return fmt.Errorf("fragment has lazy mdat")
+	}
+	found := false
+	for _, traf := range f.Moof.Trafs {
+		if traf.Tfhd.TrackID == trackID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("no traf for trackID %d", trackID)
+	}
+	if len(f.Moof.Trafs) == 1 {
+		return fmt.Errorf("cannot remove the only track %d", trackID)
+	}
+	f.setInMemoryStartPositions()
+	var trafs []*TrafBox
+	for _, traf := range f.Moof.Trafs {
+		if traf.Tfhd.TrackID != trackID {
+			trafs = append(trafs, traf)
+		}
+	}
+	err := f.repackSampleData(trafs)
+	if err != nil {
+		return err
+	}
+	children := make([]Box, 0, len(f.Moof.Children)-1)
+	for _, c := range f.Moof.Children {
+		if traf, ok := c.(*TrafBox); ok && traf.Tfhd.TrackID == trackID {
+			continue
+		}
+		children = append(children, c)
+	}
+	f.Moof.Children = children
+	f.Moof.Trafs = trafs
+	f.Moof.Traf = trafs[0]
+	f.SetTrunDataOffsets()
+	f.Mdat.SetStartPos(f.Moof.StartPos + f.moofToMdatPayload() - f.Mdat.HeaderSize())
+	return nil
+}
+
+// TrimSamples - remove samples with presentation time before startTrim or at or after endTrim
+// (in the media timescale) from a single-track fragment with sample data in memory.
+// The remaining samples are put in one trun with explicit sample values, the tfdt is set to
+// their first decode time, and the mdat only keeps their data.
+// An error is returned and the fragment is left unchanged if the remaining samples are not
+// consecutive in decode order, if the first of them is not a sync sample, or if the traf has
+// per-sample boxes like senc, saiz, subs, or sbgp.
+func (f *Fragment) TrimSamples(startTrim, endTrim uint64) error {
+	if f.Moof == nil || f.Mdat == nil {
+		return fmt.Errorf("fragment lacks moof or mdat")
+	}
+	if f.Mdat.IsLazy() {
+		return fmt.Errorf("fragment has lazy mdat")
+	}
+	if len(f.Moof.Trafs) != 1 {
+		return fmt.Errorf("trimming needs exactly one traf, not %d", len(f.Moof.Trafs))
+	}
+	traf := f.Moof.Traf
+	if traf.Tfdt == nil {
+		return fmt.Errorf("traf lacks tfdt")
+	}
+	if traf.Senc != nil || traf.Saiz != nil || traf.Subs != nil || traf.Sbgp != nil {
+		return fmt.Errorf("cannot trim traf with per-sample boxes")
+	}
+	samples, err := f.GetFullSamples(nil)
+	if err != nil {
+		return err
+	}
+	first, end := -1, -1
+	for i := range samples {
+		pt := samples[i].PresentationTime()
+		if pt < startTrim || pt >= endTrim {
+			if first >= 0 && end < 0 {
+				end = i
+			}
+			continue
+		}
+		if end >= 0 {
+			return fmt.Errorf("sample %d kept after removed sample %d", i+1, end+1)
+		}
+		if first < 0 {
+			first = i
+		}
+	}
+	if first < 0 {
+		return fmt.Errorf("no samples in [%d, %d)", startTrim, endTrim)
+	}
+	if end < 0 {
+		end = len(samples)
+	}
+	kept := samples[first:end]
+	if !kept[0].IsSync() {
+		return fmt.Errorf("first kept sample %d is not a sync sample", first+1)
+	}
+	trun := CreateTrun(0)
+	data := make([]byte, 0, len(f.Mdat.Data))
+	for _, s := range kept {
+		trun.AddSample(s.Sample)
+		data = append(data, s.Data...)
+	}
+	children := make([]Box, 0, len(traf.Children))
+	for _, c := range traf.Children {
+		if c.Type() != "trun" {
+			children = append(children, c)
+		}
+	}
+	traf.Children = children
+	traf.Trun = nil
+	traf.Truns = nil
+	_ = traf.AddChild(trun)
+	traf.Tfdt.SetBaseMediaDecodeTime(kept[0].DecodeTime)
+	tfhd := traf.Tfhd
+	tfhd.Flags &= ^baseDataOffsetPresent
+	tfhd.Flags |= defaultBaseIsMoof
+	tfhd.BaseDataOffset = 0
+	f.Mdat.Data = data
+	f.nextTrunNr = 1
+	f.SetTrunDataOffsets()
+	f.Mdat.SetStartPos(f.Moof.StartPos + f.moofToMdatPayload() - f.Mdat.HeaderSize())
+	return nil
+}
+
+// InterleaveMode - order of the sample data of different tracks in mdat, see InterleaveTracks
+type InterleaveMode byte
+
+const (
+	// InterleaveByTrack - all sample data of a track before that of the next track, in traf order
+	InterleaveByTrack InterleaveMode = iota
+	// InterleaveByDecodeTime - chunks of samples from all tracks ordered by decode time
+	InterleaveByDecodeTime
+)
+
+// InterleaveTracks - rewrite the truns and the mdat of a fragment with sample data in memory,
+// so that the sample data is laid out according to mode.
+// For InterleaveByDecodeTime, every track is split into chunks of consecutive samples with a duration
+// of at least chunkDur seconds (one sample per chunk if chunkDur is 0), and the chunks are ordered by
+// decode time in seconds, with traf order breaking ties. timescales maps trackID to track timescale
+// and is only needed for that mode. Every chunk gets a trun with explicit sample values, so sample values
+// must be in the truns or in the tfhd defaults. The new layout is checked by Validate, and the
+// fragment is only changed if that succeeds.
+func (f *Fragment) InterleaveTracks(mode InterleaveMode, chunkDur float64, timescales map[uint32]uint32) error {
+	if f.Moof == nil || f.Mdat == nil {
+		return fmt.Errorf("fragment lacks moof or mdat")
+	}
+	if f.Mdat.IsLazy() {
+		return fmt.Errorf("fragment has lazy mdat")
+	}
+	type chunk struct {
+		trafNr  int
+		start   float64
+		samples []FullSample
+	}
+	var chunks []chunk
+	for trafNr, traf := range f.Moof.Trafs {
+		tfhd := traf.Tfhd
+		if traf.Senc != nil || traf.Saiz != nil {
+			return fmt.Errorf("track %d: cannot interleave encrypted samples", tfhd.TrackID)
+		}
+		var timescale uint32
+		if mode == InterleaveByDecodeTime {
+			timescale = timescales[tfhd.TrackID]
+			if timescale == 0 {
+				return fmt.Errorf("track %d: no timescale", tfhd.TrackID)
+			}
+		}
+		var decodeTime uint64
+		if traf.Tfdt != nil {
+			decodeTime = traf.Tfdt.BaseMediaDecodeTime
+		}
+		var samples []FullSample
+		for _, trun := range traf.Truns {
+			data, err := f.trunData(tfhd, trun)
+			if err != nil {
+				return err
+			}
+			filled, _ := trun.withDefaultValues(tfhd, nil)
+			var offset uint32
+			for _, s := range filled.Samples {
+				samples = append(samples, FullSample{Sample: s, DecodeTime: decodeTime, Data: data[offset : offset+s.Size]})
+				decodeTime += uint64(s.Dur)
+				offset += s.Size
+			}
+		}
+		switch mode {
+		case InterleaveByTrack:
+			chunks = append(chunks, chunk{trafNr: trafNr, samples: samples})
+		case InterleaveByDecodeTime:
+			for len(samples) > 0 {
+				end := 1
+				for end < len(samples) &&
+					float64(samples[end].DecodeTime-samples[0].DecodeTime) < chunkDur*float64(timescale) {
+					end++
+				}
+				start := float64(samples[0].DecodeTime) / float64(timescale)
+				chunks = append(chunks, chunk{trafNr: trafNr, start: start, samples: samples[:end]})
+				samples = samples[end:]
+			}
+		default:
+			return fmt.Errorf("unknown interleave mode %d", mode)
+		}
+	}
+	sort.SliceStable(chunks, func(i, j int) bool {
+		if chunks[i].start != chunks[j].start {
+			return chunks[i].start < chunks[j].start
+		}
+		return chunks[i].trafNr < chunks[j].trafNr
+	})
+	// Build the new layout with new trafs, moof, and mdat, sharing all unchanged children,
+	// and validate it before the fragment is changed.
+	newTrafs := make([]*TrafBox, 0, len(f.Moof.Trafs))
+	for _, traf := range f.Moof.Trafs {
+		newTraf := &TrafBox{}
+		for _, c := range traf.Children {
+			switch box := c.(type) {
+			case *TrunBox:
+				continue
+			case *TfhdBox:
+				tfhd := *box
+				tfhd.Flags &= ^baseDataOffsetPresent
+				tfhd.Flags |= defaultBaseIsMoof
+				tfhd.BaseDataOffset = 0
+				_ = newTraf.AddChild(&tfhd)
+			default:
+				_ = newTraf.AddChild(c)
+			}
+		}
+		newTrafs = append(newTrafs, newTraf)
+	}
+	data := make([]byte, 0, len(f.Mdat.Data))
+	for i, c := range chunks {
+		trun := CreateTrun(uint32(i))
+		for _, s := range c.samples {
+			trun.AddSample(s.Sample)
+			data = append(data, s.Data...)
+		}
+		_ = newTrafs[c.trafNr].AddChild(trun)
+	}
+	newMoof := &MoofBox{StartPos: f.Moof.StartPos}
+	trafNr := 0
+	for _, c := range f.Moof.Children {
+		if _, ok := c.(*TrafBox); ok {
+			c = newTrafs[trafNr]
+			trafNr++
+		}
+		_ = newMoof.AddChild(c)
+	}
+	newMdat := &MdatBox{Data: data, LargeSize: f.Mdat.LargeSize}
+	newFrag := &Fragment{Moof: newMoof, Mdat: newMdat}
+	for _, c := range f.Children {
+		switch c {
+		case f.Moof:
+			c = newMoof
+		case f.Mdat:
+			c = newMdat
+		}
+		newFrag.Children = append(newFrag.Children, c)
+	}
+	newFrag.SetTrunDataOffsets()
+	newMdat.SetStartPos(newMoof.StartPos + newFrag.moofToMdatPayload() - newMdat.HeaderSize())
+	if err := newFrag.Validate(); err != nil {
+		return err
+	}
+	for i, traf := range f.Moof.Trafs {
+		*traf.Tfhd = *newTrafs[i].Tfhd
+		children := make([]Box, 0, len(traf.Children))
+		for _, c := range traf.Children {
+			if c.Type() != "trun" {
+				children = append(children, c)
+			}
+		}
+		traf.Children = children
+		traf.Trun = nil
+		traf.Truns = nil
+		for _, trun := range newTrafs[i].Truns {
+			_ = traf.AddChild(trun)
+		}
+	}
+	f.Mdat.Data = data
+	f.Mdat.SetStartPos(newMdat.StartPos)
+	f.nextTrunNr = uint32(len(chunks))
+	return nil
+}
+
+// repackSampleData - replace the mdat data by the sample data of the truns of trafs in order.
+// The truns get data offsets relative to moof in the same order, but the offset values
+// are set first by SetTrunDataOffsets. The sample data must be in memory.
+func (f *Fragment) repackSampleData(trafs []*TrafBox) error {
+	newData, err := f.trafsSampleData(trafs)
+	if err != nil {
+		return err
+	}
+	f.setRepackedSampleData(trafs, newData)
+	return nil
+}
+
+// trafsSampleData - the sample data of the truns of trafs in order. The sample data must be in memory.
+func (f *Fragment) trafsSampleData(trafs []*TrafBox) ([]byte, error) {
+	if f.Mdat.IsLazy() {
+		return nil, fmt.Errorf("no sample data in lazy mdat")
+	}
+	var newData []byte
+	for _, traf := range trafs {
+		for _, trun := range traf.Truns {
+			data, err := f.trunData(traf.Tfhd, trun)
+			if err != nil {
+				return nil, err
+			}
+			newData = append(newData, data...)
+		}
+	}
+	return newData, nil
+}
+
+// setRepackedSampleData - set newData from trafsSampleData as mdat data, and the truns of trafs
+// to have data offsets relative to moof in the same order.
+// This is done after all data is collected, since truns without data offset depend on previous truns.
+func (f *Fragment) setRepackedSampleData(trafs []*TrafBox, newData []byte) {
+	var writeOrderNr uint32
+	for _, traf := range trafs {
+		for _, trun := range traf.Truns {
+			trun.flags |= dataOffsetPresentFlag
+			trun.writeOrderNr = writeOrderNr
+			writeOrderNr++
+		}
+		tfhd := traf.Tfhd
+		tfhd.Flags &= ^baseDataOffsetPresent
+		tfhd.Flags |= defaultBaseIsMoof
+		tfhd.BaseDataOffset = 0
+	}
+	f.Mdat.Data = newData
+	f.nextTrunNr = writeOrderNr
+}
+
+// IsLastChunk - true if the fragment has a styp box with the lmsg brand,
+// which marks the last chunk of a low-latency CMAF stream
+func (f *Fragment) IsLastChunk() bool {
+	return f.Styp != nil && f.Styp.HasBrand("lmsg")
+}
+
+// GroupChunksIntoSegments - group a stream of low-latency CMAF chunks into segments.
+// A chunk with a styp box starts a new segment, and a last chunk (see IsLastChunk) ends the current segment.
+func GroupChunksIntoSegments(chunks []*Fragment) [][]*Fragment {
+	var segments [][]*Fragment
+	var current []*Fragment
+	for _, chunk := range chunks {
+		if chunk.Styp != nil && !chunk.IsLastChunk() && len(current) > 0 {
+			segments = append(segments, current)
+			current = nil
+		}
+		current = append(current, chunk)
+		if chunk.IsLastChunk() {
+			segments = append(segments, current)
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		segments = append(segments, current)
+	}
+	return segments
+}
+
+// ComputeBandwidth - bitrate in bits per second for DASH @bandwidth.
+// It is computed as total mdat payload bytes * 8 divided by the total duration of the
+// samples of the track given by trex (the first track if trex is nil).
+// timescale is the track timescale. An error is returned if the total duration is zero.
+func ComputeBandwidth(frags []*Fragment, trex *TrexBox, timescale uint32) (uint64, error) {
+	if timescale == 0 {
+		return 0, errors.New("timescale is zero")
+	}
+	var totalBytes, totalDur uint64
+	for i, f := range frags {
+		traf := f.trafForTrex(trex)
+		if traf == nil {
+			return 0, fmt.Errorf("no matching traf in fragment %d", i)
+		}
+		var defaultDur uint32
+		switch {
+		case traf.Tfhd.HasDefaultSampleDuration():
+			defaultDur = traf.Tfhd.DefaultSampleDuration
+		case trex != nil:
+			defaultDur = trex.DefaultSampleDuration
+		}
+		for _, trun := range traf.Truns {
+			totalDur += trun.Duration(defaultDur)
+		}
+		if f.Mdat != nil {
+			totalBytes += f.Mdat.payloadSize()
+		}
+	}
+	if totalDur == 0 {
+		return 0, errors.New("total duration is zero")
+	}
+	nrBits := totalBytes * 8
+	if nrBits > math.MaxUint64/uint64(timescale) {
+		return 0, errors.New("too many bytes to compute bandwidth")
+	}
+	return nrBits * uint64(timescale) / totalDur, nil
+}
+
+// RetimeSubtitles - multiply tfdt base media decode times and sample durations by factor,
+// e.g. when converting subtitles between frame rates.
+// All sample start times are scaled from the original timeline and rounded, and the
+// durations are the differences of the rounded times, so the rounding errors do not accumulate.
+// Sample durations are written explicitly in the truns, and the trun data offsets are
+// updated if the moof size changes. All fragments are checked and retimed before any of them is changed.
+func RetimeSubtitles(frags []*Fragment, factor float64) error {
+	if factor <= 0 {
+		return fmt.Errorf("non-positive factor %f", factor)
+	}
+	scale := func(t uint64) uint64 {
+		return uint64(math.Round(float64(t) * factor))
+	}
+	type retimedTraf struct {
+		baseTime   uint64
+		defaultDur uint32
+		durs       [][]uint32 // New sample durations per trun
+	}
+	var retimed []retimedTraf
+	for _, f := range frags {
+		if f.Moof == nil {
+			return fmt.Errorf("fragment lacks moof")
+		}
+		for _, traf := range f.Moof.Trafs {
+			tfhd := traf.Tfhd
+			if traf.Tfdt == nil {
+				return fmt.Errorf("no tfdt for track %d", tfhd.TrackID)
+			}
+			startTime := traf.Tfdt.BaseMediaDecodeTime
+			newStartTime := scale(startTime)
+			rt := retimedTraf{baseTime: newStartTime}
+			if tfhd.HasDefaultSampleDuration() {
+				newDefaultDur := scale(uint64(tfhd.DefaultSampleDuration))
+				if newDefaultDur > math.MaxUint32 {
+					return fmt.Errorf("scaled default sample duration %d too big for track %d", newDefaultDur, tfhd.TrackID)
+				}
+				rt.defaultDur = uint32(newDefaultDur)
+			}
+			for _, trun := range traf.Truns {
+				if !trun.HasSampleDuration() && !tfhd.HasDefaultSampleDuration() && trun.SampleCount() > 0 {
+					return fmt.Errorf("no sample duration in trun or tfhd for track %d", tfhd.TrackID)
+				}
+				filled, _ := trun.withDefaultValues(tfhd, nil)
+				durs := make([]uint32, len(filled.Samples))
+				for i := range filled.Samples {
+					endTime := startTime + uint64(filled.Samples[i].Dur)
+					newEndTime := scale(endTime)
+					newDur := newEndTime - newStartTime
+					if newDur > math.MaxUint32 {
+						return fmt.Errorf("scaled sample duration %d too big for track %d", newDur, tfhd.TrackID)
+					}
+					durs[i] = uint32(newDur)
+					startTime, newStartTime = endTime, newEndTime
+				}
+				rt.durs = append(rt.durs, durs)
+			}
+			retimed = append(retimed, rt)
+		}
+	}
+	trafNr := 0
+	for _, f := range frags {
+		oldMoofSize := f.Moof.Size()
+		for _, traf := range f.Moof.Trafs {
+			rt := retimed[trafNr]
+			trafNr++
+			traf.Tfdt.SetBaseMediaDecodeTime(rt.baseTime)
+			for j, trun := range traf.Truns {
+				trun.AddSampleDefaultValues(traf.Tfhd, nil)
+				for i := range trun.Samples {
+					trun.Samples[i].Dur = rt.durs[j][i]
+				}
+				trun.flags |= sampleDurationPresentFlag
+			}
+			if traf.Tfhd.HasDefaultSampleDuration() {
+				traf.Tfhd.DefaultSampleDuration = rt.defaultDur
+			}
+		}
+		sizeDiff := int64(f.Moof.Size()) - int64(oldMoofSize)
+		if sizeDiff != 0 {
+			for _, traf := range f.Moof.Trafs {
+				for _, trun := range traf.Truns {
+					if trun.HasDataOffset() {
+						trun.DataOffset += int32(sizeDiff)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// TimeGap - time interval [Start, End) in track timescale
+type TimeGap struct {
+	Start uint64
+	End   uint64
+}
+
+// FindSubtitleGaps - find intervals in the timeline of the track given by trex (the first track if trex is nil)
+// that are not covered by any sample. Samples with zero size are also treated as gaps, since they
+// carry neither a cue nor an explicit empty vtte box.
+// The fragments must be in decode order. Only sample metadata is used, so lazy mdat is fine,
+// and the fragments are not changed.
+func FindSubtitleGaps(frags []*Fragment, trex *TrexBox) []TimeGap {
+	var gaps []TimeGap
+	addGap := func(start, end uint64) {
+		if n := len(gaps); n > 0 && gaps[n-1].End == start {
+			gaps[n-1].End = end
+			return
+		}
+		gaps = append(gaps, TimeGap{start, end})
+	}
+	var endTime uint64
+	started := false
+	for _, f := range frags {
+		if f.Moof == nil {
+			continue
+		}
+		traf := f.trafForTrex(trex)
+		if traf == nil {
+			continue
+		}
+		decTime := traf.Tfdt.BaseMediaDecodeTime
+		for _, trun := range traf.Truns {
+			filled, _ := trun.withDefaultValues(traf.Tfhd, trex)
+			for _, s := range filled.Samples {
+				if started && decTime > endTime {
+					addGap(endTime, decTime)
+				}
+				if s.Size == 0 && s.Dur > 0 {
+					addGap(decTime, decTime+uint64(s.Dur))
+				}
+				decTime += uint64(s.Dur)
+				endTime = decTime
+				started = true
+			}
+		}
+	}
+	return gaps
+}
@@ -0,0 +1,1639 @@
+package mp4
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/edgeware/mp4ff/bits"
+	"github.com/go-test/deep"
+)
+
+// TestFragmentLargeSizeMdat - sample data should be found also when mdat has 16-byte header
+func TestFragmentLargeSizeMdat(t *testing.T) {
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Error(err)
+	}
+	baseTime := uint64(1 << 33) // Needs 64-bit tfdt
+	inSamples := []FullSample{
+		{Sample: Sample{SyncSampleFlags, 1000, 3, 0}, DecodeTime: baseTime, Data: []byte{1, 2, 3}},
+		{Sample: Sample{NonSyncSampleFlags, 1000, 2, 0}, DecodeTime: baseTime + 1000, Data: []byte{4, 5}},
+	}
+	for _, s := range inSamples {
+		frag.AddFullSample(s)
+	}
+	frag.Mdat.LargeSize = true
+	buf := bytes.Buffer{}
+	err = frag.Encode(&buf)
+	if err != nil {
+		t.Error(err)
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Error(err)
+	}
+	decFrag := f.Segments[0].Fragments[0]
+	if decFrag.Mdat.HeaderSize() != 16 {
+		t.Errorf("mdat header size %d instead of 16", decFrag.Mdat.HeaderSize())
+	}
+	if decFrag.Moof.Traf.Tfdt.Version != 1 {
+		t.Errorf("tfdt version %d instead of 1", decFrag.Moof.Traf.Tfdt.Version)
+	}
+	expectedPayloadOffset := decFrag.Moof.Size() + 16
+	if decFrag.Mdat.PayloadAbsoluteOffset() != expectedPayloadOffset {
+		t.Errorf("payload offset %d instead of %d", decFrag.Mdat.PayloadAbsoluteOffset(), expectedPayloadOffset)
+	}
+	gotSamples, err := decFrag.GetFullSamples(nil)
+	if err != nil {
+		t.Error(err)
+	}
+	if diff := deep.Equal(gotSamples, inSamples); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestMergeFragments(t *testing.T) {
+	videoFrag, err := CreateFragment(7, 1)
+	if err != nil {
+		t.Error(err)
+	}
+	videoSamples := []FullSample{
+		{Sample: Sample{SyncSampleFlags, 3000, 4, 0}, DecodeTime: 90000, Data: []byte{1, 2, 3, 4}},
+		{Sample: Sample{NonSyncSampleFlags, 3000, 3, 0}, DecodeTime: 93000, Data: []byte{5, 6, 7}},
+	}
+	for _, s := range videoSamples {
+		videoFrag.AddFullSample(s)
+	}
+	audioFrag, err := CreateFragment(7, 2)
+	if err != nil {
+		t.Error(err)
+	}
+	audioSamples := []FullSample{
+		{Sample: Sample{SyncSampleFlags, 1024, 2, 0}, DecodeTime: 48000, Data: []byte{8, 9}},
+		{Sample: Sample{SyncSampleFlags, 1024, 2, 0}, DecodeTime: 49024, Data: []byte{10, 11}},
+	}
+	for _, s := range audioSamples {
+		audioFrag.AddFullSample(s)
+	}
+	// Encode and decode audio fragment to also test a fragment with file positions
+	buf := bytes.Buffer{}
+	err = audioFrag.Encode(&buf)
+	if err != nil {
+		t.Error(err)
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Error(err)
+	}
+	decAudioFrag := f.Segments[0].Fragments[0]
+	audioTfhdFlags := decAudioFrag.Moof.Traf.Tfhd.Flags
+	audioDataOffset := decAudioFrag.Moof.Traf.Trun.DataOffset
+
+	merged, err := MergeFragments([]*Fragment{videoFrag, decAudioFrag})
+	if err != nil {
+		t.Error(err)
+	}
+	// The input fragments should not be changed
+	if merged.Moof.Trafs[0] == videoFrag.Moof.Traf || merged.Moof.Trafs[1] == decAudioFrag.Moof.Traf {
+		t.Error("merged fragment shares traf with input fragment")
+	}
+	if decAudioFrag.Moof.Traf.Tfhd.Flags != audioTfhdFlags || decAudioFrag.Moof.Traf.Trun.DataOffset != audioDataOffset {
+		t.Error("audio fragment was changed by merge")
+	}
+	if videoFrag.Moof.Traf.Trun.DataOffset != 0 || videoFrag.Mdat.StartPos != 0 {
+		t.Error("video fragment was changed by merge")
+	}
+	for _, frag := range []*Fragment{videoFrag, decAudioFrag} {
+		got, err := frag.GetFullSamples(nil)
+		if err != nil {
+			t.Error(err)
+		}
+		if len(got) != 2 {
+			t.Errorf("got %d samples from input fragment after merge", len(got))
+		}
+	}
+	if len(merged.Moof.Trafs) != 2 {
+		t.Errorf("merged fragment has %d trafs instead of 2", len(merged.Moof.Trafs))
+	}
+	buf.Reset()
+	err = merged.Encode(&buf)
+	if err != nil {
+		t.Error(err)
+	}
+	f, err = DecodeFile(&buf)
+	if err != nil {
+		t.Error(err)
+	}
+	decMerged := f.Segments[0].Fragments[0]
+	gotVideo, err := decMerged.GetFullSamples(CreateTrex(1))
+	if err != nil {
+		t.Error(err)
+	}
+	if diff := deep.Equal(gotVideo, videoSamples); diff != nil {
+		t.Errorf("video: %v", diff)
+	}
+	gotAudio, err := decMerged.GetFullSamples(CreateTrex(2))
+	if err != nil {
+		t.Error(err)
+	}
+	if diff := deep.Equal(gotAudio, audioSamples); diff != nil {
+		t.Errorf("audio: %v", diff)
+	}
+
+	otherFrag, _ := CreateFragment(8, 3)
+	_, err = MergeFragments([]*Fragment{videoFrag, otherFrag})
+	if err == nil {
+		t.Error("no error for different sequence numbers")
+	}
+}
+
+func TestSyncSamples(t *testing.T) {
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Error(err)
+	}
+	flags := []uint32{SyncSampleFlags, NonSyncSampleFlags, NonSyncSampleFlags, SyncSampleFlags}
+	for i, fl := range flags {
+		frag.AddSample(Sample{fl, 1000, 10, 0}, uint64(i*1000))
+	}
+	gotSync, err := frag.SyncSamples(nil)
+	if err != nil {
+		t.Error(err)
+	}
+	if diff := deep.Equal(gotSync, []uint32{0, 3}); diff != nil {
+		t.Error(diff)
+	}
+	// Flags in tfhd after optimization
+	frag.Moof.Traf.Trun.Samples[3].Flags = NonSyncSampleFlags
+	err = frag.Moof.Traf.OptimizeTfhdTrun()
+	if err != nil {
+		t.Error(err)
+	}
+	if frag.Moof.Traf.Trun.HasSampleFlags() {
+		t.Error("sample flags still in trun")
+	}
+	gotSync, err = frag.SyncSamples(CreateTrex(1))
+	if err != nil {
+		t.Error(err)
+	}
+	if diff := deep.Equal(gotSync, []uint32{0}); diff != nil {
+		t.Error(diff)
+	}
+	// Trun samples without flags, as after decoding, must not get the default values
+	for i := range frag.Moof.Traf.Trun.Samples {
+		frag.Moof.Traf.Trun.Samples[i].Flags = 0
+	}
+	_, err = frag.SyncSamples(CreateTrex(1))
+	assertNoError(t, err)
+	for i, s := range frag.Moof.Traf.Trun.Samples {
+		if s.Flags != 0 {
+			t.Errorf("sample %d flags changed to %08x", i+1, s.Flags)
+		}
+	}
+	_, err = frag.SyncSamples(CreateTrex(2))
+	if err == nil {
+		t.Error("no error for missing track")
+	}
+}
+
+// TestNilTrexWithoutTraf - a nil trex selects the first traf, so a moof without traf must give an error
+func TestNilTrexWithoutTraf(t *testing.T) {
+	moof := &MoofBox{}
+	assertNoError(t, moof.AddChild(CreateMfhd(1)))
+	frag := NewFragment()
+	frag.AddChild(moof)
+	frag.AddChild(&MdatBox{})
+	if _, err := frag.SyncSamples(nil); err == nil {
+		t.Error("no error from SyncSamples")
+	}
+	if _, err := frag.SampleFileOffsets(nil); err == nil {
+		t.Error("no error from SampleFileOffsets")
+	}
+	if err := frag.SetExplicitSampleValues(nil); err == nil {
+		t.Error("no error from SetExplicitSampleValues")
+	}
+}
+
+// TestEmptyFragment - heartbeat fragments with zero-sample trun should encode and decode without error
+func TestEmptyFragment(t *testing.T) {
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Error(err)
+	}
+	frag.EncOptimize = OptimizeTrun
+	err = frag.Moof.Traf.OptimizeTfhdTrun()
+	if err != nil {
+		t.Errorf("zero-sample trun not accepted: %s", err)
+	}
+	buf := bytes.Buffer{}
+	err = frag.Encode(&buf)
+	if err != nil {
+		t.Error(err)
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(f.Segments) != 1 || len(f.Segments[0].Fragments) != 1 {
+		t.Fatalf("expected one fragment")
+	}
+	decFrag := f.Segments[0].Fragments[0]
+	samples, err := decFrag.GetFullSamples(nil)
+	if err != nil {
+		t.Error(err)
+	}
+	if samples == nil || len(samples) != 0 {
+		t.Errorf("expected empty sample slice, got %v", samples)
+	}
+	sync, err := decFrag.SyncSamples(nil)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(sync) != 0 {
+		t.Errorf("expected no sync samples, got %v", sync)
+	}
+}
+
+func TestSetUniformSampleDuration(t *testing.T) {
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Error(err)
+	}
+	for i := 0; i < 3; i++ {
+		frag.AddFullSample(FullSample{Sample: Sample{SyncSampleFlags, uint32(1000 + i), 2, 0},
+			DecodeTime: uint64(i * 1000), Data: []byte{byte(i), byte(i)}})
+	}
+	frag.SetUniformSampleDuration(2000)
+	traf := frag.Moof.Traf
+	if !traf.Tfhd.HasDefaultSampleDuration() || traf.Tfhd.DefaultSampleDuration != 2000 {
+		t.Error("default sample duration not set in tfhd")
+	}
+	if traf.Trun.HasSampleDuration() {
+		t.Error("sample durations still in trun")
+	}
+	err = traf.OptimizeTfhdTrun()
+	if err != nil {
+		t.Error(err)
+	}
+	buf := bytes.Buffer{}
+	err = frag.Encode(&buf)
+	if err != nil {
+		t.Error(err)
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	samples, err := f.Segments[0].Fragments[0].GetFullSamples(nil)
+	if err != nil {
+		t.Error(err)
+	}
+	if len(samples) != 3 {
+		t.Fatalf("got %d samples instead of 3", len(samples))
+	}
+	for i, s := range samples {
+		if s.Dur != 2000 || s.DecodeTime != uint64(i*2000) {
+			t.Errorf("sample %d: dur %d decodeTime %d", i, s.Dur, s.DecodeTime)
+		}
+	}
+}
+
+func TestComputeBandwidth(t *testing.T) {
+	var frags []*Fragment
+	for nr := 0; nr < 2; nr++ {
+		frag, err := CreateFragment(uint32(nr+1), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, size := range []int{100, 150} {
+			frag.AddFullSample(FullSample{Sample: Sample{SyncSampleFlags, 1000, uint32(size), 0},
+				DecodeTime: uint64(nr*2000 + i*1000), Data: make([]byte, size)})
+		}
+		frags = append(frags, frag)
+	}
+	bw, err := ComputeBandwidth(frags, nil, 1000)
+	if err != nil {
+		t.Error(err)
+	}
+	if bw != 1000 { // 500 bytes in 4s
+		t.Errorf("got bandwidth %d instead of 1000", bw)
+	}
+	_, err = ComputeBandwidth(nil, nil, 1000)
+	if err == nil {
+		t.Error("no error for zero duration")
+	}
+	_, err = ComputeBandwidth(frags, CreateTrex(2), 1000)
+	if err == nil {
+		t.Error("no error for missing track")
+	}
+}
+
+func TestSampleReaders(t *testing.T) {
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sampleData := [][]byte{{1, 2, 3}, {4, 5}, {6}}
+	for i, data := range sampleData {
+		frag.AddFullSample(FullSample{Sample: Sample{SyncSampleFlags, 1000, uint32(len(data)), 0},
+			DecodeTime: uint64(i * 1000), Data: data})
+	}
+	buf := bytes.Buffer{}
+	err = frag.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decFrag := f.Segments[0].Fragments[0]
+	readers, err := decFrag.SampleReaders(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(readers) != len(sampleData) {
+		t.Fatalf("got %d readers instead of %d", len(readers), len(sampleData))
+	}
+	for i, r := range readers {
+		got, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Error(err)
+		}
+		if !bytes.Equal(got, sampleData[i]) {
+			t.Errorf("sample %d: got %v instead of %v", i, got, sampleData[i])
+		}
+	}
+	// Readers alias the mdat data
+	decFrag.Mdat.Data[0] = 42
+	readers, _ = decFrag.SampleReaders(nil)
+	got, _ := ioutil.ReadAll(readers[0])
+	if got[0] != 42 {
+		t.Error("reader does not alias mdat data")
+	}
+}
+
+func TestRemoveTrack(t *testing.T) {
+	var frags []*Fragment
+	trackSamples := map[uint32][]FullSample{
+		1: {
+			{Sample: Sample{SyncSampleFlags, 3000, 4, 0}, DecodeTime: 90000, Data: []byte{1, 2, 3, 4}},
+			{Sample: Sample{NonSyncSampleFlags, 3000, 3, 0}, DecodeTime: 93000, Data: []byte{5, 6, 7}},
+		},
+		2: {
+			{Sample: Sample{SyncSampleFlags, 1024, 2, 0}, DecodeTime: 48000, Data: []byte{8, 9}},
+			{Sample: Sample{SyncSampleFlags, 1024, 2, 0}, DecodeTime: 49024, Data: []byte{10, 11}},
+		},
+	}
+	for trackID := uint32(1); trackID <= 2; trackID++ {
+		frag, err := CreateFragment(3, trackID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range trackSamples[trackID] {
+			frag.AddFullSample(s)
+		}
+		frags = append(frags, frag)
+	}
+	merged, err := MergeFragments(frags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := bytes.Buffer{}
+	err = merged.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frag := f.Segments[0].Fragments[0]
+	err = frag.RemoveTrack(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frag.Moof.Trafs) != 1 || frag.Moof.Traf.Tfhd.TrackID != 2 {
+		t.Fatalf("wrong trafs after removal")
+	}
+	buf.Reset()
+	err = frag.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frag.Mdat.Data) != 4 {
+		t.Errorf("mdat has %d bytes instead of 4", len(frag.Mdat.Data))
+	}
+	f, err = DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := f.Segments[0].Fragments[0].GetFullSamples(CreateTrex(2))
+	if err != nil {
+		t.Error(err)
+	}
+	if diff := deep.Equal(got, trackSamples[2]); diff != nil {
+		t.Error(diff)
+	}
+	if err = frag.RemoveTrack(1); err == nil {
+		t.Error("no error for removing missing track")
+	}
+	// Sample data must still be found with a prft between moof and mdat
+	frag = decodeWithPrftAfterMoof(t, merged)
+	err = frag.RemoveTrack(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = frag.GetFullSamples(CreateTrex(2))
+	if err != nil {
+		t.Error(err)
+	}
+	if diff := deep.Equal(got, trackSamples[2]); diff != nil {
+		t.Error(diff)
+	}
+	if err = frag.RemoveTrack(2); err == nil {
+		t.Error("no error for removing the only track")
+	}
+
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	init.AddEmptyTrack(48000, "audio", "und")
+	err = init.Moov.RemoveTrack(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	moov := init.Moov
+	if len(moov.Traks) != 1 || moov.Trak.Tkhd.TrackID != 2 {
+		t.Error("wrong traks after removal")
+	}
+	if len(moov.Mvex.Trexs) != 1 || moov.Mvex.Trex.TrackID != 2 {
+		t.Error("wrong trex boxes after removal")
+	}
+	for _, c := range moov.Children {
+		if trak, ok := c.(*TrakBox); ok && trak.Tkhd.TrackID == 1 {
+			t.Error("trak still among moov children")
+		}
+	}
+	if err = moov.RemoveTrack(1); err == nil {
+		t.Error("no error for removing missing trak")
+	}
+}
+
+func TestGroupChunksIntoSegments(t *testing.T) {
+	createChunk := func(styp *StypBox) *Fragment {
+		frag := NewFragment()
+		if styp != nil {
+			frag.AddChild(styp)
+		}
+		return frag
+	}
+	lmsgStyp := NewStyp("cmfs", 0, []string{"cmfs", "lmsg"})
+	chunks := []*Fragment{
+		createChunk(CreateStyp()), createChunk(nil), createChunk(nil),
+		createChunk(CreateStyp()), createChunk(nil), createChunk(lmsgStyp),
+		createChunk(nil),
+	}
+	if chunks[0].IsLastChunk() || !chunks[5].IsLastChunk() {
+		t.Error("wrong IsLastChunk result")
+	}
+	segments := GroupChunksIntoSegments(chunks)
+	var sizes []int
+	for _, seg := range segments {
+		sizes = append(sizes, len(seg))
+	}
+	if diff := deep.Equal(sizes, []int{3, 3, 1}); diff != nil {
+		t.Error(diff)
+	}
+}
+
+// TestGetFullSamplesInMemory - sample data should be found in a fragment built in memory, also after adding more samples
+func TestGetFullSamplesInMemory(t *testing.T) {
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inSamples := []FullSample{
+		{Sample: Sample{SyncSampleFlags, 1000, 3, 0}, DecodeTime: 0, Data: []byte{1, 2, 3}},
+		{Sample: Sample{NonSyncSampleFlags, 1000, 2, 0}, DecodeTime: 1000, Data: []byte{4, 5}},
+		{Sample: Sample{NonSyncSampleFlags, 1000, 1, 0}, DecodeTime: 2000, Data: []byte{6}},
+	}
+	for i, s := range inSamples {
+		frag.AddFullSample(s)
+		got, err := frag.GetFullSamples(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := deep.Equal(got, inSamples[:i+1]); diff != nil {
+			t.Errorf("after %d samples: %v", i+1, diff)
+		}
+	}
+	readers, err := frag.SampleReaders(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range readers {
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, inSamples[i].Data) {
+			t.Errorf("sample %d: got data %v instead of %v", i+1, data, inSamples[i].Data)
+		}
+	}
+	// The getters must not change the fragment
+	if frag.Moof.Traf.Trun.DataOffset != 0 || frag.Mdat.StartPos != 0 || frag.inMemoryPositions {
+		t.Error("fragment changed by getters")
+	}
+	mdat := &MdatBox{}
+	mdat.SetStartPos(1234)
+	if mdat.PayloadAbsoluteOffset() != 1242 {
+		t.Errorf("got payload offset %d instead of 1242", mdat.PayloadAbsoluteOffset())
+	}
+}
+
+// TestGetFullSamplesDataParts - sample data stored in multiple mdat parts should be found and checked against its size
+func TestGetFullSamplesDataParts(t *testing.T) {
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inSamples := []FullSample{
+		{Sample: Sample{SyncSampleFlags, 1000, 3, 0}, DecodeTime: 0, Data: []byte{1, 2, 3}},
+		{Sample: Sample{NonSyncSampleFlags, 1000, 2, 0}, DecodeTime: 1000, Data: []byte{4, 5}},
+		{Sample: Sample{NonSyncSampleFlags, 1000, 1, 0}, DecodeTime: 2000, Data: []byte{6}},
+	}
+	err = frag.AddSampleInterval(SampleInterval{FirstDecodeTime: 0, Samples: []Sample{inSamples[0].Sample},
+		Size: 3, Data: []byte{1, 2, 3}})
+	assertNoError(t, err)
+	err = frag.AddSampleInterval(SampleInterval{FirstDecodeTime: 1000,
+		Samples: []Sample{inSamples[1].Sample, inSamples[2].Sample}, Size: 3, Data: []byte{4, 5, 6}})
+	assertNoError(t, err)
+	got, err := frag.GetFullSamples(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(got, inSamples); diff != nil {
+		t.Error(diff)
+	}
+	readers, err := frag.SampleReaders(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range readers {
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, inSamples[i].Data) {
+			t.Errorf("sample %d: got data %v instead of %v", i+1, data, inSamples[i].Data)
+		}
+	}
+	frag.Moof.Traf.Trun.AddSample(Sample{NonSyncSampleFlags, 1000, 4, 0}) // No data for this sample
+	if _, err = frag.SampleReaders(nil); err == nil {
+		t.Error("no error for sample reader beyond mdat")
+	}
+	_, err = frag.GetFullSamples(nil)
+	if err == nil {
+		t.Error("no error for sample data beyond mdat")
+	}
+	if _, err = (&Fragment{}).SampleReaders(nil); err == nil {
+		t.Error("no error for fragment without moof and mdat")
+	}
+}
+
+func TestSetMoofStartPos(t *testing.T) {
+	var frags []*Fragment
+	for nr := 0; nr < 2; nr++ {
+		frag, err := CreateFragment(uint32(nr+1), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		frag.AddChild(CreateStyp())
+		frag.Children = append(frag.Children[2:], frag.Children[:2]...) // styp before moof
+		frag.AddFullSample(FullSample{Sample: Sample{SyncSampleFlags, 1000, 2, 0},
+			DecodeTime: uint64(nr * 1000), Data: []byte{byte(nr), 7}})
+		frags = append(frags, frag)
+	}
+	buf := bytes.Buffer{}
+	var pos uint64 = 0
+	var err error
+	for _, frag := range frags {
+		pos, err = frag.EncodeWithStartPos(&buf, pos)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if pos != uint64(buf.Len()) {
+		t.Errorf("end position %d differs from output length %d", pos, buf.Len())
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decFrags := f.Segments[len(f.Segments)-1].Fragments
+	if frags[1].Moof.StartPos != decFrags[len(decFrags)-1].Moof.StartPos {
+		t.Errorf("moof start pos %d instead of %d", frags[1].Moof.StartPos, decFrags[len(decFrags)-1].Moof.StartPos)
+	}
+	if frags[1].Mdat.StartPos != decFrags[len(decFrags)-1].Mdat.StartPos {
+		t.Errorf("mdat start pos %d instead of %d", frags[1].Mdat.StartPos, decFrags[len(decFrags)-1].Mdat.StartPos)
+	}
+	got, err := frags[1].GetFullSamples(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Data[0] != 1 {
+		t.Errorf("wrong samples %v", got)
+	}
+	// Moving a decoded fragment keeps mdat relative to moof
+	decFrag := decFrags[len(decFrags)-1]
+	delta := decFrag.Mdat.StartPos - decFrag.Moof.StartPos
+	decFrag.SetMoofStartPos(10000)
+	if decFrag.Mdat.StartPos != 10000+delta {
+		t.Errorf("mdat start pos %d instead of %d", decFrag.Mdat.StartPos, 10000+delta)
+	}
+	got, err = decFrag.GetFullSamples(nil)
+	if err != nil || len(got) != 1 || got[0].Data[0] != 1 {
+		t.Errorf("wrong samples %v after moving decoded fragment: %v", got, err)
+	}
+}
+
+// TestPrftAfterMoof - prft between moof and mdat should be accepted and possible to move before moof
+func TestPrftAfterMoof(t *testing.T) {
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inSamples := []FullSample{
+		{Sample: Sample{SyncSampleFlags, 1000, 3, 0}, DecodeTime: 0, Data: []byte{1, 2, 3}},
+		{Sample: Sample{NonSyncSampleFlags, 1000, 2, 0}, DecodeTime: 1000, Data: []byte{4, 5}},
+	}
+	for _, s := range inSamples {
+		frag.AddFullSample(s)
+	}
+	prft := CreatePrftBox(1, 0x0102030405060708, 0)
+	frag.AddChild(prft)
+	frag.Children = []Box{frag.Moof, prft, frag.Mdat}
+	for _, decodeSR := range []bool{false, true} {
+		buf := bytes.Buffer{}
+		err = frag.Encode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var f *File
+		if decodeSR {
+			f, err = DecodeFileSR(bits.NewFixedSliceReader(buf.Bytes()))
+		} else {
+			f, err = DecodeFile(&buf)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		decFrag := f.Segments[0].Fragments[0]
+		if decFrag.Prft == nil {
+			t.Error("prft not in fragment")
+		}
+		got, err := decFrag.GetFullSamples(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := deep.Equal(got, inSamples); diff != nil {
+			t.Error(diff)
+		}
+		frag.ReorderTopLevel()
+	}
+	if frag.Children[0] != prft {
+		t.Error("prft not first after ReorderTopLevel")
+	}
+}
+
+// decodeWithPrftAfterMoof - encode and decode frag with a prft inserted between moof and mdat
+func decodeWithPrftAfterMoof(t *testing.T, frag *Fragment) *Fragment {
+	t.Helper()
+	prftFrag := NewFragment()
+	prftFrag.AddChild(frag.Moof)
+	prftFrag.AddChild(CreatePrftBox(1, 0x0102030405060708, 0))
+	prftFrag.AddChild(frag.Mdat)
+	buf := bytes.Buffer{}
+	err := prftFrag.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f.Segments[0].Fragments[0]
+}
+
+func TestSampleFileOffsets(t *testing.T) {
+	buf := bytes.Buffer{}
+	var allData [][]byte
+	for nr := uint32(1); nr <= 2; nr++ {
+		frag, err := CreateFragment(nr, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 3; i++ {
+			data := []byte{byte(nr), byte(i), byte(i)}[:i+1]
+			allData = append(allData, data)
+			frag.AddFullSample(FullSample{Sample: Sample{SyncSampleFlags, 1000, uint32(len(data)), 0}, Data: data})
+		}
+		err = frag.Encode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	raw := buf.Bytes()
+	f, err := DecodeFile(bytes.NewReader(raw), WithDecodeMode(DecModeLazyMdat))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var nr int
+	for _, frag := range f.Segments[0].Fragments {
+		offsets, err := frag.SampleFileOffsets(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(offsets) != 3 {
+			t.Fatalf("got %d offsets instead of 3", len(offsets))
+		}
+		for _, offset := range offsets {
+			data := allData[nr]
+			if got := raw[offset : offset+uint64(len(data))]; !bytes.Equal(got, data) {
+				t.Errorf("sample %d: got data %v instead of %v", nr, got, data)
+			}
+			nr++
+		}
+	}
+}
+
+// TestInMemoryGettersKeepFragment - offsets and intervals of a fragment built in memory are
+// found without changing the fragment
+func TestInMemoryGettersKeepFragment(t *testing.T) {
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	frag.Moof.StartPos = 100
+	trex := CreateTrex(1)
+	trex.DefaultSampleSize = 2
+	for i := 0; i < 3; i++ {
+		frag.AddFullSample(FullSample{Sample: Sample{SyncSampleFlags, 1000, 2, 0}, Data: []byte{byte(i), byte(i)}})
+	}
+	trun := frag.Moof.Traf.Trun
+	trun.flags &= ^sampleSizePresentFlag // Sizes from trex
+	for i := range trun.Samples {
+		trun.Samples[i].Size = 0
+	}
+	payloadStart := 100 + frag.Moof.Size() + 8
+	offsets, err := frag.SampleFileOffsets(trex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(offsets, []uint64{payloadStart, payloadStart + 2, payloadStart + 4}); diff != nil {
+		t.Errorf("offsets: %v", diff)
+	}
+	itvl, err := frag.GetSampleInterval(trex, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if itvl.OffsetInMdat != 2 || !bytes.Equal(itvl.Data, []byte{1, 1, 2, 2}) {
+		t.Errorf("got interval offset %d and data %v", itvl.OffsetInMdat, itvl.Data)
+	}
+	if trun.DataOffset != 0 || frag.Mdat.StartPos != 0 || frag.inMemoryPositions || trun.Samples[0].Size != 0 {
+		t.Error("fragment changed by getters")
+	}
+}
+
+func TestRetimeSubtitles(t *testing.T) {
+	var frags []*Fragment
+	for nr := uint32(1); nr <= 2; nr++ {
+		frag, err := CreateFragment(nr, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 10; i++ {
+			decTime := uint64(nr-1)*10 + uint64(i)
+			frag.AddFullSample(FullSample{Sample: Sample{SyncSampleFlags, 1, 1, 0}, DecodeTime: decTime, Data: []byte{byte(i)}})
+		}
+		frag.SetUniformSampleDuration(1) // Durations only in tfhd
+		frags = append(frags, frag)
+	}
+	err := RetimeSubtitles(frags, 1.0/3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantBaseTimes := []uint64{0, 3}
+	wantTotalDurs := []uint64{3, 4}
+	for i, frag := range frags {
+		buf := bytes.Buffer{}
+		err = frag.Encode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := DecodeFile(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		samples, err := f.Segments[0].Fragments[0].GetFullSamples(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if samples[0].DecodeTime != wantBaseTimes[i] {
+			t.Errorf("frag %d: got base time %d instead of %d", i, samples[0].DecodeTime, wantBaseTimes[i])
+		}
+		var totalDur uint64
+		for j, s := range samples {
+			totalDur += uint64(s.Dur)
+			if !bytes.Equal(s.Data, []byte{byte(j)}) {
+				t.Errorf("frag %d sample %d: got data %v", i, j, s.Data)
+			}
+		}
+		if totalDur != wantTotalDurs[i] {
+			t.Errorf("frag %d: got total duration %d instead of %d", i, totalDur, wantTotalDurs[i])
+		}
+	}
+
+	// No fragment is changed if a later fragment has no sample durations
+	frags[1].Moof.Traf.Tfhd.Flags &= ^defaultSampleDurationPresent
+	frags[1].Moof.Traf.Trun.flags &= ^sampleDurationPresentFlag
+	wantSamples := append([]Sample(nil), frags[0].Moof.Traf.Trun.Samples...)
+	err = RetimeSubtitles(frags, 3)
+	if err == nil {
+		t.Error("no error for fragment without sample durations")
+	}
+	if frags[0].Moof.Traf.Tfdt.BaseMediaDecodeTime != 0 || frags[1].Moof.Traf.Tfdt.BaseMediaDecodeTime != 3 {
+		t.Error("base media decode times changed after error")
+	}
+	if diff := deep.Equal(frags[0].Moof.Traf.Trun.Samples, wantSamples); diff != nil {
+		t.Errorf("samples changed after error: %v", diff)
+	}
+}
+
+func TestExplicitSampleValues(t *testing.T) {
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inSamples := []FullSample{
+		{Sample: Sample{SyncSampleFlags, 1000, 2, 0}, DecodeTime: 0, Data: []byte{1, 2}},
+		{Sample: Sample{NonSyncSampleFlags, 1000, 2, 0}, DecodeTime: 1000, Data: []byte{3, 4}},
+		{Sample: Sample{NonSyncSampleFlags, 1000, 2, 0}, DecodeTime: 2000, Data: []byte{5, 6}},
+	}
+	for _, s := range inSamples {
+		frag.AddFullSample(s)
+	}
+	frag.EncOptimize = OptimizeTrun
+	buf := bytes.Buffer{}
+	err = frag.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decFrag := f.Segments[0].Fragments[0]
+	trun := decFrag.Moof.Traf.Trun
+	if trun.HasSampleDuration() || trun.HasSampleSize() || trun.HasSampleFlags() {
+		t.Fatalf("trun not optimized")
+	}
+	dump := bytes.Buffer{}
+	err = decFrag.InfoWithOptions(&dump, "", "", "  ", DumpOptions{ResolveSampleValues: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantLine := "  - sample[3]: decodeTime=2000 dur=1000 size=2 flags=00010000"
+	if !strings.Contains(dump.String(), wantLine) {
+		t.Errorf("dump lacks %q:\n%s", wantLine, dump.String())
+	}
+	if trun.Samples[2].Size != 0 {
+		t.Errorf("trun samples changed by dump")
+	}
+
+	err = decFrag.SetExplicitSampleValues(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf.Reset()
+	err = decFrag.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err = DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decFrag = f.Segments[0].Fragments[0]
+	trun = decFrag.Moof.Traf.Trun
+	if !trun.HasSampleDuration() || !trun.HasSampleSize() || !trun.HasSampleFlags() || trun.HasFirstSampleFlags() {
+		t.Errorf("trun sample values not explicit")
+	}
+	got, err := decFrag.GetFullSamples(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(got, inSamples); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestFindSubtitleGaps(t *testing.T) {
+	vtte := []byte{0, 0, 0, 8, 'v', 't', 't', 'e'}
+	var frags []*Fragment
+	// Fragments with samples covering [0, 3000), [4000, 6000) where [5000, 6000) has size 0, and [6000, 7000)
+	fragSamples := [][]FullSample{
+		{
+			{Sample: Sample{SyncSampleFlags, 2000, 8, 0}, DecodeTime: 0, Data: vtte},
+			{Sample: Sample{SyncSampleFlags, 1000, 8, 0}, DecodeTime: 2000, Data: vtte},
+		},
+		{
+			{Sample: Sample{SyncSampleFlags, 1000, 8, 0}, DecodeTime: 4000, Data: vtte},
+			{Sample: Sample{SyncSampleFlags, 1000, 0, 0}, DecodeTime: 5000},
+		},
+		{
+			{Sample: Sample{SyncSampleFlags, 1000, 8, 0}, DecodeTime: 6000, Data: vtte},
+		},
+	}
+	for i, samples := range fragSamples {
+		frag, err := CreateFragment(uint32(i+1), 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range samples {
+			frag.AddFullSample(s)
+		}
+		frags = append(frags, frag)
+	}
+	got := FindSubtitleGaps(frags, nil)
+	want := []TimeGap{{3000, 4000}, {5000, 6000}}
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Error(diff)
+	}
+	// Durations from tfhd must be used without being written to the trun samples
+	traf := frags[2].Moof.Traf
+	traf.Tfhd.Flags |= defaultSampleDurationPresent
+	traf.Tfhd.DefaultSampleDuration = 1000
+	traf.Trun.flags &= ^sampleDurationPresentFlag
+	traf.Trun.Samples[0].Dur = 0
+	got = FindSubtitleGaps(frags, nil)
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Error(diff)
+	}
+	if traf.Trun.Samples[0].Dur != 0 {
+		t.Error("trun sample changed")
+	}
+}
+
+func TestAddFullSampleChecked(t *testing.T) {
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = frag.AddFullSampleChecked(FullSample{Sample: Sample{SyncSampleFlags, 1000, 3, 0}, Data: []byte{1, 2}})
+	if err == nil {
+		t.Error("no error for size mismatch")
+	}
+	if frag.Moof.Traf.Trun.SampleCount() != 0 || len(frag.Mdat.Data) != 0 {
+		t.Error("sample added despite size mismatch")
+	}
+	err = frag.AddFullSampleChecked(FullSample{Sample: Sample{SyncSampleFlags, 1000, 2, 0}, Data: []byte{1, 2}})
+	if err != nil {
+		t.Error(err)
+	}
+	if frag.Moof.Traf.Trun.SampleCount() != 1 || len(frag.Mdat.Data) != 2 {
+		t.Error("sample not added")
+	}
+}
+
+// TestDeterministicEncode - encoding the same fragment or file twice should give identical bytes
+func TestDeterministicEncode(t *testing.T) {
+	frag, err := CreateMultiTrackFragment(1, []uint32{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	frag.EncOptimize = OptimizeTrun
+	for i := 0; i < 4; i++ {
+		for trackID := uint32(1); trackID <= 2; trackID++ {
+			data := []byte{byte(trackID), byte(i)}
+			fs := FullSample{Sample: Sample{SyncSampleFlags, 1000, 2, 0}, DecodeTime: uint64(i) * 1000, Data: data}
+			err = frag.AddFullSampleToTrack(fs, trackID)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	var outputs [][]byte
+	for i := 0; i < 2; i++ {
+		buf := bytes.Buffer{}
+		err = frag.Encode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs = append(outputs, buf.Bytes())
+		sw := bits.NewFixedSliceWriter(int(frag.Size()))
+		err = frag.EncodeSW(sw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		outputs = append(outputs, sw.Bytes())
+	}
+	for i := 1; i < len(outputs); i++ {
+		if !bytes.Equal(outputs[0], outputs[i]) {
+			t.Errorf("fragment encoding %d differs from the first", i)
+		}
+	}
+
+	inData, err := ioutil.ReadFile("testdata/prog_8s_dec_dashinit.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(bytes.NewReader(inData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var first []byte
+	for i := 0; i < 2; i++ {
+		buf := bytes.Buffer{}
+		err = f.Encode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 0 {
+			first = buf.Bytes()
+		} else if !bytes.Equal(first, buf.Bytes()) {
+			t.Error("file encodings differ")
+		}
+	}
+}
+
+func TestFragmentFromSamples(t *testing.T) {
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		flags := NonSyncSampleFlags
+		if i == 0 {
+			flags = SyncSampleFlags
+		}
+		s := FullSample{Sample: Sample{flags, 1000, 1, int32(i%2) * 1000}, DecodeTime: 5000 + uint64(i)*1000, Data: []byte{byte(i)}}
+		frag.AddFullSample(s)
+	}
+	samples, err := frag.GetFullSamples(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Drop the last sample and extend the previous one to keep the timeline
+	var kept []*FullSample
+	for i := 0; i < 3; i++ {
+		kept = append(kept, &samples[i])
+	}
+	kept[2].Dur = 2000
+	newFrag, err := FragmentFromSamples(2, 1, kept)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := bytes.Buffer{}
+	err = newFrag.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := f.Segments[0].Fragments[0].GetFullSamples(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(got, samples[:3]); diff != nil {
+		t.Error(diff)
+	}
+	// Dropping the middle sample extends the duration of the first one
+	gapFrag, err := FragmentFromSamples(2, 1, []*FullSample{kept[0], kept[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kept[0].Dur != 1000 {
+		t.Errorf("input sample duration changed to %d", kept[0].Dur)
+	}
+	got, err = gapFrag.GetFullSamples(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Dur != 2000 || got[1].DecodeTime != kept[2].DecodeTime || got[1].Dur != 2000 {
+		t.Errorf("got samples %v", got)
+	}
+	_, err = FragmentFromSamples(2, 1, []*FullSample{kept[2], kept[0]})
+	if err == nil {
+		t.Error("no error for decreasing decode times")
+	}
+}
+
+func TestAddFullSampleToTrack(t *testing.T) {
+	frag, err := CreateMultiTrackFragment(1, []uint32{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inSamples := map[uint32][]FullSample{}
+	for i := 0; i < 6; i++ {
+		for trackID := uint32(1); trackID <= 2; trackID++ {
+			if trackID == 2 && i == 3 {
+				err = frag.StartNewTrun(trackID)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			data := []byte{byte(trackID), byte(i), byte(i)}[:1+int(trackID)]
+			fs := FullSample{Sample: Sample{SyncSampleFlags, 1000 * trackID, uint32(len(data)), 0},
+				DecodeTime: 5000 + uint64(i)*1000*uint64(trackID), Data: data}
+			inSamples[trackID] = append(inSamples[trackID], fs)
+			if trackID == 2 && i%2 == 1 {
+				continue // Add two consecutive samples of track 1 now and then
+			}
+			err = frag.AddFullSampleToTrack(fs, trackID)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if i%2 == 1 {
+			fs := inSamples[2][len(inSamples[2])-1]
+			err = frag.AddFullSampleToTrack(fs, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err = frag.AddFullSampleToTrack(inSamples[1][0], 3); err == nil {
+		t.Error("no error for unknown trackID")
+	}
+	if err = frag.StartNewTrun(3); err == nil {
+		t.Error("no error for new trun in unknown trackID")
+	}
+	if err = NewFragment().AddFullSampleToTrack(inSamples[1][0], 1); err == nil {
+		t.Error("no error for fragment without moof")
+	}
+	// No empty trun should be left if no samples follow
+	for i := 0; i < 2; i++ {
+		if err = frag.StartNewTrun(1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, traf := range frag.Moof.Trafs {
+		for _, trun := range traf.Truns {
+			if trun.SampleCount() == 0 {
+				t.Errorf("empty trun in track %d", traf.Tfhd.TrackID)
+			}
+		}
+	}
+	buf := bytes.Buffer{}
+	err = frag.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decFrag := f.Segments[0].Fragments[0]
+	for trackID := uint32(1); trackID <= 2; trackID++ {
+		got, err := decFrag.GetFullSamples(&TrexBox{TrackID: trackID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := deep.Equal(got, inSamples[trackID]); diff != nil {
+			t.Errorf("track %d: %v", trackID, diff)
+		}
+	}
+}
+
+func TestGetFullSamplesFromReader(t *testing.T) {
+	buf := bytes.Buffer{}
+	var inSamples [][]FullSample
+	for nr := uint32(1); nr <= 2; nr++ {
+		frag, err := CreateFragment(nr, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var samples []FullSample
+		for i := 0; i < 3; i++ {
+			data := []byte{byte(nr), byte(i), 7}
+			s := FullSample{Sample: Sample{SyncSampleFlags, 1000, 3, 0}, DecodeTime: uint64(nr*3+uint32(i)) * 1000, Data: data}
+			frag.AddFullSample(s)
+			samples = append(samples, s)
+		}
+		inSamples = append(inSamples, samples)
+		err = frag.Encode(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	rs := bytes.NewReader(buf.Bytes())
+	f, err := DecodeFile(rs, WithDecodeMode(DecModeLazyMdat))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, frag := range f.Segments[0].Fragments {
+		if !frag.Mdat.IsLazy() {
+			t.Fatal("mdat not lazy")
+		}
+		got, err := frag.GetFullSamplesFromReader(nil, rs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := deep.Equal(got, inSamples[i]); diff != nil {
+			t.Errorf("fragment %d: %v", i+1, diff)
+		}
+	}
+}
+
+func TestFragmentValidate(t *testing.T) {
+	frag, err := CreateMultiTrackFragment(1, []uint32{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		for trackID := uint32(1); trackID <= 2; trackID++ {
+			fs := FullSample{Sample: Sample{SyncSampleFlags, 1000, 2, 0}, DecodeTime: uint64(i) * 1000, Data: []byte{1, 2}}
+			err = frag.AddFullSampleToTrack(fs, trackID)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	frag.SetTrunDataOffsets()
+	err = frag.Validate()
+	if err != nil {
+		t.Errorf("valid fragment: %v", err)
+	}
+	if frag.Mdat.StartPos != 0 || frag.inMemoryPositions {
+		t.Error("fragment changed by Validate")
+	}
+	trun := frag.Moof.Trafs[1].Truns[0]
+	trun.DataOffset += int32(frag.Moof.Size())
+	if err = frag.Validate(); err == nil {
+		t.Error("no error for data offset beyond mdat")
+	}
+	trun.DataOffset -= int32(frag.Moof.Size()) + 1
+	if err = frag.Validate(); err == nil {
+		t.Error("no error for overlapping trun data")
+	}
+	frag.SetTrunDataOffsets()
+	frag.Mdat.AddSampleData([]byte{3})
+	if err = frag.Validate(); err == nil {
+		t.Error("no error for extra mdat data")
+	}
+}
+
+// TestTrunContinuation - a trun without data offset continues after the data of the previous trun
+func TestTrunContinuation(t *testing.T) {
+	trun1 := CreateTrun(0)
+	trun1.AddSample(NewSample(SyncSampleFlags, 10, 3, 0))
+	trun1.AddSample(NewSample(NonSyncSampleFlags, 10, 2, 0))
+	trun2 := CreateTrun(1)
+	trun2.flags &^= dataOffsetPresentFlag
+	trun2.AddSample(NewSample(NonSyncSampleFlags, 10, 4, 0))
+	trun2.AddSample(NewSample(NonSyncSampleFlags, 10, 1, 0))
+	traf := &TrafBox{}
+	assertNoError(t, traf.AddChild(&TfhdBox{TrackID: 1, Flags: baseDataOffsetPresent, BaseDataOffset: 0}))
+	assertNoError(t, traf.AddChild(&TfdtBox{Version: 1, BaseMediaDecodeTime: 1000}))
+	assertNoError(t, traf.AddChild(trun1))
+	assertNoError(t, traf.AddChild(trun2))
+	moof := &MoofBox{}
+	assertNoError(t, moof.AddChild(&MfhdBox{SequenceNumber: 1}))
+	assertNoError(t, moof.AddChild(traf))
+	mdat := &MdatBox{Data: []byte("aaabbccccd")}
+	trun1.DataOffset = int32(moof.Size() + mdat.HeaderSize()) // Absolute since BaseDataOffset is 0
+
+	buf := bytes.Buffer{}
+	assertNoError(t, moof.Encode(&buf))
+	assertNoError(t, mdat.Encode(&buf))
+	f, err := DecodeFile(&buf)
+	assertNoError(t, err)
+	frag := f.Segments[0].Fragments[0]
+	samples, err := frag.GetFullSamples(nil)
+	assertNoError(t, err)
+	wantedData := []string{"aaa", "bb", "cccc", "d"}
+	if len(samples) != len(wantedData) {
+		t.Fatalf("got %d samples instead of %d", len(samples), len(wantedData))
+	}
+	for i, s := range samples {
+		if string(s.Data) != wantedData[i] || s.DecodeTime != 1000+uint64(10*i) {
+			t.Errorf("sample %d: got data %q and time %d", i+1, s.Data, s.DecodeTime)
+		}
+	}
+	offsets, err := frag.SampleFileOffsets(nil)
+	assertNoError(t, err)
+	if offsets[2] != uint64(trun1.DataOffset)+5 {
+		t.Errorf("got offset %d for first sample of second trun", offsets[2])
+	}
+	assertNoError(t, frag.Validate())
+}
+
+// TestTrunContinuationTrexSize - the data size of a previous trun without sample sizes
+// is given by the trex default if not in tfhd
+func TestTrunContinuationTrexSize(t *testing.T) {
+	trun1 := CreateTrun(0)
+	trun1.flags &^= sampleSizePresentFlag
+	trun1.AddSample(NewSample(SyncSampleFlags, 10, 0, 0))
+	trun1.AddSample(NewSample(NonSyncSampleFlags, 10, 0, 0))
+	trun2 := CreateTrun(1)
+	trun2.flags &^= dataOffsetPresentFlag | sampleSizePresentFlag
+	trun2.AddSample(NewSample(NonSyncSampleFlags, 10, 0, 0))
+	traf := &TrafBox{}
+	assertNoError(t, traf.AddChild(&TfhdBox{TrackID: 1, Flags: baseDataOffsetPresent, BaseDataOffset: 0}))
+	assertNoError(t, traf.AddChild(&TfdtBox{Version: 1, BaseMediaDecodeTime: 1000}))
+	assertNoError(t, traf.AddChild(trun1))
+	assertNoError(t, traf.AddChild(trun2))
+	moof := &MoofBox{}
+	assertNoError(t, moof.AddChild(&MfhdBox{SequenceNumber: 1}))
+	assertNoError(t, moof.AddChild(traf))
+	mdat := &MdatBox{Data: []byte("aabbcc")}
+	trun1.DataOffset = int32(moof.Size() + mdat.HeaderSize()) // Absolute since BaseDataOffset is 0
+
+	buf := bytes.Buffer{}
+	assertNoError(t, moof.Encode(&buf))
+	assertNoError(t, mdat.Encode(&buf))
+	f, err := DecodeFile(&buf)
+	assertNoError(t, err)
+	frag := f.Segments[0].Fragments[0]
+	trex := CreateTrex(1)
+	trex.DefaultSampleSize = 2
+	samples, err := frag.GetFullSamples(trex)
+	assertNoError(t, err)
+	wantedData := []string{"aa", "bb", "cc"}
+	if len(samples) != len(wantedData) {
+		t.Fatalf("got %d samples instead of %d", len(samples), len(wantedData))
+	}
+	for i, s := range samples {
+		if string(s.Data) != wantedData[i] {
+			t.Errorf("sample %d: got data %q", i+1, s.Data)
+		}
+	}
+}
+
+func TestTrimSamples(t *testing.T) {
+	createFrag := func() *Fragment {
+		frag, err := CreateFragment(1, 1)
+		assertNoError(t, err)
+		for i := 0; i < 8; i++ {
+			flags := NonSyncSampleFlags
+			if i%4 == 0 {
+				flags = SyncSampleFlags
+			}
+			frag.AddFullSample(FullSample{Sample: NewSample(flags, 1000, 2, 0),
+				DecodeTime: 10000 + uint64(1000*i), Data: []byte{byte(i), byte(i)}})
+		}
+		return frag
+	}
+	frag := createFrag()
+	err := frag.TrimSamples(14000, 17000)
+	assertNoError(t, err)
+	var buf bytes.Buffer
+	err = frag.Encode(&buf)
+	assertNoError(t, err)
+	decoded, err := DecodeFile(&buf)
+	assertNoError(t, err)
+	withPrft := decodeWithPrftAfterMoof(t, createFrag()) // Sample data must be found with prft between moof and mdat
+	err = withPrft.TrimSamples(14000, 17000)
+	assertNoError(t, err)
+	for _, f := range []*Fragment{frag, decoded.Segments[0].Fragments[0], withPrft} {
+		samples, err := f.GetFullSamples(nil)
+		assertNoError(t, err)
+		if len(samples) != 3 {
+			t.Fatalf("got %d samples instead of 3", len(samples))
+		}
+		for i, s := range samples {
+			nr := byte(4 + i)
+			if s.DecodeTime != 14000+uint64(1000*i) || !bytes.Equal(s.Data, []byte{nr, nr}) {
+				t.Errorf("sample %d: decode time %d and data %v", i+1, s.DecodeTime, s.Data)
+			}
+		}
+		if !samples[0].IsSync() {
+			t.Errorf("first sample is not sync")
+		}
+	}
+	frag = createFrag()
+	err = frag.TrimSamples(11000, 20000)
+	if err == nil {
+		t.Errorf("no error for non-sync first sample")
+	}
+	trun := frag.Moof.Traf.Trun
+	if trun.SampleCount() != 8 || trun.DataOffset != 0 || frag.Mdat.StartPos != 0 || frag.inMemoryPositions {
+		t.Errorf("fragment changed after error")
+	}
+	err = frag.TrimSamples(20000, 30000)
+	if err == nil {
+		t.Errorf("no error when no sample is left")
+	}
+}
+
+func TestInterleaveTracks(t *testing.T) {
+	createFrag := func() *Fragment {
+		frag, err := CreateMultiTrackFragment(1, []uint32{1, 2})
+		assertNoError(t, err)
+		for i := 0; i < 6; i++ {
+			err = frag.AddFullSampleToTrack(FullSample{Sample: NewSample(SyncSampleFlags, 3000, 2, 0),
+				DecodeTime: uint64(3000 * i), Data: []byte{1, byte(i)}}, 1)
+			assertNoError(t, err)
+		}
+		for i := 0; i < 8; i++ {
+			err = frag.AddFullSampleToTrack(FullSample{Sample: NewSample(SyncSampleFlags, 1024, 3, 0),
+				DecodeTime: uint64(1024 * i), Data: []byte{2, byte(i), 0}}, 2)
+			assertNoError(t, err)
+		}
+		return frag
+	}
+	timescales := map[uint32]uint32{1: 90000, 2: 48000}
+	testCases := []struct {
+		mode         InterleaveMode
+		wantedTracks []uint32 // trackID of each trun in data order
+	}{
+		{InterleaveByTrack, []uint32{1, 2}},
+		{InterleaveByDecodeTime, []uint32{1, 2, 1, 2}},
+	}
+	for _, tc := range testCases {
+		orig := createFrag()
+		frag := createFrag()
+		err := frag.InterleaveTracks(tc.mode, 0.1, timescales)
+		assertNoError(t, err)
+		var buf bytes.Buffer
+		err = frag.Encode(&buf)
+		assertNoError(t, err)
+		decoded, err := DecodeFile(&buf)
+		assertNoError(t, err)
+		dFrag := decoded.Segments[0].Fragments[0]
+		var trackIDs []uint32
+		var offsets []int32
+		for _, traf := range dFrag.Moof.Trafs {
+			for _, trun := range traf.Truns {
+				trackIDs = append(trackIDs, traf.Tfhd.TrackID)
+				offsets = append(offsets, trun.DataOffset)
+			}
+		}
+		sort.Sort(byOffset{trackIDs, offsets})
+		if diff := deep.Equal(trackIDs, tc.wantedTracks); diff != nil {
+			t.Errorf("mode %d: %v", tc.mode, diff)
+		}
+		for _, trackID := range []uint32{1, 2} {
+			trex := &TrexBox{TrackID: trackID}
+			wanted, err := orig.GetFullSamples(trex)
+			assertNoError(t, err)
+			got, err := dFrag.GetFullSamples(trex)
+			assertNoError(t, err)
+			if diff := deep.Equal(got, wanted); diff != nil {
+				t.Errorf("mode %d track %d: %v", tc.mode, trackID, diff)
+			}
+		}
+	}
+	// Sample data must be found with a prft between moof and mdat
+	withPrft := decodeWithPrftAfterMoof(t, createFrag())
+	err := withPrft.InterleaveTracks(InterleaveByDecodeTime, 0.1, timescales)
+	assertNoError(t, err)
+	orig := createFrag()
+	for _, trackID := range []uint32{1, 2} {
+		trex := &TrexBox{TrackID: trackID}
+		wanted, err := orig.GetFullSamples(trex)
+		assertNoError(t, err)
+		got, err := withPrft.GetFullSamples(trex)
+		assertNoError(t, err)
+		if diff := deep.Equal(got, wanted); diff != nil {
+			t.Errorf("prft after moof track %d: %v", trackID, diff)
+		}
+	}
+	frag := createFrag()
+	if err := frag.InterleaveTracks(InterleaveByDecodeTime, 0.1, map[uint32]uint32{1: 90000}); err == nil {
+		t.Error("no error for missing timescale")
+	}
+	if len(frag.Moof.Trafs[0].Truns) != 1 || frag.Moof.Trafs[0].Trun.DataOffset != 0 || frag.inMemoryPositions {
+		t.Error("fragment changed after error")
+	}
+}
+
+// byOffset - sort trackIDs by trun data offsets
+type byOffset struct {
+	trackIDs []uint32
+	offsets  []int32
+}
+
+func (b byOffset) Len() int           { return len(b.offsets) }
+func (b byOffset) Less(i, j int) bool { return b.offsets[i] < b.offsets[j] }
+func (b byOffset) Swap(i, j int) {
+	b.trackIDs[i], b.trackIDs[j] = b.trackIDs[j], b.trackIDs[i]
+	b.offsets[i], b.offsets[j] = b.offsets[j], b.offsets[i]
+}
+
+func TestSplitFragment(t *testing.T) {
+	frag, err := CreateFragment(5, 1)
+	assertNoError(t, err)
+	for i := 0; i < 6; i++ {
+		flags := NonSyncSampleFlags
+		if i%3 == 0 {
+			flags = SyncSampleFlags
+		}
+		frag.AddFullSample(FullSample{Sample: NewSample(flags, 1000, uint32(i+1), int32(500*(i%2))),
+			DecodeTime: 9000 + uint64(1000*i), Data: bytes.Repeat([]byte{byte(i)}, i+1)})
+	}
+	orig, err := frag.GetFullSamples(nil)
+	assertNoError(t, err)
+	before, after, err := SplitFragment(frag, 12000, nil)
+	assertNoError(t, err)
+	if before.Moof.Mfhd.SequenceNumber != 5 || after.Moof.Mfhd.SequenceNumber != 6 {
+		t.Errorf("got sequence numbers %d and %d", before.Moof.Mfhd.SequenceNumber, after.Moof.Mfhd.SequenceNumber)
+	}
+	if after.Moof.Traf.Tfdt.BaseMediaDecodeTime != 12000 {
+		t.Errorf("got after tfdt %d", after.Moof.Traf.Tfdt.BaseMediaDecodeTime)
+	}
+	var union []FullSample
+	for _, f := range []*Fragment{before, after} {
+		var buf bytes.Buffer
+		err = f.Encode(&buf)
+		assertNoError(t, err)
+		decoded, err := DecodeFile(&buf)
+		assertNoError(t, err)
+		samples, err := decoded.Segments[0].Fragments[0].GetFullSamples(nil)
+		assertNoError(t, err)
+		union = append(union, samples...)
+	}
+	if diff := deep.Equal(union, orig); diff != nil {
+		t.Error(diff)
+	}
+	for _, splitTime := range []uint64{9000, 12500, 15000} {
+		_, _, err = SplitFragment(frag, splitTime, nil)
+		if err == nil {
+			t.Errorf("no error for split time %d", splitTime)
+		}
+	}
+	_, _, err = SplitFragment(frag, 12500, nil)
+	if err == nil || !strings.Contains(err.Error(), "12000 and 13000") {
+		t.Errorf("got error %v", err)
+	}
+	// Top-level boxes are kept in before
+	emsg := &EmsgBox{Version: 1, SchemeIDURI: "urn:test", Value: "1", ID: 1}
+	prft := CreatePrftBox(1, 0x0102030405060708, 9000)
+	frag.AddChild(emsg)
+	frag.AddChild(prft)
+	before, _, err = SplitFragment(frag, 12000, nil)
+	assertNoError(t, err)
+	if len(before.Emsgs) != 1 || before.Prft != prft || before.Children[len(before.Children)-1] != before.Mdat {
+		t.Errorf("emsg and prft not kept before moof")
+	}
+	// Per-sample boxes are not split
+	sdtp := &SdtpBox{Entries: []SdtpEntry{NewSdtpEntry(0, 2, 1, 0)}}
+	_ = frag.Moof.Traf.AddChild(sdtp)
+	_, _, err = SplitFragment(frag, 12000, nil)
+	if err == nil {
+		t.Error("no error for traf with sdtp")
+	}
+}
+
+func TestFragmentDuration(t *testing.T) {
+	fd, err := os.Open("testdata/1.m4s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	f, err := DecodeFile(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, seg := range f.Segments {
+		for _, frag := range seg.Fragments {
+			got := frag.Duration(nil)
+			samples, err := frag.GetFullSamples(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want uint64
+			for _, s := range samples {
+				want += uint64(s.Dur)
+			}
+			if got != want {
+				t.Errorf("got duration %d instead of %d", got, want)
+			}
+		}
+	}
+
+	// Default duration from trex
+	frag, err := CreateFragment(1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trun := frag.Moof.Traf.Trun
+	for i := 0; i < 3; i++ {
+		trun.AddSample(NewSample(SyncSampleFlags, 0, 10, 0))
+	}
+	trun.flags &^= sampleDurationPresentFlag
+	trex := &TrexBox{TrackID: 2, DefaultSampleDuration: 1024}
+	if got := frag.Duration(trex); got != 3*1024 {
+		t.Errorf("got duration %d instead of %d", got, 3*1024)
+	}
+	if got := frag.Duration(&TrexBox{TrackID: 3}); got != 0 {
+		t.Errorf("got duration %d for missing track", got)
+	}
+}
@@ -0,0 +1,102 @@
+package mp4
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// FragmentReader - read fragments one at a time from a stream of top-level boxes, like a live feed.
+// Every fragment consists of all boxes up to and including the mdat following a moof,
+// so boxes like styp, prft, and emsg before the moof are attached to the fragment.
+// Each box is read completely before it is decoded, so partial reads from r are fine.
+type FragmentReader struct {
+	r   io.Reader
+	pos uint64 // Position in stream of next box
+}
+
+// NewFragmentReader - create a FragmentReader reading from r
+func NewFragmentReader(r io.Reader) *FragmentReader {
+	return &FragmentReader{r: r}
+}
+
+// Next - read and return the next fragment.
+// io.EOF is returned when the stream ends between fragments, also if there are boxes without
+// moof after the last fragment, like mfra, which are then skipped. io.ErrUnexpectedEOF is returned
+// when the stream ends inside a box or between a moof and its mdat.
+func (fr *FragmentReader) Next() (*Fragment, error) {
+	frag := NewFragment()
+	for {
+		box, err := fr.readBox()
+		if err != nil {
+			if err == io.EOF && frag.Moof != nil {
+				return nil, io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		switch box.Type() {
+		case "moof":
+			if frag.Moof != nil {
+				return nil, fmt.Errorf("moof at %d follows moof without mdat", box.(*MoofBox).StartPos)
+			}
+		case "mdat":
+			if frag.Moof == nil {
+				return nil, fmt.Errorf("mdat at %d without preceding moof", box.(*MdatBox).StartPos)
+			}
+		}
+		frag.AddChild(box)
+		if frag.Mdat != nil {
+			return frag, nil
+		}
+	}
+}
+
+// readBox - read and decode the next top-level box.
+// io.EOF is returned if the stream ends before the box starts.
+func (fr *FragmentReader) readBox() (Box, error) {
+	hdr := make([]byte, boxHeaderSize, boxHeaderSize+largeSizeLen)
+	_, err := io.ReadFull(fr.r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	size := uint64(binary.BigEndian.Uint32(hdr[0:4]))
+	if size == 1 {
+		hdr = hdr[:boxHeaderSize+largeSizeLen]
+		_, err = io.ReadFull(fr.r, hdr[boxHeaderSize:])
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		size = binary.BigEndian.Uint64(hdr[boxHeaderSize:])
+	}
+	if size < uint64(len(hdr)) {
+		return nil, fmt.Errorf("box %q at %d has size %d", string(hdr[4:8]), fr.pos, size)
+	}
+	if size > math.MaxInt64 {
+		return nil, fmt.Errorf("box %q at %d has too large size %d", string(hdr[4:8]), fr.pos, size)
+	}
+	// Read into a growing buffer, so that a bad size does not result in a huge allocation
+	// before the stream ends.
+	buf := bytes.NewBuffer(hdr)
+	_, err = io.CopyN(buf, fr.r, int64(size)-int64(len(hdr)))
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	box, err := DecodeBoxSR(fr.pos, bits.NewFixedSliceReader(buf.Bytes()))
+	if err != nil {
+		return nil, err
+	}
+	fr.pos += size
+	return box, nil
+}
+
+// unexpectedEOF - io.ErrUnexpectedEOF instead of io.EOF for a stream ending inside a box
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,116 @@
+package mp4
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestFragmentReader(t *testing.T) {
+	var stream bytes.Buffer
+	for i := 0; i < 3; i++ {
+		frag, err := CreateFragment(uint32(i+1), 1)
+		assertNoError(t, err)
+		if i == 1 {
+			frag.Children = nil
+			frag.AddChild(CreateStyp())
+			frag.AddChild(&EmsgBox{Version: 1, TimeScale: 1000, SchemeIDURI: "urn:test", Value: "1"})
+			frag.AddChild(CreatePrft(1, 1<<40, 2000))
+			frag.AddChild(frag.Moof)
+			frag.AddChild(frag.Mdat)
+		}
+		frag.AddFullSample(FullSample{Sample: NewSample(SyncSampleFlags, 1000, 3, 0),
+			DecodeTime: uint64(1000 * i), Data: []byte{byte(i), 1, 2}})
+		err = frag.Encode(&stream)
+		assertNoError(t, err)
+	}
+	raw := stream.Bytes()
+
+	fr := NewFragmentReader(iotest.HalfReader(bytes.NewReader(raw)))
+	for i := 0; i < 3; i++ {
+		frag, err := fr.Next()
+		assertNoError(t, err)
+		if frag.Moof.Mfhd.SequenceNumber != uint32(i+1) {
+			t.Errorf("fragment %d: got sequence number %d", i+1, frag.Moof.Mfhd.SequenceNumber)
+		}
+		wantedChildren := 2
+		if i == 1 {
+			wantedChildren = 5
+			if frag.Styp == nil || frag.Prft == nil || len(frag.Emsgs) != 1 {
+				t.Errorf("fragment 2 lacks styp, prft, or emsg")
+			}
+		}
+		if len(frag.Children) != wantedChildren {
+			t.Errorf("fragment %d: got %d children instead of %d", i+1, len(frag.Children), wantedChildren)
+		}
+		samples, err := frag.GetFullSamples(nil)
+		assertNoError(t, err)
+		if len(samples) != 1 || samples[0].DecodeTime != uint64(1000*i) || samples[0].Data[0] != byte(i) {
+			t.Errorf("fragment %d: got samples %v", i+1, samples)
+		}
+	}
+	_, err := fr.Next()
+	if err != io.EOF {
+		t.Errorf("got %v instead of io.EOF at end of stream", err)
+	}
+
+	// Boxes without moof after the last fragment are skipped
+	trailing := bytes.NewBuffer(append([]byte(nil), raw...))
+	for _, box := range []Box{CreateStyp(), &FreeBox{Name: "free", notDecoded: []byte{0, 1}}} {
+		err = box.Encode(trailing)
+		assertNoError(t, err)
+	}
+	fr = NewFragmentReader(trailing)
+	var nrFrags int
+	for {
+		_, err = fr.Next()
+		if err != nil {
+			break
+		}
+		nrFrags++
+	}
+	if err != io.EOF || nrFrags != 3 {
+		t.Errorf("got %v after %d fragments instead of io.EOF after 3 with trailing boxes", err, nrFrags)
+	}
+
+	// Truncated inside the last mdat, and right before it (11 bytes)
+	for _, size := range []int{len(raw) - 1, len(raw) - 11} {
+		fr = NewFragmentReader(bytes.NewReader(raw[:size]))
+		for {
+			_, err = fr.Next()
+			if err != nil {
+				break
+			}
+		}
+		if err != io.ErrUnexpectedEOF {
+			t.Errorf("got %v instead of io.ErrUnexpectedEOF for truncated stream", err)
+		}
+	}
+}
+
+func TestFragmentReaderBadSize(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		size    uint64
+		wantEOF bool
+	}{
+		{"huge size", 1 << 62, true},
+		{"too large size", 1 << 63, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			data := []byte{0, 0, 0, 1, 'f', 'r', 'e', 'e'}
+			data = append(data, byte(tc.size>>56), byte(tc.size>>48), byte(tc.size>>40), byte(tc.size>>32),
+				byte(tc.size>>24), byte(tc.size>>16), byte(tc.size>>8), byte(tc.size))
+			data = append(data, 1, 2, 3, 4)
+			_, err := NewFragmentReader(bytes.NewReader(data)).Next()
+			if err == nil {
+				t.Fatal("no error for bad box size")
+			}
+			if (err == io.ErrUnexpectedEOF) != tc.wantEOF {
+				t.Errorf("got error %v", err)
+			}
+		})
+	}
+}
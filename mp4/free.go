@@ -13,7 +13,7 @@ type FreeBox struct {
 }
 
 // DecodeFree - box-specific decode
-func DecodeFree(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeFree(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -22,8 +22,8 @@ func DecodeFree(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeFreeSR - box-specific decode
-func DecodeFreeSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
-	return &FreeBox{Name: hdr.name, notDecoded: sr.ReadBytes(hdr.payloadLen())}, sr.AccError()
+func DecodeFreeSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	return &FreeBox{Name: hdr.name, notDecoded: sr.ReadBytes(hdr.PayloadLen())}, sr.AccError()
 }
 
 // Type - box type
@@ -13,7 +13,7 @@ type FrmaBox struct {
 }
 
 // DecodeFrma - box-specific decode
-func DecodeFrma(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeFrma(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -23,8 +23,8 @@ func DecodeFrma(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeFrmaSR - box-specific decode
-func DecodeFrmaSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
-	if hdr.payloadLen() != 4 {
+func DecodeFrmaSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	if hdr.PayloadLen() != 4 {
 		return nil, fmt.Errorf("Frma content length is not 4")
 	}
 	return &FrmaBox{DataFormat: string(sr.ReadFixedLengthString(4))}, sr.AccError()
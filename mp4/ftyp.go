@@ -36,6 +36,73 @@ func (b *FtypBox) CompatibleBrands() []string {
 	return compatibleBrands
 }
 
+// HasBrand - true if brand is the major brand or one of the compatible brands
+func (b *FtypBox) HasBrand(brand string) bool {
+	return b.MajorBrand() == brand || b.CompatibleWith(brand)
+}
+
+// CompatibleWith - true if brand is in the list of compatible brands
+func (b *FtypBox) CompatibleWith(brand string) bool {
+	for _, cb := range b.CompatibleBrands() {
+		if cb == brand {
+			return true
+		}
+	}
+	return false
+}
+
+// MissingBrands - brands in brands that are not declared by the ftyp box
+func (b *FtypBox) MissingBrands(brands []string) []string {
+	var missing []string
+	for _, brand := range brands {
+		if !b.HasBrand(brand) {
+			missing = append(missing, brand)
+		}
+	}
+	return missing
+}
+
+// HasAnyBrand - true if at least one of brands is declared by the ftyp box
+func (b *FtypBox) HasAnyBrand(brands []string) bool {
+	for _, brand := range brands {
+		if b.HasBrand(brand) {
+			return true
+		}
+	}
+	return false
+}
+
+// cmafBrands - structural brands expected in the ftyp of a CMAF init segment
+var cmafBrands = []string{"iso6", "cmfc"}
+
+// CMAFBrands - structural brands expected in the ftyp of a CMAF init segment.
+// A new slice is returned, so changing it does not affect later calls.
+func CMAFBrands() []string {
+	return append([]string(nil), cmafBrands...)
+}
+
+// sampleEntryBrands - CMAF media profile brands per sample entry type (ISO/IEC 23000-19)
+var sampleEntryBrands = map[string][]string{
+	"avc1": {"cfhd", "cfsd"},
+	"avc3": {"cfhd", "cfsd"},
+	"hvc1": {"chh1", "chd1"},
+	"hev1": {"chh1", "chd1"},
+	"mp4a": {"caac"},
+	"wvtt": {"cwvt"},
+}
+
+// BrandsForSampleEntry - CMAF media profile brands for a sample entry type.
+// A CMAF init segment is expected to declare one of the brands (checked by HasAnyBrand), which
+// one depends on the resolution and other properties of the track, in addition to CMAFBrands.
+// ok is false if the sample entry type is not known.
+func BrandsForSampleEntry(sampleEntryType string) (brands []string, ok bool) {
+	brands, ok = sampleEntryBrands[sampleEntryType]
+	if !ok {
+		return nil, false
+	}
+	return append([]string(nil), brands...), true
+}
+
 // CreateFtyp - Create an Ftyp box suitable for DASH/CMAF
 func CreateFtyp() *FtypBox {
 	return NewFtyp("cmfc", 0, []string{"dash", "iso6"})
@@ -54,7 +121,7 @@ func NewFtyp(majorBrand string, minorVersion uint32, compatibleBrands []string)
 }
 
 // DecodeFtyp - box-specific decode
-func DecodeFtyp(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeFtyp(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -64,8 +131,8 @@ func DecodeFtyp(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeFtypSR - box-specific decode
-func DecodeFtypSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
-	return &FtypBox{data: sr.ReadBytes(hdr.payloadLen())}, sr.AccError()
+func DecodeFtypSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	return &FtypBox{data: sr.ReadBytes(hdr.PayloadLen())}, sr.AccError()
 }
 
 // Type - return box type
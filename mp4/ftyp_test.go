@@ -2,6 +2,8 @@ package mp4
 
 import (
 	"testing"
+
+	"github.com/go-test/deep"
 )
 
 func TestFtyp(t *testing.T) {
@@ -9,3 +11,71 @@ func TestFtyp(t *testing.T) {
 	ftyp := CreateFtyp()
 	boxDiffAfterEncodeAndDecode(t, ftyp)
 }
+
+func TestFtypBrands(t *testing.T) {
+	ftyp := NewFtyp("cmfc", 0, []string{"dash", "iso6"})
+	testCases := []struct {
+		brand      string
+		has        bool
+		compatible bool
+	}{
+		{"cmfc", true, false},
+		{"iso6", true, true},
+		{"dash", true, true},
+		{"isom", false, false},
+	}
+	for _, tc := range testCases {
+		if got := ftyp.HasBrand(tc.brand); got != tc.has {
+			t.Errorf("HasBrand(%q) = %t instead of %t", tc.brand, got, tc.has)
+		}
+		if got := ftyp.CompatibleWith(tc.brand); got != tc.compatible {
+			t.Errorf("CompatibleWith(%q) = %t instead of %t", tc.brand, got, tc.compatible)
+		}
+	}
+	if missing := ftyp.MissingBrands(CMAFBrands()); len(missing) != 0 {
+		t.Errorf("unexpected missing brands %v", missing)
+	}
+	missing := NewFtyp("isom", 0, []string{"iso6"}).MissingBrands(CMAFBrands())
+	if len(missing) != 1 || missing[0] != "cmfc" {
+		t.Errorf("got missing brands %v instead of [cmfc]", missing)
+	}
+	brands := CMAFBrands()
+	brands[0] = "isom"
+	if CMAFBrands()[0] != "iso6" {
+		t.Error("CMAF brands changed via returned slice")
+	}
+	if _, ok := BrandsForSampleEntry("xxxx"); ok {
+		t.Error("unknown sample entry type reported as known")
+	}
+}
+
+func TestBrandsForSampleEntry(t *testing.T) {
+	testCases := []struct {
+		sampleEntryType string
+		ftypBrand       string
+		wantedBrands    []string
+	}{
+		{"avc1", "cfhd", []string{"cfhd", "cfsd"}},
+		{"avc3", "cfsd", []string{"cfhd", "cfsd"}},
+		{"hvc1", "chh1", []string{"chh1", "chd1"}},
+		{"hev1", "chd1", []string{"chh1", "chd1"}},
+		{"mp4a", "caac", []string{"caac"}},
+		{"wvtt", "cwvt", []string{"cwvt"}},
+	}
+	for _, tc := range testCases {
+		brands, ok := BrandsForSampleEntry(tc.sampleEntryType)
+		if !ok {
+			t.Errorf("%s not known", tc.sampleEntryType)
+			continue
+		}
+		if diff := deep.Equal(brands, tc.wantedBrands); diff != nil {
+			t.Errorf("%s brands: %v", tc.sampleEntryType, diff)
+		}
+		if !NewFtyp("cmfc", 0, []string{"iso6", tc.ftypBrand}).HasAnyBrand(brands) {
+			t.Errorf("%s: ftyp with brand %s not accepted", tc.sampleEntryType, tc.ftypBrand)
+		}
+		if NewFtyp("cmfc", 0, []string{"iso6"}).HasAnyBrand(brands) {
+			t.Errorf("%s: ftyp without media profile brand accepted", tc.sampleEntryType)
+		}
+	}
+}
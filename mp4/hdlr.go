@@ -55,7 +55,7 @@ func CreateHdlr(mediaOrHdlrType string) (*HdlrBox, error) {
 }
 
 // DecodeHdlr - box-specific decode
-func DecodeHdlr(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeHdlr(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -65,7 +65,7 @@ func DecodeHdlr(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeHdlrSR - box-specific decode
-func DecodeHdlrSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeHdlrSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	versionAndFlags := sr.ReadUint32()
 	h := HdlrBox{
 		Version:     byte(versionAndFlags >> 24),
@@ -74,7 +74,7 @@ func DecodeHdlrSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, err
 		HandlerType: sr.ReadFixedLengthString(4),
 	}
 	sr.SkipBytes(12) // 12 bytes of zero
-	nrBytesLeft := hdr.payloadLen() - 24
+	nrBytesLeft := hdr.PayloadLen() - 24
 	if nrBytesLeft > 0 {
 		bytesLeft := sr.ReadBytes(nrBytesLeft)
 		lastChar := bytesLeft[len(bytesLeft)-1]
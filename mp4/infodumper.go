@@ -5,8 +5,13 @@ import (
 	"io"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
+// DumpMaxStringLen - max number of bytes of long strings, such as WebVTT cue text, written by Info.
+// Longer strings are cut and end with an ellipsis and the total size. Zero or less means no limit.
+var DumpMaxStringLen = 128
+
 type boxLike interface {
 	Type() string
 	Size() uint64
@@ -61,6 +66,23 @@ func (b *infoDumper) write(format string, p ...interface{}) {
 	_, b.err = fmt.Fprintf(b.w, format+"\n", p...)
 }
 
+// infoString - s cut to DumpMaxStringLen bytes (at a character boundary), quoted if quote is set
+func infoString(s string, quote bool) string {
+	suffix := ""
+	if DumpMaxStringLen > 0 && len(s) > DumpMaxStringLen {
+		suffix = fmt.Sprintf("... (%d bytes total)", len(s))
+		cut := DumpMaxStringLen
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		s = s[:cut]
+	}
+	if quote {
+		s = strconv.Quote(s)
+	}
+	return s + suffix
+}
+
 // getInfoLevel - get info level for specific boxLike, or from all
 func getInfoLevel(b boxLike, specificBoxLevels string) (level int) {
 	if len(specificBoxLevels) == 0 {
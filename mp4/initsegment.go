@@ -37,6 +37,11 @@ func (s *InitSegment) AddChild(b Box) {
 	s.Children = append(s.Children, b)
 }
 
+// GetChildren - top-level boxes in order
+func (s *InitSegment) GetChildren() []Box {
+	return s.Children
+}
+
 // Size - size of init segment
 func (s *InitSegment) Size() uint64 {
 	var size uint64 = 0
@@ -225,11 +230,9 @@ func (t *TrakBox) SetHEVCDescriptor(sampleDescriptorType string, vpsNALUs, spsNA
 
 // GetMediaType - should return video or audio (at present)
 func (s *InitSegment) GetMediaType() string {
-	switch s.Moov.Trak.Mdia.Hdlr.HandlerType {
-	case "soun":
-		return "audio"
-	case "vide":
-		return "video"
+	switch mediaType := s.Moov.Trak.Mdia.GetMediaType(); mediaType {
+	case "audio", "video":
+		return mediaType
 	default:
 		return "unknown"
 	}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/edgeware/mp4ff/bits"
 	"github.com/go-test/deep"
 )
 
@@ -211,3 +212,182 @@ func TestGenerateInitSegment(t *testing.T) {
 		t.Errorf("Generated init segment different from %s", goldenAssetPath)
 	}
 }
+
+// TestInitSegmentWithoutVmhd - legacy files may lack vmhd. Media type should then be inferred from hdlr.
+func TestInitSegmentWithoutVmhd(t *testing.T) {
+	f, err := parseInitFile("testdata/init1.cmfv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	minf := f.Init.Moov.Trak.Mdia.Minf
+	children := make([]Box, 0, len(minf.Children))
+	for _, c := range minf.Children {
+		if c.Type() != "vmhd" {
+			children = append(children, c)
+		}
+	}
+	minf.Children = children
+	minf.Vmhd = nil
+	buf := bytes.Buffer{}
+	err = f.Init.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mdia := dec.Init.Moov.Trak.Mdia
+	if mdia.Minf.MediaHeader() != nil {
+		t.Error("media header found in minf without one")
+	}
+	if mt := dec.Init.GetMediaType(); mt != "video" {
+		t.Errorf("got media type %q instead of video", mt)
+	}
+	expected, missing := mdia.MissingMediaHeader()
+	if !missing || expected != "vmhd" {
+		t.Errorf("got missing media header %q %t instead of vmhd true", expected, missing)
+	}
+	orig, err := parseInitFile("testdata/init1.cmfv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, missing = orig.Init.Moov.Trak.Mdia.MissingMediaHeader(); missing {
+		t.Error("vmhd reported missing in original init segment")
+	}
+}
+
+func TestProtectedSampleEntryAccessors(t *testing.T) {
+	f, err := parseInitFile("testdata/init_cenc.cmfv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	encv, ok := f.Init.Moov.Trak.Mdia.Minf.Stbl.Stsd.Children[0].(*VisualSampleEntryBox)
+	if !ok || encv.Type() != "encv" {
+		t.Fatalf("no encv sample entry")
+	}
+	if of := encv.OriginalFormat(); of != "avc3" {
+		t.Errorf("got original format %q instead of avc3", of)
+	}
+	tenc := encv.Tenc()
+	if tenc == nil {
+		t.Fatal("no tenc found")
+	}
+	if tenc.DefaultPerSampleIVSize != 8 {
+		t.Errorf("got perSampleIVSize %d instead of 8", tenc.DefaultPerSampleIVSize)
+	}
+	_, err = encv.RemoveEncryption()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encv.OriginalFormat() != "avc3" || encv.Tenc() != nil {
+		t.Error("wrong accessor values for unencrypted sample entry")
+	}
+}
+
+// TestMoovDecodeBoxOrder - by default, decoding moves traks together. With KeepBoxOrder, the original
+// order is kept so that re-encoding gives the same bytes.
+func TestMoovDecodeBoxOrder(t *testing.T) {
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	init.AddEmptyTrack(48000, "audio", "und")
+	moov := init.Moov
+	var traks, others []Box
+	for _, c := range moov.Children {
+		if c.Type() == "trak" {
+			traks = append(traks, c)
+		} else {
+			others = append(others, c)
+		}
+	}
+	// Non-canonical order: mvhd, trak, mvex, trak
+	moov.Children = []Box{others[0], traks[0], others[1], traks[1]}
+	buf := bytes.Buffer{}
+	err := moov.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encBytes := buf.Bytes()
+
+	testCases := []struct {
+		desc        string
+		keepOrder   bool
+		wantedTypes []string
+	}{
+		{"default", false, []string{"mvhd", "trak", "trak", "mvex"}},
+		{"keep box order", true, []string{"mvhd", "trak", "mvex", "trak"}},
+	}
+	for _, tc := range testCases {
+		opts := DecodeOptions{KeepBoxOrder: tc.keepOrder}
+		decBox, err := DecodeBoxWithOptions(0, bytes.NewBuffer(encBytes), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decBoxSR, err := DecodeBoxSRWithOptions(0, bits.NewFixedSliceReader(encBytes), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, b := range []Box{decBox, decBoxSR} {
+			var types []string
+			for _, c := range b.(*MoovBox).Children {
+				types = append(types, c.Type())
+			}
+			if diff := deep.Equal(types, tc.wantedTypes); diff != nil {
+				t.Errorf("%s: decoded order: %v", tc.desc, diff)
+			}
+			reBuf := bytes.Buffer{}
+			err = b.Encode(&reBuf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.keepOrder && !bytes.Equal(reBuf.Bytes(), encBytes) {
+				t.Errorf("%s: re-encoded moov differs", tc.desc)
+			}
+		}
+	}
+
+	buf.Reset()
+	err = init.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(bytes.NewBuffer(buf.Bytes()), WithKeepBoxOrder(), WithEncodeMode(EncModeBoxTree))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := bytes.Buffer{}
+	err = f.Encode(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), buf.Bytes()) {
+		t.Error("re-encoded file with kept box order differs")
+	}
+}
+
+func TestMdiaMediaType(t *testing.T) {
+	testCases := []struct {
+		handlerType    string
+		mediaType      string
+		mediaHeader    string
+		reportsMissing bool
+	}{
+		{"vide", "video", "vmhd", true},
+		{"soun", "audio", "smhd", true},
+		{"subt", "subtitle", "sthd", true},
+		{"text", "text", "nmhd", true},
+		{"sbtl", "unknown", "", false},
+	}
+	for _, tc := range testCases {
+		mdia := &MdiaBox{}
+		mdia.AddChild(&HdlrBox{HandlerType: tc.handlerType})
+		mdia.AddChild(&MinfBox{})
+		if mt := mdia.GetMediaType(); mt != tc.mediaType {
+			t.Errorf("%s: got media type %q instead of %q", tc.handlerType, mt, tc.mediaType)
+		}
+		expected, missing := mdia.MissingMediaHeader()
+		if expected != tc.mediaHeader || missing != tc.reportsMissing {
+			t.Errorf("%s: got missing media header %q %t", tc.handlerType, expected, missing)
+		}
+	}
+}
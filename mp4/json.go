@@ -0,0 +1,159 @@
+package mp4
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"unicode"
+	"unicode/utf8"
+)
+
+var boxType = reflect.TypeOf((*Box)(nil)).Elem()
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// BoxToJSON - write box tree as indented JSON to w.
+// Every box becomes an object with "type", "size", and all exported fields with their Go names
+// in lower camel case, which is also used for the fields of structs inside boxes.
+// Fields pointing to child boxes are left out, since the children are
+// in a "children" array in the order they appear in the box.
+// The media data of mdat is replaced by "dataLength".
+func BoxToJSON(b Box, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(boxToJSONValue(b))
+}
+
+// boxToJSONValue - map with type, size, exported non-box fields, and children of b
+func boxToJSONValue(b Box) map[string]interface{} {
+	m := map[string]interface{}{
+		"type": b.Type(),
+		"size": b.Size(),
+	}
+	var children []Box
+	v := reflect.ValueOf(b)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Struct {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" { // unexported
+				continue
+			}
+			fv := v.Field(i)
+			if field.Name == "Children" && field.Type == reflect.TypeOf([]Box{}) {
+				children = fv.Interface().([]Box)
+				continue
+			}
+			if isBoxJSONChild(fv) {
+				continue
+			}
+			m[jsonName(field.Name)] = jsonValue(fv)
+		}
+	}
+	switch box := b.(type) {
+	case *MdatBox:
+		delete(m, "data")
+		delete(m, "dataParts")
+		m["dataLength"] = box.payloadSize()
+	case *TrunBox:
+		m["flags"] = box.flags
+		m["sampleCount"] = box.sampleCount
+		if flags, present := box.FirstSampleFlags(); present {
+			m["firstSampleFlags"] = flags
+		}
+	}
+	if c, ok := b.(interface{ GetChildren() []Box }); ok {
+		children = c.GetChildren()
+	}
+	if len(children) > 0 {
+		jsonChildren := make([]map[string]interface{}, 0, len(children))
+		for _, c := range children {
+			jsonChildren = append(jsonChildren, boxToJSONValue(c))
+		}
+		m["children"] = jsonChildren
+	}
+	return m
+}
+
+// isBoxJSONChild - is the field value a box or a slice of boxes, which are output as children
+func isBoxJSONChild(fv reflect.Value) bool {
+	t := fv.Type()
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Implements(boxType) {
+		return true
+	}
+	if fv.Kind() == reflect.Interface && !fv.IsNil() {
+		return fv.Elem().Type().Implements(boxType)
+	}
+	return false
+}
+
+// jsonName - field name with the first rune in lower case
+func jsonName(name string) string {
+	r, n := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r)) + name[n:]
+}
+
+// jsonValue - value of a non-box field, where structs are converted to maps with names from jsonName.
+// Other values are returned as they are, unless they contain structs.
+func jsonValue(v reflect.Value) interface{} {
+	if v.Type().Implements(jsonMarshalerType) {
+		return v.Interface()
+	}
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return jsonValue(v.Elem())
+	case reflect.Struct:
+		m := make(map[string]interface{})
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			if t.Field(i).PkgPath != "" { // unexported
+				continue
+			}
+			m[jsonName(t.Field(i).Name)] = jsonValue(v.Field(i))
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		if !hasStruct(v.Type().Elem()) || (v.Kind() == reflect.Slice && v.IsNil()) {
+			return v.Interface()
+		}
+		s := make([]interface{}, v.Len())
+		for i := range s {
+			s[i] = jsonValue(v.Index(i))
+		}
+		return s
+	case reflect.Map:
+		if !hasStruct(v.Type().Elem()) {
+			return v.Interface()
+		}
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[fmt.Sprint(iter.Key().Interface())] = jsonValue(iter.Value())
+		}
+		return m
+	}
+	return v.Interface()
+}
+
+// hasStruct - can a value of type t contain a struct that is not a json.Marshaler
+func hasStruct(t reflect.Type) bool {
+	if t.Implements(jsonMarshalerType) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Interface:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return hasStruct(t.Elem())
+	}
+	return false
+}
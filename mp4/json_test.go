@@ -0,0 +1,99 @@
+package mp4
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+	"unicode"
+)
+
+func TestBoxToJSON(t *testing.T) {
+	wvtt := NewWvttBox()
+	wvtt.AddChild(&VttCBox{Config: "WEBVTT"})
+	buf := bytes.Buffer{}
+	err := BoxToJSON(wvtt, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	err = json.Unmarshal(buf.Bytes(), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got["type"] != "wvtt" || got["dataReferenceIndex"] != 1.0 || got["size"] != float64(wvtt.Size()) {
+		t.Errorf("unexpected wvtt JSON: %s", buf.String())
+	}
+	if _, ok := got["vttC"]; ok {
+		t.Error("child box pointer in JSON")
+	}
+	children := got["children"].([]interface{})
+	vttC := children[0].(map[string]interface{})
+	if len(children) != 1 || vttC["type"] != "vttC" || vttC["config"] != "WEBVTT" {
+		t.Errorf("unexpected children JSON: %v", children)
+	}
+
+	mdat := &MdatBox{Data: []byte{1, 2, 3}}
+	buf.Reset()
+	err = BoxToJSON(mdat, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = nil
+	err = json.Unmarshal(buf.Bytes(), &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["data"]; ok || got["dataLength"] != 3.0 {
+		t.Errorf("unexpected mdat JSON: %s", buf.String())
+	}
+
+	for _, testFile := range []string{"testdata/prog_8s.mp4", "testdata/init_cenc.cmfv", "testdata/moof_enc.m4s"} {
+		fd, err := os.Open(testFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := DecodeFile(fd)
+		fd.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, box := range f.Children {
+			buf.Reset()
+			err = BoxToJSON(box, &buf)
+			if err != nil {
+				t.Fatalf("%s %s: %v", testFile, box.Type(), err)
+			}
+			var v interface{}
+			err = json.Unmarshal(buf.Bytes(), &v)
+			if err != nil {
+				t.Fatalf("%s %s: invalid JSON: %v", testFile, box.Type(), err)
+			}
+			if key := upperCaseJSONKey(v); key != "" {
+				t.Errorf("%s %s: key %q does not start with lower case", testFile, box.Type(), key)
+			}
+		}
+	}
+}
+
+// upperCaseJSONKey - first object key in v which does not start with a lower-case letter
+func upperCaseJSONKey(v interface{}) string {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, e := range val {
+			if k == "" || !unicode.IsLower(rune(k[0])) {
+				return k
+			}
+			if key := upperCaseJSONKey(e); key != "" {
+				return key
+			}
+		}
+	case []interface{}:
+		for _, e := range val {
+			if key := upperCaseJSONKey(e); key != "" {
+				return key
+			}
+		}
+	}
+	return ""
+}
@@ -7,14 +7,18 @@ import (
 	"github.com/edgeware/mp4ff/bits"
 )
 
-// KindBox - Track Kind Box
+// KindBox - Track Kind Box (kind)
+// ISO/IEC 14496-12 Section 8.10.4. Contained in udta of trak.
+// For DASH roles, SchemeURI is "urn:mpeg:dash:role:2011" and Value is a role like "caption" or "subtitle".
 type KindBox struct {
+	Version   byte
+	Flags     uint32
 	SchemeURI string
 	Value     string
 }
 
 // DecodeKind - box-specific decode
-func DecodeKind(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeKind(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -24,15 +28,19 @@ func DecodeKind(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeKindSR - box-specific decode
-func DecodeKindSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
-	maxLen := hdr.payloadLen() - 1
+func DecodeKindSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	initPos := sr.GetPos()
+	versionAndFlags := sr.ReadUint32()
+	maxLen := hdr.PayloadLen() - 4 - 1
 	schemeURI := sr.ReadZeroTerminatedString(maxLen)
-	maxLen = hdr.payloadLen() - 1
+	maxLen = hdr.PayloadLen() - (sr.GetPos() - initPos)
 	value := sr.ReadZeroTerminatedString(maxLen)
 	if err := sr.AccError(); err != nil {
 		return nil, fmt.Errorf("decode kind: %w", err)
 	}
 	b := KindBox{
+		Version:   byte(versionAndFlags >> 24),
+		Flags:     versionAndFlags & flagsMask,
 		SchemeURI: schemeURI,
 		Value:     value,
 	}
@@ -46,7 +54,7 @@ func (b *KindBox) Type() string {
 
 // Size - calculated size of box
 func (b *KindBox) Size() uint64 {
-	return uint64(boxHeaderSize + len(b.SchemeURI) + 1 + len(b.Value) + 1)
+	return uint64(boxHeaderSize + 4 + len(b.SchemeURI) + 1 + len(b.Value) + 1)
 }
 
 // Encode - write box to w
@@ -66,6 +74,8 @@ func (b *KindBox) EncodeSW(sw bits.SliceWriter) error {
 	if err != nil {
 		return err
 	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
 	sw.WriteString(b.SchemeURI, true)
 	sw.WriteString(b.Value, true)
 	return sw.AccError()
@@ -73,8 +83,32 @@ func (b *KindBox) EncodeSW(sw bits.SliceWriter) error {
 
 // Info - write box-specific information
 func (b *KindBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
-	bd := newInfoDumper(w, indent, b, -1, 0)
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
 	bd.write(" - schemeURI: %s", b.SchemeURI)
 	bd.write(" - value: %s", b.Value)
 	return bd.err
 }
+
+// GetKinds - all kind boxes in the udta boxes of the track
+func (t *TrakBox) GetKinds() []*KindBox {
+	var kinds []*KindBox
+	for _, c := range t.Children {
+		if c.Type() != "udta" {
+			continue
+		}
+		for _, box := range FindBoxes(c, "kind") {
+			kinds = append(kinds, box.(*KindBox))
+		}
+	}
+	return kinds
+}
+
+// HasKind - true if the track has a kind box with schemeURI and value
+func (t *TrakBox) HasKind(schemeURI, value string) bool {
+	for _, kind := range t.GetKinds() {
+		if kind.SchemeURI == schemeURI && kind.Value == value {
+			return true
+		}
+	}
+	return false
+}
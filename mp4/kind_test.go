@@ -1,10 +1,34 @@
 package mp4
 
 import (
+	"bytes"
+	"encoding/hex"
 	"testing"
 )
 
 func TestKind(t *testing.T) {
 	kind := &KindBox{SchemeURI: "urn:mpeg:dash:role:2011", Value: "forced-subtitle"}
 	boxDiffAfterEncodeAndDecode(t, kind)
+	boxDiffAfterEncodeAndDecode(t, &KindBox{SchemeURI: "urn:mpeg:dash:role:2011"})
+
+	var buf bytes.Buffer
+	err := (&KindBox{SchemeURI: "urn:x", Value: "c"}).Encode(&buf)
+	assertNoError(t, err)
+	wanted := "00000014" + "6b696e64" + "00000000" + "75726e3a7800" + "6300"
+	if got := hex.EncodeToString(buf.Bytes()); got != wanted {
+		t.Errorf("got %s instead of %s", got, wanted)
+	}
+}
+
+func TestTrakKinds(t *testing.T) {
+	trak := &TrakBox{}
+	udta := &UdtaBox{}
+	udta.AddChild(&KindBox{SchemeURI: "urn:mpeg:dash:role:2011", Value: "caption"})
+	trak.AddChild(udta)
+	if len(trak.GetKinds()) != 1 {
+		t.Fatalf("got %d kinds", len(trak.GetKinds()))
+	}
+	if !trak.HasKind("urn:mpeg:dash:role:2011", "caption") || trak.HasKind("urn:mpeg:dash:role:2011", "subtitle") {
+		t.Error("wrong kind")
+	}
 }
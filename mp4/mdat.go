@@ -23,7 +23,7 @@ type MdatBox struct {
 const maxNormalPayloadSize = (1 << 32) - 1 - 8
 
 // DecodeMdat - box-specific decode
-func DecodeMdat(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeMdat(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -33,9 +33,15 @@ func DecodeMdat(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeMdatSR - box-specific decode
-func DecodeMdatSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeMdatSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	largeSize := hdr.hdrlen > boxHeaderSize
-	return &MdatBox{startPos, sr.ReadBytes(hdr.payloadLen()), nil, 0, largeSize}, nil
+	return &MdatBox{startPos, sr.ReadBytes(hdr.PayloadLen()), nil, 0, largeSize}, nil
+}
+
+// SetStartPos - set the absolute position of the mdat box, which is the base for PayloadAbsoluteOffset.
+// This is needed to find sample data via trun data offsets in a fragment built in memory.
+func (m *MdatBox) SetStartPos(pos uint64) {
+	m.StartPos = pos
 }
 
 // IsLazy - is the mdat data handled lazily (with separate writer/reader).
@@ -44,7 +50,7 @@ func (m *MdatBox) IsLazy() bool {
 }
 
 // DecodeMdatLazily - box-specific decode but Data is not in memory
-func DecodeMdatLazily(hdr boxHeader, startPos uint64) (Box, error) {
+func DecodeMdatLazily(hdr BoxHeader, startPos uint64) (Box, error) {
 	largeSize := hdr.hdrlen > boxHeaderSize
 	decLazyDataSize := hdr.size - uint64(hdr.hdrlen)
 	return &MdatBox{startPos, nil, nil, decLazyDataSize, largeSize}, nil
@@ -66,21 +72,22 @@ func (m *MdatBox) Type() string {
 	return "mdat"
 }
 
-// Size - return calculated size, depending on largeSize set or not
+// Size - return calculated size, depending on largeSize set or not.
+// LargeSize is set automatically if the payload is too big for a 32-bit size field.
 func (m *MdatBox) Size() uint64 {
-	dataSize := m.DataLength()
-
-	if m.lazyDataSize > 0 {
-		dataSize = m.lazyDataSize
-	}
-	if dataSize > maxNormalPayloadSize {
+	hdrSize := m.HeaderSize()
+	if hdrSize > boxHeaderSize {
 		m.LargeSize = true
 	}
-	size := boxHeaderSize + dataSize
-	if m.LargeSize {
-		size += 8
+	return hdrSize + m.payloadSize()
+}
+
+// payloadSize - size of payload, either lazy or data in memory
+func (m *MdatBox) payloadSize() uint64 {
+	if m.lazyDataSize > 0 {
+		return m.lazyDataSize
 	}
-	return size
+	return m.DataLength()
 }
 
 // AddSampleData -  a sample data to an mdat box
@@ -154,16 +161,49 @@ func (m *MdatBox) DataLength() uint64 {
 	return uint64(dataLength)
 }
 
+// dataRange - bytes [offset, offset+size) of the mdat payload in memory, stored either as one or multiple parts.
+// A range inside one slice is returned without copying, while a range over several parts is copied.
+func (m *MdatBox) dataRange(offset, size uint64) ([]byte, error) {
+	end := offset + size
+	if end > m.DataLength() {
+		return nil, fmt.Errorf("range %d-%d beyond mdat data length %d", offset, end, m.DataLength())
+	}
+	if len(m.DataParts) == 0 {
+		return m.Data[offset:end], nil
+	}
+	var data []byte
+	var partStart uint64
+	for _, part := range m.DataParts {
+		partEnd := partStart + uint64(len(part))
+		if partStart < end && offset < partEnd {
+			if partStart <= offset && end <= partEnd {
+				return part[offset-partStart : end-partStart], nil
+			}
+			from, to := uint64(0), uint64(len(part))
+			if offset > partStart {
+				from = offset - partStart
+			}
+			if end < partEnd {
+				to = end - partStart
+			}
+			data = append(data, part[from:to]...)
+		}
+		partStart = partEnd
+	}
+	return data, nil
+}
+
 // Info - write box-specific information
 func (m *MdatBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
 	bd := newInfoDumper(w, indent, m, -1, 0)
 	return bd.err
 }
 
-// HeaderSize - 8 or 16 (bytes) depending o whether largeSize is used
+// HeaderSize - 8 or 16 (bytes) depending on whether largeSize is used.
+// largeSize is used if set explicitly or if the payload is bigger than 0xFFFFFFFF - 8.
 func (m *MdatBox) HeaderSize() uint64 {
 	hSize := boxHeaderSize
-	if m.LargeSize {
+	if m.LargeSize || m.payloadSize() > maxNormalPayloadSize {
 		hSize += largeSizeLen
 	}
 	return uint64(hSize)
@@ -190,8 +230,8 @@ func (m *MdatBox) ReadData(start, size int64, rs io.ReadSeeker) ([]byte, error)
 		}
 
 		buf := make([]byte, size)
-		n, err := rs.Read(buf)
-		if err != nil {
+		n, err := io.ReadFull(rs, buf)
+		if err != nil && err != io.ErrUnexpectedEOF {
 			return nil, err
 		}
 		if int64(n) != size {
@@ -207,7 +247,7 @@ func (m *MdatBox) ReadData(start, size int64, rs io.ReadSeeker) ([]byte, error)
 
 	// validate if indexes are valid to avoid panics
 	dataLen := m.DataLength()
-	if offsetInMdatData >= dataLen || endIndexInMdatData >= dataLen {
+	if offsetInMdatData >= dataLen || endIndexInMdatData > dataLen {
 		return nil, fmt.Errorf("normal mdat mode - invalid range provided")
 	}
 	if len(m.DataParts) > 0 {
@@ -240,7 +280,7 @@ func (m *MdatBox) CopyData(start, size int64, rs io.ReadSeeker, w io.Writer) (nr
 
 	// validate if indexes are valid to avoid panics
 	dataLen := m.DataLength()
-	if offsetInMdatData >= dataLen || endIndexInMdatData >= dataLen {
+	if offsetInMdatData >= dataLen || endIndexInMdatData > dataLen {
 		return 0, fmt.Errorf("normal mdat mode - invalid range provided")
 	}
 	if len(m.DataParts) > 0 {
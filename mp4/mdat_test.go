@@ -220,3 +220,56 @@ func TestAddParts(t *testing.T) {
 		t.Errorf("expected %v, got %v", outBufExp.Bytes(), outBuf.Bytes())
 	}
 }
+
+// TestMdatAutomaticLargeSize - payload bigger than 32-bit size limit gives 16-byte header
+func TestMdatAutomaticLargeSize(t *testing.T) {
+	mdat := &MdatBox{StartPos: 1000}
+	mdat.SetLazyDataSize(1 << 32)
+	if mdat.HeaderSize() != 16 {
+		t.Errorf("header size is %d instead of 16", mdat.HeaderSize())
+	}
+	if mdat.PayloadAbsoluteOffset() != 1016 {
+		t.Errorf("payload offset is %d instead of 1016", mdat.PayloadAbsoluteOffset())
+	}
+	expectedSize := uint64(1<<32) + 16
+	if mdat.Size() != expectedSize {
+		t.Errorf("mdat size is %d instead of %d", mdat.Size(), expectedSize)
+	}
+	if !mdat.LargeSize {
+		t.Error("LargeSize not set")
+	}
+	buf := bytes.Buffer{}
+	err := mdat.Encode(&buf) // Lazy mode, so only header is written
+	if err != nil {
+		t.Error(err)
+	}
+	expectedHdr := []byte{0, 0, 0, 1, 'm', 'd', 'a', 't', 0, 0, 0, 1, 0, 0, 0, 16}
+	if !bytes.Equal(buf.Bytes(), expectedHdr) {
+		t.Errorf("got header %v instead of %v", buf.Bytes(), expectedHdr)
+	}
+
+	mdat.SetLazyDataSize(maxNormalPayloadSize)
+	mdat.LargeSize = false
+	if mdat.HeaderSize() != 8 {
+		t.Errorf("header size is %d instead of 8", mdat.HeaderSize())
+	}
+}
+
+func TestReadDataLargeSizeToEnd(t *testing.T) {
+	mdat := &MdatBox{
+		StartPos:  100,
+		Data:      []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06},
+		LargeSize: true,
+	}
+	data, err := mdat.ReadData(116, 7, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	if !bytes.Equal(data, mdat.Data) {
+		t.Errorf("expected %v, got %v", mdat.Data, data)
+	}
+	_, err = mdat.ReadData(117, 7, nil)
+	if err == nil {
+		t.Error("no error for range beyond end of mdat")
+	}
+}
@@ -38,8 +38,62 @@ func (m *MdiaBox) AddChild(box Box) {
 	m.Children = append(m.Children, box)
 }
 
+// GetMediaType - media type inferred from the hdlr handler type:
+// "video", "audio", "subtitle", "text", or "unknown".
+// The media header box in minf is not needed, so files missing vmhd or smhd are handled.
+func (m *MdiaBox) GetMediaType() string {
+	if m.Hdlr == nil {
+		return "unknown"
+	}
+	switch m.Hdlr.HandlerType {
+	case "vide":
+		return "video"
+	case "soun":
+		return "audio"
+	case "subt":
+		return "subtitle"
+	case "text":
+		return "text"
+	default:
+		return "unknown"
+	}
+}
+
+// GetLanguage - language tag of the track, taken from elng if present, and otherwise from mdhd.
+// An empty string is returned if there is neither.
+func (m *MdiaBox) GetLanguage() string {
+	if m.Elng != nil {
+		return m.Elng.Language
+	}
+	if m.Mdhd != nil {
+		return m.Mdhd.GetLanguage()
+	}
+	return ""
+}
+
+// MissingMediaHeader - report the media header box expected from the hdlr handler type, if it is missing in minf.
+// This is to be used as a warning for lenient parsing of legacy files.
+func (m *MdiaBox) MissingMediaHeader() (expected string, missing bool) {
+	switch m.GetMediaType() {
+	case "video":
+		expected = "vmhd"
+	case "audio":
+		expected = "smhd"
+	case "subtitle":
+		expected = "sthd"
+	case "text":
+		expected = "nmhd"
+	default:
+		return "", false
+	}
+	if m.Minf == nil || m.Minf.MediaHeader() == nil {
+		return expected, true
+	}
+	return expected, false
+}
+
 // DecodeMdia - box-specific decode
-func DecodeMdia(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeMdia(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	l, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.size, r)
 	if err != nil {
 		return nil, err
@@ -52,7 +106,7 @@ func DecodeMdia(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeMdiaSR - box-specific decode
-func DecodeMdiaSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeMdiaSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.size, sr)
 	if err != nil {
 		return nil, err
@@ -1,6 +1,7 @@
 package mp4
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/edgeware/mp4ff/bits"
@@ -37,6 +38,63 @@ func (s *MediaSegment) AddFragment(f *Fragment) {
 	s.Fragments = append(s.Fragments, f)
 }
 
+// ConcatFragments - combine fragments into one media segment with a shared styp.
+// The styp of the first fragment is used if present, otherwise a CMAF styp is created,
+// and styp boxes are removed from all fragments. Other boxes like prft are kept.
+// The mfhd sequence numbers are set to startSeq, startSeq+1, ..., and the sample data of
+// every fragment is repacked in traf and trun order with data offsets relative to moof,
+// so the sample data must be in memory.
+// All fragments are checked before any of them is changed.
+// The fragments are then modified and become part of the returned segment.
+func ConcatFragments(frags []*Fragment, startSeq uint32) (*MediaSegment, error) {
+	if len(frags) == 0 {
+		return nil, fmt.Errorf("no fragments")
+	}
+	fragsData := make([][]byte, 0, len(frags))
+	for i, frag := range frags {
+		if frag.Moof == nil || frag.Mdat == nil {
+			return nil, fmt.Errorf("fragment %d lacks moof or mdat", i+1)
+		}
+		data, err := frag.trafsSampleData(frag.Moof.Trafs)
+		if err != nil {
+			return nil, fmt.Errorf("fragment %d: %w", i+1, err)
+		}
+		fragsData = append(fragsData, data)
+	}
+	seg := NewMediaSegmentWithoutStyp()
+	seg.Styp = frags[0].Styp
+	if seg.Styp == nil {
+		seg.Styp = CreateStyp()
+	}
+	pos := seg.Styp.Size()
+	for i, frag := range frags {
+		frag.setRepackedSampleData(frag.Moof.Trafs, fragsData[i])
+		if frag.Styp != nil {
+			children := make([]Box, 0, len(frag.Children)-1)
+			for _, c := range frag.Children {
+				if c != frag.Styp {
+					children = append(children, c)
+				}
+			}
+			frag.Children = children
+			frag.Styp = nil
+		}
+		frag.Moof.Mfhd.SequenceNumber = startSeq + uint32(i)
+		moofPos := pos
+		for _, c := range frag.Children {
+			if c == frag.Moof {
+				break
+			}
+			moofPos += c.Size()
+		}
+		frag.inMemoryPositions = true
+		frag.SetMoofStartPos(moofPos) // Also sets trun data offsets and mdat position
+		pos += frag.Size()
+		seg.AddFragment(frag)
+	}
+	return seg, nil
+}
+
 // LastFragment - Currently last fragment
 func (s *MediaSegment) LastFragment() *Fragment {
 	return s.Fragments[len(s.Fragments)-1]
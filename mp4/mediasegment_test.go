@@ -218,3 +218,96 @@ func TestDecodeEncodeCencFragmentedFile(t *testing.T) {
 		t.Errorf("generated bytes differ from input")
 	}
 }
+
+func TestConcatFragments(t *testing.T) {
+	// First fragment has styp, prft, and two tracks
+	fragA, err := CreateMultiTrackFragment(7, []uint32{1, 2})
+	assertNoError(t, err)
+	for i := 0; i < 3; i++ {
+		for _, trackID := range []uint32{1, 2} {
+			data := []byte{byte(trackID), byte(i), 0xaa}
+			fs := FullSample{Sample: NewSample(SyncSampleFlags, 10, uint32(len(data)), 0),
+				DecodeTime: uint64(10 * i), Data: data}
+			assertNoError(t, fragA.AddFullSampleToTrack(fs, trackID))
+		}
+	}
+	styp := NewStyp("cmfs", 0, []string{"cmfs", "cmf2"})
+	prft := &PrftBox{Version: 1, NTPTimestamp: 1 << 40, MediaTime: 0}
+	fragA.Children = append([]Box{styp, prft}, fragA.Children...)
+	fragA.Styp, fragA.Prft = styp, prft
+
+	// Second fragment is decoded, so it has real positions
+	fragB, err := CreateFragment(9, 1)
+	assertNoError(t, err)
+	for i := 0; i < 2; i++ {
+		fragB.AddFullSample(FullSample{Sample: NewSample(SyncSampleFlags, 10, 2, 0),
+			DecodeTime: uint64(30 + 10*i), Data: []byte{0xbb, byte(i)}})
+	}
+	buf := bytes.Buffer{}
+	assertNoError(t, fragB.Encode(&buf))
+	f, err := DecodeFile(&buf)
+	assertNoError(t, err)
+	fragB = f.Segments[0].Fragments[0]
+
+	trexs := []*TrexBox{{TrackID: 1}, {TrackID: 2}}
+	var wanted [][]FullSample
+	for _, trex := range trexs {
+		var samples []FullSample
+		for _, frag := range []*Fragment{fragA, fragB} {
+			s, err := frag.GetFullSamples(trex)
+			assertNoError(t, err)
+			samples = append(samples, s...)
+		}
+		wanted = append(wanted, samples)
+	}
+
+	seg, err := ConcatFragments([]*Fragment{fragA, fragB}, 100)
+	assertNoError(t, err)
+	if seg.Styp != styp || fragA.Styp != nil {
+		t.Errorf("styp of first fragment not moved to segment")
+	}
+	buf.Reset()
+	assertNoError(t, seg.Encode(&buf))
+	f, err = DecodeFile(&buf)
+	assertNoError(t, err)
+	if len(f.Segments) != 1 || len(f.Segments[0].Fragments) != 2 {
+		t.Fatalf("decoded segment does not have 2 fragments")
+	}
+	decFrags := f.Segments[0].Fragments
+	for i, frag := range decFrags {
+		if frag.Moof.Mfhd.SequenceNumber != uint32(100+i) {
+			t.Errorf("fragment %d: got sequence number %d", i+1, frag.Moof.Mfhd.SequenceNumber)
+		}
+	}
+	if decFrags[0].Prft == nil || decFrags[0].Prft.NTPTimestamp != prft.NTPTimestamp {
+		t.Errorf("prft not kept in first fragment")
+	}
+	for i, trex := range trexs {
+		var samples []FullSample
+		for _, frag := range decFrags {
+			s, err := frag.GetFullSamples(trex)
+			assertNoError(t, err)
+			samples = append(samples, s...)
+		}
+		if diff := deep.Equal(samples, wanted[i]); diff != nil {
+			t.Errorf("track %d: %v", trex.TrackID, diff)
+		}
+	}
+
+	// No fragment is changed if a later fragment has no sample data in memory
+	fragC, err := CreateFragment(1, 1)
+	assertNoError(t, err)
+	fragC.AddChild(CreateStyp())
+	fragC.AddFullSample(FullSample{Sample: NewSample(SyncSampleFlags, 10, 2, 0), Data: []byte{1, 2}})
+	lazyFrag, err := CreateFragment(2, 1)
+	assertNoError(t, err)
+	lazyFrag.Mdat.SetLazyDataSize(2)
+	lazyFrag.AddSample(NewSample(SyncSampleFlags, 10, 2, 0), 10)
+	_, err = ConcatFragments([]*Fragment{fragC, lazyFrag}, 1)
+	if err == nil {
+		t.Error("no error for fragment with lazy mdat")
+	}
+	if fragC.Styp == nil || fragC.Moof.Mfhd.SequenceNumber != 1 || fragC.Moof.Traf.Trun.DataOffset != 0 {
+		t.Error("first fragment changed after error")
+	}
+}
@@ -16,7 +16,7 @@ type MfhdBox struct {
 }
 
 // DecodeMfhd - box-specific decode
-func DecodeMfhd(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeMfhd(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -34,7 +34,7 @@ func DecodeMfhd(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeMfhdSR - box-specific decode
-func DecodeMfhdSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeMfhdSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	versionAndFlags := sr.ReadUint32()
 	version := byte(versionAndFlags >> 24)
 	flags := versionAndFlags & flagsMask
@@ -1,7 +1,9 @@
 package mp4
 
 import (
+	"fmt"
 	"io"
+	"math"
 
 	"github.com/edgeware/mp4ff/bits"
 )
@@ -17,7 +19,7 @@ type MfraBox struct {
 }
 
 // DecodeMfra - box-specific decode
-func DecodeMfra(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeMfra(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	children, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.size, r)
 	if err != nil {
 		return nil, err
@@ -34,7 +36,7 @@ func DecodeMfra(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeMfraSR - box-specific decode
-func DecodeMfraSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeMfraSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.size, sr)
 	if err != nil {
 		return nil, err
@@ -94,3 +96,91 @@ func (m *MfraBox) GetChildren() []Box {
 func (m *MfraBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
 	return ContainerInfo(m, w, specificBoxLevels, indent, indentStep)
 }
+
+// CreateMfra - create mfra with one tfra per track and an entry for every sync sample in frags.
+// moofOffsets[i] is the file offset of the moof box of frags[i].
+// The entry time is the presentation time of the sync sample. Sample values are taken from the
+// trun or from the tfhd defaults, so sample flags must be in one of them.
+// The number fields get the smallest size that fits, and version 1 is used if time or offset
+// does not fit in 32 bits. The mfro box at the end gets the size of the mfra box.
+// An error is returned if there is not one moof offset per fragment. The fragments are not changed.
+func CreateMfra(frags []*Fragment, moofOffsets []uint64) (*MfraBox, error) {
+	if len(moofOffsets) != len(frags) {
+		return nil, fmt.Errorf("%d moof offsets for %d fragments", len(moofOffsets), len(frags))
+	}
+	mfra := &MfraBox{}
+	tfras := make(map[uint32]*TfraBox)
+	var trackIDs []uint32
+	for i, frag := range frags {
+		if frag.Moof == nil {
+			continue
+		}
+		for trafIdx, traf := range frag.Moof.Trafs {
+			trackID := traf.Tfhd.TrackID
+			tfra, ok := tfras[trackID]
+			if !ok {
+				tfra = &TfraBox{TrackID: trackID}
+				tfras[trackID] = tfra
+				trackIDs = append(trackIDs, trackID)
+			}
+			var decodeTime uint64
+			if traf.Tfdt != nil {
+				decodeTime = traf.Tfdt.BaseMediaDecodeTime
+			}
+			for trunIdx, trun := range traf.Truns {
+				filled, _ := trun.withDefaultValues(traf.Tfhd, nil)
+				for sampleIdx, s := range filled.Samples {
+					if s.IsSync() {
+						fs := FullSample{Sample: s, DecodeTime: decodeTime}
+						e := TfraEntry{
+							Time:        int64(fs.PresentationTime()),
+							MoofOffset:  int64(moofOffsets[i]),
+							TrafNumber:  uint32(trafIdx + 1),
+							TrunNumber:  uint32(trunIdx + 1),
+							SampleDelta: uint32(sampleIdx + 1),
+						}
+						if e.Time > math.MaxInt32 || e.MoofOffset > math.MaxInt32 {
+							tfra.Version = 1
+						}
+						tfra.Entries = append(tfra.Entries, e)
+					}
+					decodeTime += uint64(s.Dur)
+				}
+			}
+		}
+	}
+	for _, trackID := range trackIDs {
+		tfra := tfras[trackID]
+		for _, e := range tfra.Entries {
+			tfra.LengthSizeOfTrafNum = maxByte(tfra.LengthSizeOfTrafNum, tfraLengthSize(e.TrafNumber))
+			tfra.LengthSizeOfTrunNum = maxByte(tfra.LengthSizeOfTrunNum, tfraLengthSize(e.TrunNumber))
+			tfra.LengthSizeOfSampleNum = maxByte(tfra.LengthSizeOfSampleNum, tfraLengthSize(e.SampleDelta))
+		}
+		_ = mfra.AddChild(tfra)
+	}
+	mfro := &MfroBox{}
+	_ = mfra.AddChild(mfro)
+	mfro.ParentSize = uint32(mfra.Size())
+	return mfra, nil
+}
+
+// tfraLengthSize - length_size field (number of bytes minus one) needed for nr
+func tfraLengthSize(nr uint32) byte {
+	switch {
+	case nr <= 0xff:
+		return 0
+	case nr <= 0xffff:
+		return 1
+	case nr <= 0xffffff:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func maxByte(a, b byte) byte {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -1,6 +1,10 @@
 package mp4
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
 
 func TestMfra(t *testing.T) {
 	mfra := &MfraBox{}
@@ -31,3 +35,71 @@ func TestMfra(t *testing.T) {
 	}
 	boxDiffAfterEncodeAndDecode(t, mfra)
 }
+
+func TestTfraFieldSizes(t *testing.T) {
+	maxValues := []uint32{0xff, 0xffff, 0xffffff, 0xffffffff}
+	for version := byte(0); version <= 1; version++ {
+		for trafSize := byte(0); trafSize < 4; trafSize++ {
+			for trunSize := byte(0); trunSize < 4; trunSize++ {
+				for sampleSize := byte(0); sampleSize < 4; sampleSize++ {
+					tfra := &TfraBox{
+						Version:               version,
+						TrackID:               2,
+						LengthSizeOfTrafNum:   trafSize,
+						LengthSizeOfTrunNum:   trunSize,
+						LengthSizeOfSampleNum: sampleSize,
+						Entries: []TfraEntry{
+							{Time: 1000, MoofOffset: 2000, TrafNumber: 1, TrunNumber: 1, SampleDelta: 1},
+							{Time: 3000, MoofOffset: 4000, TrafNumber: maxValues[trafSize],
+								TrunNumber: maxValues[trunSize], SampleDelta: maxValues[sampleSize]},
+						},
+					}
+					boxDiffAfterEncodeAndDecode(t, tfra)
+				}
+			}
+		}
+	}
+}
+
+func TestCreateMfra(t *testing.T) {
+	var frags []*Fragment
+	var moofOffsets []uint64
+	offset := uint64(1000)
+	for i := 0; i < 2; i++ {
+		frag, err := CreateFragment(uint32(i+1), 1)
+		assertNoError(t, err)
+		for j := 0; j < 4; j++ {
+			flags := NonSyncSampleFlags
+			if j%2 == 0 {
+				flags = SyncSampleFlags
+			}
+			frag.AddFullSample(FullSample{Sample: NewSample(flags, 1000, 10, 500),
+				DecodeTime: uint64(4000*i + 1000*j), Data: make([]byte, 10)})
+		}
+		frags = append(frags, frag)
+		moofOffsets = append(moofOffsets, offset)
+		offset += frag.Size()
+	}
+	if _, err := CreateMfra(frags, moofOffsets[:1]); err == nil {
+		t.Error("no error for missing moof offset")
+	}
+	mfra, err := CreateMfra(frags, moofOffsets)
+	assertNoError(t, err)
+	if len(mfra.Tfras) != 1 || mfra.Mfro == nil {
+		t.Fatalf("got %d tfras and mfro %v", len(mfra.Tfras), mfra.Mfro)
+	}
+	var wanted []TfraEntry
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 4; j += 2 {
+			wanted = append(wanted, TfraEntry{Time: int64(4000*i + 1000*j + 500), MoofOffset: int64(moofOffsets[i]),
+				TrafNumber: 1, TrunNumber: 1, SampleDelta: uint32(j + 1)})
+		}
+	}
+	if diff := deep.Equal(mfra.Tfra.Entries, wanted); diff != nil {
+		t.Error(diff)
+	}
+	if mfra.Mfro.ParentSize != uint32(mfra.Size()) {
+		t.Errorf("mfro parent size %d instead of %d", mfra.Mfro.ParentSize, mfra.Size())
+	}
+	boxDiffAfterEncodeAndDecode(t, mfra)
+}
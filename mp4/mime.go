@@ -15,7 +15,7 @@ type MimeBox struct {
 }
 
 // DecodeMime - box-specific decode
-func DecodeMime(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeMime(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -25,13 +25,13 @@ func DecodeMime(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeMimeSR - box-specific decode
-func DecodeMimeSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeMimeSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	versionAndFlags := sr.ReadUint32()
 	b := MimeBox{
 		Version: byte(versionAndFlags >> 24),
 		Flags:   versionAndFlags & flagsMask,
 	}
-	rest := sr.ReadBytes(hdr.payloadLen() - 4)
+	rest := sr.ReadBytes(hdr.PayloadLen() - 4)
 	if rest[len(rest)-1] == 0 { // zero-termination
 		b.ContentType = string(rest[:len(rest)-1])
 	} else {
@@ -14,6 +14,7 @@ type MinfBox struct {
 	Vmhd     *VmhdBox
 	Smhd     *SmhdBox
 	Sthd     *SthdBox
+	Nmhd     *NmhdBox
 	Dinf     *DinfBox
 	Stbl     *StblBox
 	Children []Box
@@ -34,6 +35,8 @@ func (m *MinfBox) AddChild(box Box) {
 		m.Smhd = box.(*SmhdBox)
 	case "sthd":
 		m.Sthd = box.(*SthdBox)
+	case "nmhd":
+		m.Nmhd = box.(*NmhdBox)
 	case "dinf":
 		m.Dinf = box.(*DinfBox)
 	case "stbl":
@@ -43,7 +46,7 @@ func (m *MinfBox) AddChild(box Box) {
 }
 
 // DecodeMinf - box-specific decode
-func DecodeMinf(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeMinf(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	children, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.size, r)
 	if err != nil {
 		return nil, err
@@ -56,7 +59,7 @@ func DecodeMinf(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeMinfSR - box-specific decode
-func DecodeMinfSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeMinfSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.size, sr)
 	if err != nil {
 		return nil, err
@@ -78,6 +81,22 @@ func (m *MinfBox) Size() uint64 {
 	return containerSize(m.Children)
 }
 
+// MediaHeader - the media header box (vmhd, smhd, sthd, or nmhd) or nil if there is none.
+// Some legacy files lack a media header box, so its presence is not required when decoding.
+func (m *MinfBox) MediaHeader() Box {
+	switch {
+	case m.Vmhd != nil:
+		return m.Vmhd
+	case m.Smhd != nil:
+		return m.Smhd
+	case m.Sthd != nil:
+		return m.Sthd
+	case m.Nmhd != nil:
+		return m.Nmhd
+	}
+	return nil
+}
+
 // GetChildren - list of child boxes
 func (m *MinfBox) GetChildren() []Box {
 	return m.Children
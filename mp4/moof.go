@@ -21,8 +21,8 @@ type MoofBox struct {
 }
 
 // DecodeMoof - box-specific decode
-func DecodeMoof(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
-	data := make([]byte, hdr.payloadLen())
+func DecodeMoof(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data := make([]byte, hdr.PayloadLen())
 	_, err := r.Read(data)
 	if err != nil {
 		return nil, err
@@ -45,7 +45,7 @@ func DecodeMoof(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeMoofSR - box-specific decode
-func DecodeMoofSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeMoofSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.size, sr)
 	if err != nil {
 		return nil, err
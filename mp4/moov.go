@@ -1,6 +1,7 @@
 package mp4
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/edgeware/mp4ff/bits"
@@ -25,8 +26,14 @@ func NewMoovBox() *MoovBox {
 	return &MoovBox{}
 }
 
-// AddChild - Add a child box
+// AddChild - Add a child box.
+// A trak box is put after the last previous trak box to keep the traks together.
 func (m *MoovBox) AddChild(box Box) {
+	m.addChild(box, true)
+}
+
+// addChild - add child box. keepTraksTogether == false preserves the order in which boxes are added.
+func (m *MoovBox) addChild(box Box, keepTraksTogether bool) {
 	switch box.Type() {
 	case "mvhd":
 		m.Mvhd = box.(*MvhdBox)
@@ -44,7 +51,7 @@ func (m *MoovBox) AddChild(box Box) {
 				lastTrakIdx = i
 			}
 		}
-		if lastTrakIdx != 0 && lastTrakIdx != len(m.Children)-1 { // last one in middle
+		if keepTraksTogether && lastTrakIdx != 0 && lastTrakIdx != len(m.Children)-1 { // last one in middle
 			m.Children = append(m.Children[:lastTrakIdx+2], m.Children[lastTrakIdx+1:]...)
 			m.Children[lastTrakIdx+1] = trak
 			return
@@ -62,8 +69,8 @@ func (m *MoovBox) AddChild(box Box) {
 }
 
 // DecodeMoov - box-specific decode
-func DecodeMoov(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
-	data := make([]byte, hdr.payloadLen())
+func DecodeMoov(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data := make([]byte, hdr.PayloadLen())
 	_, err := r.Read(data)
 	if err != nil {
 		return nil, err
@@ -76,13 +83,17 @@ func DecodeMoov(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 	m := MoovBox{Children: make([]Box, 0, len(children))}
 	m.StartPos = startPos
 	for _, c := range children {
-		m.AddChild(c)
+		m.addChild(c, !hdr.opts.keepBoxOrder())
+	}
+	moov, err := decompressMoov(&m)
+	if err != nil {
+		return nil, err
 	}
-	return &m, err
+	return moov, nil
 }
 
 // DecodeMoovSR - box-specific decode
-func DecodeMoovSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeMoovSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.size, sr)
 	if err != nil {
 		return nil, err
@@ -90,9 +101,13 @@ func DecodeMoovSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, err
 	m := MoovBox{Children: make([]Box, 0, len(children))}
 	m.StartPos = startPos
 	for _, c := range children {
-		m.AddChild(c)
+		m.addChild(c, !hdr.opts.keepBoxOrder())
 	}
-	return &m, err
+	moov, err := decompressMoov(&m)
+	if err != nil {
+		return nil, err
+	}
+	return moov, nil
 }
 
 // Type - box type
@@ -125,6 +140,36 @@ func (m *MoovBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string
 	return ContainerInfo(m, w, specificBoxLevels, indent, indentStep)
 }
 
+// RemoveTrack - remove trak and the trex and trep boxes of trackID. Error if no trak has trackID.
+func (m *MoovBox) RemoveTrack(trackID uint32) error {
+	var traks []*TrakBox
+	for _, trak := range m.Traks {
+		if trak.Tkhd.TrackID != trackID {
+			traks = append(traks, trak)
+		}
+	}
+	if len(traks) == len(m.Traks) {
+		return fmt.Errorf("no trak with trackID %d", trackID)
+	}
+	newChildren := make([]Box, 0, len(m.Children)-1)
+	for _, c := range m.Children {
+		if trak, ok := c.(*TrakBox); ok && trak.Tkhd.TrackID == trackID {
+			continue
+		}
+		newChildren = append(newChildren, c)
+	}
+	m.Children = newChildren
+	m.Traks = traks
+	m.Trak = nil
+	if len(traks) > 0 {
+		m.Trak = traks[0]
+	}
+	if m.Mvex != nil {
+		m.Mvex.removeTrack(trackID)
+	}
+	return nil
+}
+
 // RemovePsshs - remove and return all psshs children boxes
 func (m *MoovBox) RemovePsshs() []*PsshBox {
 	if m.Pssh == nil {
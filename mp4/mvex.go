@@ -15,6 +15,8 @@ type MvexBox struct {
 	Mehd     *MehdBox
 	Trex     *TrexBox
 	Trexs    []*TrexBox
+	Trep     *TrepBox
+	Treps    []*TrepBox
 	Children []Box
 }
 
@@ -34,12 +36,17 @@ func (m *MvexBox) AddChild(box Box) {
 			m.Trex = box.(*TrexBox)
 		}
 		m.Trexs = append(m.Trexs, box.(*TrexBox))
+	case "trep":
+		if m.Trep == nil {
+			m.Trep = box.(*TrepBox)
+		}
+		m.Treps = append(m.Treps, box.(*TrepBox))
 	}
 	m.Children = append(m.Children, box)
 }
 
 // DecodeMvex - box-specific decode
-func DecodeMvex(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeMvex(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	children, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.size, r)
 	if err != nil {
 		return nil, err
@@ -52,7 +59,7 @@ func DecodeMvex(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeMvex - box-specific decode
-func DecodeMvexSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeMvexSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.size, sr)
 	if err != nil {
 		return nil, err
@@ -103,3 +110,32 @@ func (m *MvexBox) GetTrex(trackID uint32) (trex *TrexBox, ok bool) {
 	}
 	return nil, true
 }
+
+// GetTrep - get trep box for trackID
+func (m *MvexBox) GetTrep(trackID uint32) (trep *TrepBox, ok bool) {
+	for _, trep := range m.Treps {
+		if trep.TrackID == trackID {
+			return trep, true
+		}
+	}
+	return nil, false
+}
+
+// removeTrack - remove trex and trep boxes for trackID
+func (m *MvexBox) removeTrack(trackID uint32) {
+	oldChildren := m.Children
+	*m = MvexBox{}
+	for _, c := range oldChildren {
+		switch box := c.(type) {
+		case *TrexBox:
+			if box.TrackID == trackID {
+				continue
+			}
+		case *TrepBox:
+			if box.TrackID == trackID {
+				continue
+			}
+		}
+		m.AddChild(c)
+	}
+}
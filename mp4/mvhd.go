@@ -38,7 +38,7 @@ func CreateMvhd() *MvhdBox {
 }
 
 // DecodeMvhd - box-specific decode
-func DecodeMvhd(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeMvhd(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -48,7 +48,7 @@ func DecodeMvhd(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeMvhdSR - box-specific decode
-func DecodeMvhdSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeMvhdSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	versionAndFlags := sr.ReadUint32()
 	version := byte(versionAndFlags >> 24)
 
@@ -1,6 +1,8 @@
 package mp4
 
 import (
+	"encoding/hex"
+	"fmt"
 	"io"
 
 	"github.com/edgeware/mp4ff/bits"
@@ -8,12 +10,13 @@ import (
 
 // NmhdBox - Null Media Header Box (nmhd - often used instead of sthd for subtitle tracks)
 type NmhdBox struct {
-	Version byte
-	Flags   uint32
+	Version  byte
+	Flags    uint32
+	Reserved []byte // Any bytes after the FullBox header, re-emitted verbatim
 }
 
 // DecodeNmhd - box-specific decode
-func DecodeNmhd(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeNmhd(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -23,13 +26,19 @@ func DecodeNmhd(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeNmhdSR - box-specific decode
-func DecodeNmhdSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeNmhdSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	if hdr.PayloadLen() < 4 {
+		return nil, fmt.Errorf("nmhd payload size %d is less than 4", hdr.PayloadLen())
+	}
 
 	versionAndFlags := sr.ReadUint32()
 	sb := &NmhdBox{
 		Version: byte(versionAndFlags >> 24),
 		Flags:   versionAndFlags & flagsMask,
 	}
+	if nrReserved := int(hdr.size) - int(hdr.hdrlen) - 4; nrReserved > 0 {
+		sb.Reserved = sr.ReadBytes(nrReserved)
+	}
 	return sb, sr.AccError()
 }
 
@@ -40,7 +49,7 @@ func (b *NmhdBox) Type() string {
 
 // Size - calculated size of box
 func (b *NmhdBox) Size() uint64 {
-	return boxHeaderSize + 4 // FullBox
+	return boxHeaderSize + 4 + uint64(len(b.Reserved)) // FullBox + reserved
 }
 
 // Encode - write box to w
@@ -62,11 +71,15 @@ func (b *NmhdBox) EncodeSW(sw bits.SliceWriter) error {
 	}
 	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
 	sw.WriteUint32(versionAndFlags)
+	sw.WriteBytes(b.Reserved)
 	return sw.AccError()
 }
 
 // Info - write box-specific information
 func (b *NmhdBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
 	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	if len(b.Reserved) > 0 {
+		bd.write(" - reserved: %s", hex.EncodeToString(b.Reserved))
+	}
 	return bd.err
 }
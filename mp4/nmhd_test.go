@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/edgeware/mp4ff/bits"
 	"github.com/go-test/deep"
 )
 
@@ -26,3 +27,38 @@ func TestNmhd(t *testing.T) {
 		t.Error(diff)
 	}
 }
+
+// TestNmhdReserved - bytes after the FullBox header should be kept verbatim
+func TestNmhdReserved(t *testing.T) {
+	encBox := &NmhdBox{Reserved: []byte{0, 1}}
+	boxDiffAfterEncodeAndDecode(t, encBox)
+}
+
+func TestNmhdInfo(t *testing.T) {
+	buf := bytes.Buffer{}
+	err := (&NmhdBox{}).Info(&buf, "", "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted := "[nmhd] size=12 version=0 flags=000000\n"
+	if buf.String() != wanted {
+		t.Errorf("got %q instead of %q", buf.String(), wanted)
+	}
+	buf.Reset()
+	err = (&NmhdBox{Reserved: []byte{0, 1}}).Info(&buf, "", "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted = "[nmhd] size=14 version=0 flags=000000\n - reserved: 0001\n"
+	if buf.String() != wanted {
+		t.Errorf("got %q instead of %q", buf.String(), wanted)
+	}
+}
+
+func TestNmhdTooSmall(t *testing.T) {
+	data := []byte{0, 0, 0, 8, 'n', 'm', 'h', 'd', 0, 0, 0, 8, 'f', 'r', 'e', 'e'}
+	_, err := DecodeBoxSR(0, bits.NewFixedSliceReader(data))
+	if err == nil {
+		t.Error("no error for nmhd without version and flags")
+	}
+}
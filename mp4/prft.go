@@ -2,6 +2,8 @@ package mp4
 
 import (
 	"io"
+	"math"
+	"time"
 
 	"github.com/edgeware/mp4ff/bits"
 )
@@ -10,12 +12,16 @@ import (
 //
 // Contained in File before moof box
 type PrftBox struct {
-	Version      byte
-	Flags        uint32
-	NTPTimestamp uint64
-	MediaTime    uint64
+	Version          byte
+	Flags            uint32
+	ReferenceTrackID uint32
+	NTPTimestamp     uint64
+	MediaTime        uint64
 }
 
+// ntpUnixEpochOffset - seconds from NTP epoch 1900-01-01 to Unix epoch 1970-01-01
+const ntpUnixEpochOffset = 2208988800
+
 // CreatePrftBox - Create a new PrftBox
 func CreatePrftBox(version byte, ntp uint64, mediatime uint64) *PrftBox {
 	return &PrftBox{
@@ -26,8 +32,38 @@ func CreatePrftBox(version byte, ntp uint64, mediatime uint64) *PrftBox {
 	}
 }
 
+// CreatePrft - create a PrftBox for trackID with ntp timestamp and mediaTime in track timescale.
+// Version 1 is used if mediaTime does not fit in 32 bits.
+func CreatePrft(trackID uint32, ntp uint64, mediaTime uint64) *PrftBox {
+	var version byte
+	if mediaTime > math.MaxUint32 {
+		version = 1
+	}
+	return &PrftBox{
+		Version:          version,
+		ReferenceTrackID: trackID,
+		NTPTimestamp:     ntp,
+		MediaTime:        mediaTime,
+	}
+}
+
+// NtpToTime - convert 64-bit NTP timestamp (seconds since 1900 and 32-bit fraction) to time in UTC.
+// The fraction is rounded to nanoseconds.
+func NtpToTime(ntp uint64) time.Time {
+	secs := int64(ntp>>32) - ntpUnixEpochOffset
+	nanos := ((ntp&0xffffffff)*1e9 + 1<<31) >> 32
+	return time.Unix(secs, int64(nanos)).UTC()
+}
+
+// TimeToNtp - convert t to 64-bit NTP timestamp (seconds since 1900 and 32-bit fraction)
+func TimeToNtp(t time.Time) uint64 {
+	secs := uint64(t.Unix() + ntpUnixEpochOffset)
+	frac := (uint64(t.Nanosecond())<<32 + 5e8) / 1e9
+	return secs<<32 | frac
+}
+
 // DecodePrft - box-specific decode
-func DecodePrft(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodePrft(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -37,10 +73,11 @@ func DecodePrft(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodePrftSR - box-specific decode
-func DecodePrftSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodePrftSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	versionAndFlags := sr.ReadUint32()
 	version := byte(versionAndFlags >> 24)
 	flags := versionAndFlags & flagsMask
+	trackID := sr.ReadUint32()
 	ntp := sr.ReadUint64()
 	var mediatime uint64
 	if version == 0 {
@@ -50,10 +87,11 @@ func DecodePrftSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, err
 	}
 
 	p := PrftBox{
-		Version:      version,
-		Flags:        flags,
-		NTPTimestamp: ntp,
-		MediaTime:    mediatime,
+		Version:          version,
+		Flags:            flags,
+		ReferenceTrackID: trackID,
+		NTPTimestamp:     ntp,
+		MediaTime:        mediatime,
 	}
 	return &p, sr.AccError()
 }
@@ -65,7 +103,7 @@ func (b *PrftBox) Type() string {
 
 // Size - return calculated size
 func (b *PrftBox) Size() uint64 {
-	return uint64(boxHeaderSize + 16 + 4*int(b.Version))
+	return uint64(boxHeaderSize + 20 + 4*int(b.Version))
 }
 
 // Encode - write box to w
@@ -87,6 +125,7 @@ func (b *PrftBox) EncodeSW(sw bits.SliceWriter) error {
 	}
 	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
 	sw.WriteUint32(versionAndFlags)
+	sw.WriteUint32(b.ReferenceTrackID)
 	sw.WriteUint64(b.NTPTimestamp)
 	if b.Version == 0 {
 		sw.WriteUint32(uint32(b.MediaTime))
@@ -99,7 +138,8 @@ func (b *PrftBox) EncodeSW(sw bits.SliceWriter) error {
 // Info - write box-specific information
 func (b *PrftBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
 	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
-	bd.write(" - ntpTimestamp: %d", b.NTPTimestamp)
+	bd.write(" - referenceTrackID: %d", b.ReferenceTrackID)
+	bd.write(" - ntpTimestamp: %d (%s)", b.NTPTimestamp, NtpToTime(b.NTPTimestamp).Format(time.RFC3339Nano))
 	bd.write(" - mediaTime: %d", b.MediaTime)
 	return bd.err
 }
@@ -1,14 +1,60 @@
 package mp4
 
-import "testing"
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+	"time"
+)
 
 func TestPrft(t *testing.T) {
 	prfts := []*PrftBox{
 		CreatePrftBox(0, 8998, 98),
 		CreatePrftBox(1, 8998, 98),
+		CreatePrft(2, 8998, 98),
+		CreatePrft(2, 8998, 1<<33),
 	}
 	for _, prft := range prfts {
 		boxDiffAfterEncodeAndDecode(t, prft)
 	}
+	if prfts[2].Version != 0 || prfts[3].Version != 1 {
+		t.Errorf("got versions %d and %d", prfts[2].Version, prfts[3].Version)
+	}
+	buf := bytes.Buffer{}
+	err := CreatePrft(2, 0x0102030405060708, 0x0a0b0c0d).Encode(&buf)
+	assertNoError(t, err)
+	wanted := "0000001c7072667400000000000000020102030405060708" + "0a0b0c0d"
+	if got := hex.EncodeToString(buf.Bytes()); got != wanted {
+		t.Errorf("got %s instead of %s", got, wanted)
+	}
+}
 
+func TestNtpConversion(t *testing.T) {
+	unixEpoch := uint64(ntpUnixEpochOffset) << 32
+	if got := NtpToTime(unixEpoch); !got.Equal(time.Unix(0, 0)) {
+		t.Errorf("got %s for Unix epoch", got)
+	}
+	if got := NtpToTime(unixEpoch + 1<<31); !got.Equal(time.Unix(0, 5e8)) {
+		t.Errorf("got %s for half a second after Unix epoch", got)
+	}
+	if got := TimeToNtp(time.Unix(0, 25e7)); got != unixEpoch+1<<30 {
+		t.Errorf("got %x for a quarter second after Unix epoch", got)
+	}
+	times := []time.Time{
+		time.Date(2021, 3, 4, 5, 6, 7, 123456789, time.UTC),
+		time.Date(1999, 12, 31, 23, 59, 59, 999999999, time.UTC),
+		time.Date(1970, 1, 1, 0, 0, 0, 1, time.UTC),
+	}
+	for _, tm := range times {
+		if got := NtpToTime(TimeToNtp(tm)); !got.Equal(tm) {
+			t.Errorf("got %s after round trip of %s", got, tm)
+		}
+	}
+	for _, ntp := range []uint64{0xe3e8f2a1_12345678, unixEpoch + 0xffffffff} {
+		got := TimeToNtp(NtpToTime(ntp))
+		diff := int64(got - ntp)
+		if diff < -3 || diff > 3 { // One nanosecond is about 4.3 fraction units
+			t.Errorf("got %x after round trip of %x", got, ntp)
+		}
+	}
 }
@@ -0,0 +1,64 @@
+package mp4
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func uuidFromHex(t *testing.T, hexStr string) UUID {
+	t.Helper()
+	u, err := hex.DecodeString(hexStr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return UUID(u)
+}
+
+func TestPssh(t *testing.T) {
+	widevine := uuidFromHex(t, "edef8ba979d64acea3c827dcd51d21ed")
+	playReady := uuidFromHex(t, "9a04f07998404286ab92e65be0885f95")
+	kid1 := uuidFromHex(t, "00112233445566778899aabbccddeeff")
+	kid2 := uuidFromHex(t, "ffeeddccbbaa99887766554433221100")
+
+	v0 := &PsshBox{Version: 0, SystemID: widevine, Data: []byte{0x08, 0x01, 0x12, 0x10}}
+	boxDiffAfterEncodeAndDecode(t, v0)
+	v1 := &PsshBox{Version: 1, SystemID: playReady, KIDs: []UUID{kid1, kid2}, Data: []byte{0xab, 0xcd}}
+	boxDiffAfterEncodeAndDecode(t, v1)
+	if v1.Size() != 12+16+4+2*16+4+2 {
+		t.Errorf("got size %d for version 1 pssh", v1.Size())
+	}
+
+	moov := NewMoovBox()
+	moov.AddChild(v0)
+	moof := &MoofBox{}
+	moof.AddChild(&MfhdBox{SequenceNumber: 1})
+	moof.AddChild(v1)
+	frag := NewFragment()
+	frag.AddChild(moof)
+	seg := NewMediaSegment()
+	seg.AddFragment(frag)
+	f := NewFile()
+	f.Moov = moov
+	f.AddMediaSegment(seg)
+	psshs := f.GetPsshs()
+	if len(psshs) != 2 || psshs[0] != v0 || psshs[1] != v1 {
+		t.Errorf("got %d psshs, not v0 and v1 in order", len(psshs))
+	}
+	if !bytes.Equal(psshs[1].KIDs[1], kid2) {
+		t.Errorf("got KID %s instead of %s", psshs[1].KIDs[1], kid2)
+	}
+}
+
+func TestTenc(t *testing.T) {
+	kid := uuidFromHex(t, "00112233445566778899aabbccddeeff")
+	cenc := &TencBox{Version: 0, DefaultIsProtected: 1, DefaultPerSampleIVSize: 16, DefaultKID: kid}
+	boxDiffAfterEncodeAndDecode(t, cenc)
+	cbcs := &TencBox{Version: 1, DefaultCryptByteBlock: 1, DefaultSkipByteBlock: 9, DefaultIsProtected: 1,
+		DefaultPerSampleIVSize: 0, DefaultKID: kid,
+		DefaultConstantIV: []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}}
+	boxDiffAfterEncodeAndDecode(t, cbcs)
+	if cbcs.Size() != 32+1+16 {
+		t.Errorf("got size %d for cbcs tenc", cbcs.Size())
+	}
+}
@@ -0,0 +1,98 @@
+package mp4
+
+import (
+	"fmt"
+	"sort"
+)
+
+// RemapTrackIDs - change track IDs in all boxes below and including root according to mapping.
+// root can be any box, *File, or *Fragment. The fields updated are
+// tkhd, tfhd, trex, trep, tfra and prft track IDs, sidx reference ID and the track IDs in tref.
+// mvhd NextTrackID is increased if needed to stay above all track IDs.
+// An error is returned, without changing anything, if two track IDs would be mapped to the same
+// value, or if a target ID is already used by a track that is not remapped.
+func RemapTrackIDs(root interface{}, mapping map[uint32]uint32) error {
+	boxes := collectBoxes(root)
+
+	existing := make(map[uint32]bool)
+	for _, b := range boxes {
+		switch box := b.(type) {
+		case *TkhdBox:
+			existing[box.TrackID] = true
+		case *TrexBox:
+			existing[box.TrackID] = true
+		case *TfhdBox:
+			existing[box.TrackID] = true
+		}
+	}
+	sources := make([]uint32, 0, len(mapping))
+	for src := range mapping {
+		sources = append(sources, src)
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i] < sources[j] })
+	targets := make(map[uint32]uint32)
+	for _, src := range sources {
+		dst := mapping[src]
+		if prev, ok := targets[dst]; ok {
+			return fmt.Errorf("track IDs %d and %d both mapped to %d", prev, src, dst)
+		}
+		targets[dst] = src
+		if _, remapped := mapping[dst]; existing[dst] && !remapped {
+			return fmt.Errorf("track ID %d mapped to %d which is already in use", src, dst)
+		}
+	}
+
+	remap := func(id uint32) uint32 {
+		if newID, ok := mapping[id]; ok {
+			return newID
+		}
+		return id
+	}
+	var maxTrackID uint32
+	var mvhds []*MvhdBox
+	for _, b := range boxes {
+		switch box := b.(type) {
+		case *TkhdBox:
+			box.TrackID = remap(box.TrackID)
+			if box.TrackID > maxTrackID {
+				maxTrackID = box.TrackID
+			}
+		case *TfhdBox:
+			box.TrackID = remap(box.TrackID)
+		case *TrexBox:
+			box.TrackID = remap(box.TrackID)
+		case *TrepBox:
+			box.TrackID = remap(box.TrackID)
+		case *TfraBox:
+			box.TrackID = remap(box.TrackID)
+		case *PrftBox:
+			box.ReferenceTrackID = remap(box.ReferenceTrackID)
+		case *SidxBox:
+			box.ReferenceID = remap(box.ReferenceID)
+		case *TrefTypeBox:
+			for i, id := range box.TrackIDs {
+				box.TrackIDs[i] = remap(id)
+			}
+		case *MvhdBox:
+			mvhds = append(mvhds, box)
+		}
+	}
+	for _, mvhd := range mvhds {
+		if mvhd.NextTrackID <= maxTrackID {
+			mvhd.NextTrackID = maxTrackID + 1
+		}
+	}
+	return nil
+}
+
+// collectBoxes - root (if it is a box) and all boxes below it in depth-first order
+func collectBoxes(root interface{}) []Box {
+	var boxes []Box
+	if b, ok := root.(Box); ok {
+		boxes = append(boxes, b)
+	}
+	for _, child := range getChildren(root) {
+		boxes = append(boxes, collectBoxes(child)...)
+	}
+	return boxes
+}
@@ -0,0 +1,67 @@
+package mp4
+
+import (
+	"testing"
+)
+
+func TestRemapTrackIDs(t *testing.T) {
+	videoInit := CreateEmptyInit()
+	videoInit.AddEmptyTrack(90000, "video", "und")
+	audioInit := CreateEmptyInit()
+	audioInit.AddEmptyTrack(48000, "audio", "en")
+	tref := &TrefBox{}
+	tref.AddChild(&TrefTypeBox{Name: "sync", TrackIDs: []uint32{1}})
+	audioInit.Moov.Trak.AddChild(tref)
+
+	err := RemapTrackIDs(audioInit, map[uint32]uint32{1: 1, 3: 1})
+	if err == nil {
+		t.Error("no error for two tracks mapped to the same ID")
+	}
+	err = RemapTrackIDs(audioInit, map[uint32]uint32{2: 1})
+	if err == nil {
+		t.Error("no error for mapping to an ID already in use")
+	}
+
+	err = RemapTrackIDs(audioInit, map[uint32]uint32{1: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	audioTrak := audioInit.Moov.Trak
+	videoInit.Moov.AddChild(audioTrak)
+	videoInit.Moov.Mvex.AddChild(audioInit.Moov.Mvex.Trex)
+
+	checkUnique := func(boxType string, trackID func(Box) uint32) {
+		t.Helper()
+		ids := make(map[uint32]bool)
+		for _, b := range FindBoxes(videoInit, boxType) {
+			id := trackID(b)
+			if ids[id] {
+				t.Errorf("duplicate %s track ID %d", boxType, id)
+			}
+			ids[id] = true
+		}
+		if len(ids) != 2 {
+			t.Errorf("got %d %s track IDs instead of 2", len(ids), boxType)
+		}
+	}
+	checkUnique("tkhd", func(b Box) uint32 { return b.(*TkhdBox).TrackID })
+	checkUnique("trex", func(b Box) uint32 { return b.(*TrexBox).TrackID })
+	if got := tref.Children[0].(*TrefTypeBox).TrackIDs[0]; got != 2 {
+		t.Errorf("tref track ID %d instead of 2", got)
+	}
+	if got := audioInit.Moov.Mvhd.NextTrackID; got != 3 {
+		t.Errorf("mvhd NextTrackID %d instead of 3", got)
+	}
+
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = RemapTrackIDs(frag, map[uint32]uint32{1: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := frag.Moof.Traf.Tfhd.TrackID; got != 2 {
+		t.Errorf("tfhd track ID %d instead of 2", got)
+	}
+}
@@ -1,6 +1,10 @@
 package mp4
 
-import "encoding/binary"
+import (
+	"encoding/binary"
+	"math"
+	mathbits "math/bits"
+)
 
 // Sample - sample as used in trun box (mdhd timescale)
 type Sample struct {
@@ -26,6 +30,57 @@ func (s *Sample) IsSync() bool {
 	return !decFlags.SampleIsNonSync && (decFlags.SampleDependsOn == 2)
 }
 
+// SetSync - set flags to sync (dependsOn=2 and not non-sync) or non-sync (dependsOn=1 and non-sync)
+func (s *Sample) SetSync(isSync bool) {
+	if isSync {
+		s.SetDependsOn(2)
+		s.Flags &^= 1 << 16
+		return
+	}
+	s.SetDependsOn(1)
+	s.Flags |= 1 << 16
+}
+
+// DependsOn - sample_depends_on (0=unknown, 1=depends on others, 2=does not depend on others)
+func (s *Sample) DependsOn() uint8 {
+	return uint8((s.Flags >> 24) & 0x3)
+}
+
+// SetDependsOn - set sample_depends_on (2 bits)
+func (s *Sample) SetDependsOn(dependsOn uint8) {
+	s.Flags = s.Flags&^(0x3<<24) | uint32(dependsOn&0x3)<<24
+}
+
+// IsDependedOn - sample_is_depended_on (0=unknown, 1=other samples depend on this, 2=disposable)
+func (s *Sample) IsDependedOn() uint8 {
+	return uint8((s.Flags >> 22) & 0x3)
+}
+
+// SetIsDependedOn - set sample_is_depended_on (2 bits)
+func (s *Sample) SetIsDependedOn(isDependedOn uint8) {
+	s.Flags = s.Flags&^(0x3<<22) | uint32(isDependedOn&0x3)<<22
+}
+
+// HasRedundancy - sample_has_redundancy (0=unknown, 1=redundant coding, 2=no redundant coding)
+func (s *Sample) HasRedundancy() uint8 {
+	return uint8((s.Flags >> 20) & 0x3)
+}
+
+// SetHasRedundancy - set sample_has_redundancy (2 bits)
+func (s *Sample) SetHasRedundancy(hasRedundancy uint8) {
+	s.Flags = s.Flags&^(0x3<<20) | uint32(hasRedundancy&0x3)<<20
+}
+
+// DegradationPriority - sample_degradation_priority (lowest 16 bits)
+func (s *Sample) DegradationPriority() uint16 {
+	return uint16(s.Flags & 0xffff)
+}
+
+// SetDegradationPriority - set sample_degradation_priority
+func (s *Sample) SetDegradationPriority(priority uint16) {
+	s.Flags = s.Flags&^0xffff | uint32(priority)
+}
+
 // FullSample - include accumulated time and data. Times in mdhd timescale
 type FullSample struct {
 	Sample
@@ -34,12 +89,70 @@ type FullSample struct {
 }
 
 // PresentationTime - DecodeTime displaced by composition time offset (possibly negative)
+// Without composition time offset, this is the same as DecodeTime.
 func (s *FullSample) PresentationTime() uint64 {
-	p := int64(s.DecodeTime) + int64(s.CompositionTimeOffset)
-	if p < 0 {
-		p = 0 // Extraordinary case. Clip it to 0.
+	cto := int64(s.CompositionTimeOffset)
+	if cto >= 0 {
+		return s.DecodeTime + uint64(cto)
+	}
+	if uint64(-cto) > s.DecodeTime {
+		return 0 // Extraordinary case. Clip it to 0.
+	}
+	return s.DecodeTime - uint64(-cto)
+}
+
+// RescaleTo - convert DecodeTime, Dur, and CompositionTimeOffset from one timescale to another.
+// Start and end times are rescaled with RescaleTime, and the duration is their difference,
+// so consecutive samples stay contiguous without accumulating rounding drift.
+// Durations and offsets outside the range of their fields are clamped.
+func (s *FullSample) RescaleTo(fromTimescale, toTimescale uint32) {
+	start := RescaleTime(s.DecodeTime, fromTimescale, toTimescale)
+	end := RescaleTime(s.DecodeTime+uint64(s.Dur), fromTimescale, toTimescale)
+	dur := end - start
+	if dur > math.MaxUint32 {
+		dur = math.MaxUint32
+	}
+	cto := int64(s.CompositionTimeOffset)
+	var newCto uint64
+	if cto >= 0 {
+		newCto = RescaleTime(uint64(cto), fromTimescale, toTimescale)
+	} else {
+		newCto = RescaleTime(uint64(-cto), fromTimescale, toTimescale)
+	}
+	if newCto > math.MaxInt32 {
+		newCto = math.MaxInt32
+	}
+	s.DecodeTime = start
+	s.Dur = uint32(dur)
+	if cto >= 0 {
+		s.CompositionTimeOffset = int32(newCto)
+	} else {
+		s.CompositionTimeOffset = -int32(newCto)
+	}
+}
+
+// RescaleTime - convert time t from fromTimescale to toTimescale.
+// The product t*toTimescale is calculated with 128-bit precision, so it cannot overflow,
+// and the result is rounded to nearest with ties to even (round-half-to-even).
+// A result that does not fit in 64 bits is clamped to math.MaxUint64.
+// fromTimescale must not be zero.
+func RescaleTime(t uint64, fromTimescale, toTimescale uint32) uint64 {
+	if fromTimescale == toTimescale {
+		return t
+	}
+	from := uint64(fromTimescale)
+	hi, lo := mathbits.Mul64(t, uint64(toTimescale))
+	if hi >= from {
+		return math.MaxUint64
+	}
+	q, r := mathbits.Div64(hi, lo, from)
+	if r > from-r || (r == from-r && q%2 == 1) {
+		if q == math.MaxUint64 {
+			return q
+		}
+		q++
 	}
-	return uint64(p)
+	return q
 }
 
 func toAnnexB(videoSample []byte) {
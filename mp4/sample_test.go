@@ -0,0 +1,108 @@
+package mp4
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPresentationTime(t *testing.T) {
+	testCases := []struct {
+		decodeTime uint64
+		cto        int32
+		wantedPTS  uint64
+	}{
+		{0, 0, 0},
+		{1 << 40, 0, 1 << 40},
+		{math.MaxUint64 - 10, 0, math.MaxUint64 - 10},
+		{1000, 512, 1512},
+		{1000, -512, 488},
+		{1000, -1000, 0},
+		{1000, -1001, 0},
+		{1 << 32, math.MaxInt32, 1<<32 + math.MaxInt32},
+		{1 << 32, math.MinInt32, 1<<32 - 1<<31},
+		{1 << 30, math.MinInt32, 0},
+		{math.MaxInt64 + 1, math.MinInt32, math.MaxInt64 + 1 - 1<<31},
+	}
+	for _, tc := range testCases {
+		fs := FullSample{Sample: Sample{CompositionTimeOffset: tc.cto}, DecodeTime: tc.decodeTime}
+		gotPTS := fs.PresentationTime()
+		if gotPTS != tc.wantedPTS {
+			t.Errorf("decodeTime=%d cto=%d: got %d instead of %d", tc.decodeTime, tc.cto, gotPTS, tc.wantedPTS)
+		}
+	}
+}
+
+func TestRescaleTime(t *testing.T) {
+	testCases := []struct {
+		t      uint64
+		from   uint32
+		to     uint32
+		wanted uint64
+	}{
+		{1234, 1000, 90000, 111060},
+		{111060, 90000, 1000, 1234},
+		{45, 90000, 1000, 0},                   // 0.5 rounds to even 0
+		{135, 90000, 1000, 2},                  // 1.5 rounds to even 2
+		{136, 90000, 1000, 2},                  // above half rounds up
+		{1 << 62, 1000, 90000, math.MaxUint64}, // result too big
+		{math.MaxUint64, 90000, 1000, 204963823041217240},
+		{math.MaxUint64 / 90, 1000, 90000, (math.MaxUint64 / 90) * 90},
+		{7, 48000, 48000, 7},
+	}
+	for _, tc := range testCases {
+		got := RescaleTime(tc.t, tc.from, tc.to)
+		if got != tc.wanted {
+			t.Errorf("RescaleTime(%d, %d, %d): got %d instead of %d", tc.t, tc.from, tc.to, got, tc.wanted)
+		}
+	}
+}
+
+func TestFullSampleRescaleTo(t *testing.T) {
+	// Three 1/3 s samples in timescale 3 become 333, 334, 333 ms without drift
+	var decodeTime uint64
+	var rescaled []FullSample
+	for i := 0; i < 3; i++ {
+		fs := FullSample{Sample: Sample{Dur: 1, CompositionTimeOffset: -1}, DecodeTime: decodeTime + 3}
+		decodeTime += 1
+		fs.RescaleTo(3, 1000)
+		rescaled = append(rescaled, fs)
+	}
+	wantedDurs := []uint32{333, 334, 333}
+	for i, fs := range rescaled {
+		if fs.Dur != wantedDurs[i] {
+			t.Errorf("sample %d: got dur %d instead of %d", i+1, fs.Dur, wantedDurs[i])
+		}
+		if fs.CompositionTimeOffset != -333 {
+			t.Errorf("sample %d: got cto %d instead of -333", i+1, fs.CompositionTimeOffset)
+		}
+		if i > 0 && rescaled[i-1].DecodeTime+uint64(rescaled[i-1].Dur) != fs.DecodeTime {
+			t.Errorf("sample %d: not contiguous with previous", i+1)
+		}
+	}
+	if rescaled[2].DecodeTime+uint64(rescaled[2].Dur) != 2000 {
+		t.Errorf("got end time %d instead of 2000", rescaled[2].DecodeTime+uint64(rescaled[2].Dur))
+	}
+}
+
+func TestSampleFlagAccessors(t *testing.T) {
+	s := Sample{}
+	s.SetSync(true)
+	if !s.IsSync() || s.Flags != SyncSampleFlags {
+		t.Errorf("got flags %08x for sync sample", s.Flags)
+	}
+	s.SetSync(false)
+	if s.IsSync() || s.DependsOn() != 1 || !DecodeSampleFlags(s.Flags).SampleIsNonSync {
+		t.Errorf("got flags %08x for non-sync sample", s.Flags)
+	}
+	s.SetIsDependedOn(2)
+	s.SetHasRedundancy(1)
+	s.SetDegradationPriority(0x1234)
+	wanted := SampleFlags{SampleDependsOn: 1, SampleIsDependedOn: 2, SampleHasRedundancy: 1,
+		SampleIsNonSync: true, SampleDegradationPriority: 0x1234}
+	if s.Flags != wanted.Encode() {
+		t.Errorf("got flags %08x instead of %08x", s.Flags, wanted.Encode())
+	}
+	if s.IsDependedOn() != 2 || s.HasRedundancy() != 1 || s.DegradationPriority() != 0x1234 {
+		t.Errorf("accessors do not match flags %08x", s.Flags)
+	}
+}
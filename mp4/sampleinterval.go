@@ -4,7 +4,7 @@ package mp4
 type SampleInterval struct {
 	FirstDecodeTime uint64
 	Samples         []Sample
-	OffsetInMdat    uint32 // Offset relative start of mdat box
+	OffsetInMdat    uint64 // Offset relative start of mdat box
 	Size            uint32 // total size of all samples in interval
 	Data            []byte // If set, should be relevant mdat range
 }
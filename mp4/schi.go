@@ -22,7 +22,7 @@ func (b *SchiBox) AddChild(box Box) {
 }
 
 // DecodeSchi - box-specific decode
-func DecodeSchi(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeSchi(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	children, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.size, r)
 	if err != nil {
 		return nil, err
@@ -35,7 +35,7 @@ func DecodeSchi(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeSchiSR - box-specific decode
-func DecodeSchiSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeSchiSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.size, sr)
 	if err != nil {
 		return nil, err
@@ -16,7 +16,7 @@ type SchmBox struct {
 }
 
 // DecodeSchm - box-specific decode
-func DecodeSchm(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeSchm(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -26,7 +26,7 @@ func DecodeSchm(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeSchmSR - box-specific decode
-func DecodeSchmSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeSchmSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	versionAndFlags := sr.ReadUint32()
 	version := byte(versionAndFlags >> 24)
 
@@ -37,7 +37,7 @@ func DecodeSchmSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, err
 	b.SchemeType = sr.ReadFixedLengthString(4)
 	b.SchemeVersion = sr.ReadUint32()
 	if b.Flags&0x01 != 0 {
-		b.SchemeURI = sr.ReadZeroTerminatedString(hdr.payloadLen())
+		b.SchemeURI = sr.ReadZeroTerminatedString(hdr.PayloadLen())
 	}
 	return &b, sr.AccError()
 }
@@ -1,6 +1,7 @@
 package mp4
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/edgeware/mp4ff/bits"
@@ -25,7 +26,15 @@ type SdtpEntry uint8
 
 // NewSdtpEntry - make new SdtpEntry from 2-bit parameters
 func NewSdtpEntry(isLeading, sampleDependsOn, sampleDependedOn, hasRedundancy uint8) SdtpEntry {
-	return SdtpEntry(isLeading<<6 | sampleDependedOn<<4 | sampleDependedOn<<2 | hasRedundancy)
+	return SdtpEntry(isLeading<<6 | sampleDependsOn<<4 | sampleDependedOn<<2 | hasRedundancy)
+}
+
+// SampleDependency - the four 2-bit values of an SdtpEntry
+type SampleDependency struct {
+	IsLeading          uint8
+	SampleDependsOn    uint8
+	SampleIsDependedOn uint8
+	HasRedundancy      uint8
 }
 
 // IsLeading (bits 0-1)
@@ -71,8 +80,32 @@ func CreateSdtpBox(entries []SdtpEntry) *SdtpBox {
 	}
 }
 
+// CreateSdtpFromSamples - create SdtpBox with entries from the dependency bits of the sample flags,
+// which have the same layout as the sdtp entries
+func CreateSdtpFromSamples(samples []Sample) *SdtpBox {
+	entries := make([]SdtpEntry, len(samples))
+	for i, s := range samples {
+		entries[i] = SdtpEntry(s.Flags >> 20)
+	}
+	return CreateSdtpBox(entries)
+}
+
+// Dependencies - the entries as SampleDependency values
+func (b *SdtpBox) Dependencies() []SampleDependency {
+	deps := make([]SampleDependency, len(b.Entries))
+	for i, e := range b.Entries {
+		deps[i] = SampleDependency{
+			IsLeading:          e.IsLeading(),
+			SampleDependsOn:    e.SampleDependsOn(),
+			SampleIsDependedOn: e.SampleIsDependedOn(),
+			HasRedundancy:      e.SampleHasRedundancy(),
+		}
+	}
+	return deps
+}
+
 // DecodeSdtp - box-specific decode
-func DecodeSdtp(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeSdtp(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -82,13 +115,16 @@ func DecodeSdtp(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeSdtpSR - box-specific decode
-func DecodeSdtpSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeSdtpSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	if hdr.PayloadLen() < 4 {
+		return nil, fmt.Errorf("sdtp: payload size %d less than 4", hdr.PayloadLen())
+	}
 	versionAndFlags := sr.ReadUint32()
 	version := byte(versionAndFlags >> 24)
 	flags := versionAndFlags & flagsMask
 
 	// Supposed to get count from stsz. Use rest of payload
-	entries := make([]SdtpEntry, hdr.payloadLen()-4)
+	entries := make([]SdtpEntry, hdr.PayloadLen()-4)
 	for i := range entries {
 		entries[i] = SdtpEntry(sr.ReadUint8())
 	}
@@ -2,6 +2,8 @@ package mp4
 
 import (
 	"testing"
+
+	"github.com/go-test/deep"
 )
 
 func TestSdtp(t *testing.T) {
@@ -12,4 +14,32 @@ func TestSdtp(t *testing.T) {
 	}
 
 	boxDiffAfterEncodeAndDecode(t, CreateSdtpBox(entries))
+	if deps := CreateSdtpBox(entries).Dependencies(); deps[1].SampleDependsOn != 1 || deps[1].SampleIsDependedOn != 2 {
+		t.Errorf("got dependency %+v", deps[1])
+	}
+}
+
+func TestCreateSdtpFromSamples(t *testing.T) {
+	samples := []Sample{
+		NewSample(SyncSampleFlags, 1000, 10, 0),
+		NewSample(NonSyncSampleFlags, 1000, 10, 0),
+		NewSample(NonSyncSampleFlags, 1000, 10, 0),
+		NewSample(NonSyncSampleFlags, 1000, 10, 0),
+	}
+	samples[1].SetIsDependedOn(1)
+	samples[2].SetDependsOn(1)
+	samples[2].SetIsDependedOn(2)
+	samples[3].SetHasRedundancy(2)
+	samples[3].Flags |= 3 << 26 // isLeading
+	sdtp := CreateSdtpFromSamples(samples)
+	wanted := []SampleDependency{
+		{IsLeading: 0, SampleDependsOn: 2, SampleIsDependedOn: 0, HasRedundancy: 0},
+		{IsLeading: 0, SampleDependsOn: 0, SampleIsDependedOn: 1, HasRedundancy: 0},
+		{IsLeading: 0, SampleDependsOn: 1, SampleIsDependedOn: 2, HasRedundancy: 0},
+		{IsLeading: 3, SampleDependsOn: 0, SampleIsDependedOn: 0, HasRedundancy: 2},
+	}
+	if diff := deep.Equal(sdtp.Dependencies(), wanted); diff != nil {
+		t.Error(diff)
+	}
+	boxDiffAfterEncodeAndDecode(t, sdtp)
 }
@@ -71,7 +71,7 @@ func (s *SencBox) AddSample(sample SencSample) error {
 }
 
 // DecodeSenc - box-specific decode
-func DecodeSenc(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeSenc(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -97,12 +97,12 @@ func DecodeSenc(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeSencSR - box-specific decode
-func DecodeSencSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeSencSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	versionAndFlags := sr.ReadUint32()
 	sampleCount := sr.ReadUint32()
 	senc := SencBox{
 		Version:          byte(versionAndFlags >> 24),
-		rawData:          sr.ReadBytes(hdr.payloadLen() - 8), // After the first 8 bytes of box content
+		rawData:          sr.ReadBytes(hdr.PayloadLen() - 8), // After the first 8 bytes of box content
 		Flags:            versionAndFlags & flagsMask,
 		StartPos:         startPos,
 		SampleCount:      sampleCount,
@@ -327,3 +327,22 @@ func (s *SencBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string
 func (s *SencBox) GetPerSampleIVSize() int {
 	return int(s.perSampleIVSize)
 }
+
+// GetSamples - get IV and subsample encryption entries for every sample.
+// The senc box must have been parsed. IV is nil for all samples if there are no per-sample IVs,
+// and SubSamples is nil for all samples if the subsample flag is not set.
+func (s *SencBox) GetSamples() ([]SencSample, error) {
+	if s.readButNotParsed {
+		return nil, fmt.Errorf("senc box not parsed")
+	}
+	samples := make([]SencSample, s.SampleCount)
+	for i := range samples {
+		if len(s.IVs) > 0 {
+			samples[i].IV = s.IVs[i]
+		}
+		if len(s.SubSamples) > 0 {
+			samples[i].SubSamples = s.SubSamples[i]
+		}
+	}
+	return samples, nil
+}
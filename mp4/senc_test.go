@@ -124,3 +124,42 @@ func TestAddSamples(t *testing.T) {
 	err = senc.AddSample(SencSample{iv8, []SubSamplePattern{{20, 2000}}})
 	assertError(t, err, "Should have got error due to different iv size")
 }
+
+func TestSencGetSamples(t *testing.T) {
+	iv8 := InitializationVector("01234567")
+	inSamples := []SencSample{
+		{iv8, []SubSamplePattern{{10, 1000}}},
+		{iv8, []SubSamplePattern{{20, 2000}, {5, 500}}},
+	}
+	senc := CreateSencBox()
+	for _, s := range inSamples {
+		err := senc.AddSample(s)
+		assertNoError(t, err)
+	}
+	buf := bytes.Buffer{}
+	err := senc.Encode(&buf)
+	assertNoError(t, err)
+	box, err := DecodeBox(0, &buf)
+	assertNoError(t, err)
+	decSenc := box.(*SencBox)
+	_, err = decSenc.GetSamples()
+	if err == nil {
+		t.Error("no error for unparsed senc")
+	}
+	err = decSenc.ParseReadBox(8, nil)
+	assertNoError(t, err)
+	got, err := decSenc.GetSamples()
+	assertNoError(t, err)
+	if diff := deep.Equal(got, inSamples); diff != nil {
+		t.Error(diff)
+	}
+
+	senc = CreateSencBox()
+	err = senc.AddSample(SencSample{iv8, nil})
+	assertNoError(t, err)
+	got, err = senc.GetSamples()
+	assertNoError(t, err)
+	if diff := deep.Equal(got, []SencSample{{iv8, nil}}); diff != nil {
+		t.Error(diff)
+	}
+}
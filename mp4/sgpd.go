@@ -19,7 +19,7 @@ type SgpdBox struct {
 }
 
 // DecodeSgpd - box-specific decode
-func DecodeSgpd(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeSgpd(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -29,7 +29,7 @@ func DecodeSgpd(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeSgpdSR - box-specific decode
-func DecodeSgpdSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeSgpdSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	versionAndFlags := sr.ReadUint32()
 	version := byte(versionAndFlags >> 24)
 
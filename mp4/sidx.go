@@ -1,7 +1,9 @@
 package mp4
 
 import (
+	"fmt"
 	"io"
+	"math"
 
 	"github.com/edgeware/mp4ff/bits"
 )
@@ -54,7 +56,7 @@ type SidxRef struct {
 }
 
 // DecodeSidx - box-specific decode
-func DecodeSidx(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeSidx(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -64,7 +66,7 @@ func DecodeSidx(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeSidxSR - box-specific decode
-func DecodeSidxSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeSidxSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	versionAndFlags := sr.ReadUint32()
 	version := byte(versionAndFlags >> 24)
 
@@ -110,6 +112,64 @@ func CreateSidx(baseMediaDecodeTime uint64) *SidxBox {
 	}
 }
 
+// CreateSidxFromFragments - create a sidx with one reference per fragment for the track given by trex
+// (the first track if trex is nil). The referenced size is the size of all boxes in the fragment,
+// so the sidx indexes fragments directly following it (FirstOffset=0).
+// EarliestPresentationTime is the smallest presentation time in the first fragment, and version 1
+// is used if it does not fit in 32 bits. A fragment starting with a sync sample gets SAP type 1.
+// Sample data is not accessed, so lazy mdat is fine. The fragments are not changed.
+func CreateSidxFromFragments(frags []*Fragment, trex *TrexBox, timescale uint32) (*SidxBox, error) {
+	if len(frags) == 0 {
+		return nil, fmt.Errorf("no fragments")
+	}
+	sidx := &SidxBox{Timescale: timescale}
+	for i, frag := range frags {
+		if frag.Moof == nil {
+			return nil, fmt.Errorf("fragment %d: no moof", i+1)
+		}
+		traf := frag.trafForTrex(trex)
+		if traf == nil || traf.Tfdt == nil {
+			return nil, fmt.Errorf("fragment %d: no traf with tfdt for track", i+1)
+		}
+		size := frag.Size()
+		if size > 1<<31-1 {
+			return nil, fmt.Errorf("fragment %d: size %d too big for sidx", i+1, size)
+		}
+		ref := SidxRef{ReferencedSize: uint32(size)}
+		var dur uint64
+		decodeTime := traf.Tfdt.BaseMediaDecodeTime
+		firstSample := true
+		for _, trun := range traf.Truns {
+			filled, _ := trun.withDefaultValues(traf.Tfhd, trex)
+			for _, s := range filled.Samples {
+				if firstSample && s.IsSync() {
+					ref.StartsWithSAP = 1
+					ref.SAPType = 1
+				}
+				fs := FullSample{Sample: s, DecodeTime: decodeTime}
+				if i == 0 && (firstSample || fs.PresentationTime() < sidx.EarliestPresentationTime) {
+					sidx.EarliestPresentationTime = fs.PresentationTime()
+				}
+				firstSample = false
+				decodeTime += uint64(s.Dur)
+				dur += uint64(s.Dur)
+			}
+		}
+		if i == 0 {
+			sidx.ReferenceID = traf.Tfhd.TrackID
+		}
+		if dur > math.MaxUint32 {
+			return nil, fmt.Errorf("fragment %d: duration %d too big for sidx", i+1, dur)
+		}
+		ref.SubSegmentDuration = uint32(dur)
+		sidx.SidxRefs = append(sidx.SidxRefs, ref)
+	}
+	if sidx.EarliestPresentationTime > math.MaxUint32 {
+		sidx.Version = 1
+	}
+	return sidx, nil
+}
+
 // Type - return box type
 func (b *SidxBox) Type() string {
 	return "sidx"
@@ -25,3 +25,60 @@ func TestSidx(t *testing.T) {
 
 	boxDiffAfterEncodeAndDecode(t, sidx)
 }
+
+func TestSidxVersion1(t *testing.T) {
+	sidx := &SidxBox{Version: 1, ReferenceID: 2, Timescale: 90000,
+		EarliestPresentationTime: 1 << 40, FirstOffset: 1 << 33}
+	sidx.SidxRefs = append(sidx.SidxRefs, SidxRef{ReferencedSize: 4096, SubSegmentDuration: 180000,
+		StartsWithSAP: 1, SAPType: 2, SAPDeltaTime: 3000})
+	boxDiffAfterEncodeAndDecode(t, sidx)
+}
+
+func TestCreateSidxFromFragments(t *testing.T) {
+	var frags []*Fragment
+	startTime := uint64(1 << 33)
+	for i := 0; i < 3; i++ {
+		frag, err := CreateFragment(uint32(i+1), 1)
+		assertNoError(t, err)
+		for j := 0; j < 4; j++ {
+			flags := NonSyncSampleFlags
+			if j == 0 && i != 1 {
+				flags = SyncSampleFlags
+			}
+			frag.AddFullSample(FullSample{Sample: NewSample(flags, 3000, 10, 3000),
+				DecodeTime: startTime + uint64(12000*i+3000*j), Data: make([]byte, 10)})
+		}
+		frags = append(frags, frag)
+	}
+	// Durations from trex default must not be written into the trun samples
+	trun := frags[2].Moof.Traf.Trun
+	trun.flags &= ^sampleDurationPresentFlag
+	for j := range trun.Samples {
+		trun.Samples[j].Dur = 0
+	}
+	trex := CreateTrex(1)
+	trex.DefaultSampleDuration = 3000
+	sidx, err := CreateSidxFromFragments(frags, trex, 90000)
+	assertNoError(t, err)
+	if trun.Samples[0].Dur != 0 {
+		t.Error("trun samples changed")
+	}
+	if sidx.Version != 1 || sidx.ReferenceID != 1 || sidx.EarliestPresentationTime != startTime+3000 {
+		t.Errorf("got version %d, referenceID %d, and EPT %d", sidx.Version, sidx.ReferenceID,
+			sidx.EarliestPresentationTime)
+	}
+	if len(sidx.SidxRefs) != 3 {
+		t.Fatalf("got %d references instead of 3", len(sidx.SidxRefs))
+	}
+	for i, ref := range sidx.SidxRefs {
+		wantedSAP := uint8(1)
+		if i == 1 {
+			wantedSAP = 0
+		}
+		if ref.ReferencedSize != uint32(frags[i].Size()) || ref.SubSegmentDuration != 12000 ||
+			ref.StartsWithSAP != wantedSAP {
+			t.Errorf("ref %d: got %+v", i+1, ref)
+		}
+	}
+	boxDiffAfterEncodeAndDecode(t, sidx)
+}
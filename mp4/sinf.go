@@ -27,8 +27,24 @@ func (b *SinfBox) AddChild(box Box) {
 	b.Children = append(b.Children, box)
 }
 
+// OriginalFormat - original sample entry type from frma, or empty string if frma is missing
+func (b *SinfBox) OriginalFormat() string {
+	if b.Frma == nil {
+		return ""
+	}
+	return b.Frma.DataFormat
+}
+
+// Tenc - tenc box in schi, or nil if not present
+func (b *SinfBox) Tenc() *TencBox {
+	if b.Schi == nil {
+		return nil
+	}
+	return b.Schi.Tenc
+}
+
 // DecodeSinf - box-specific decode
-func DecodeSinf(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeSinf(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	children, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.size, r)
 	if err != nil {
 		return nil, err
@@ -41,7 +57,7 @@ func DecodeSinf(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeSinfSR - box-specific decode
-func DecodeSinfSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeSinfSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.size, sr)
 	if err != nil {
 		return nil, err
@@ -11,9 +11,10 @@ import (
 // Contained in : Media Information Box (minf)
 //
 type SmhdBox struct {
-	Version byte
-	Flags   uint32
-	Balance uint16 // should be int16
+	Version  byte
+	Flags    uint32
+	Balance  uint16 // should be int16
+	Reserved uint16 // Should be 0, but re-emitted verbatim
 }
 
 // CreateSmhd - Create Sound Media Header Box (all is zero)
@@ -22,7 +23,7 @@ func CreateSmhd() *SmhdBox {
 }
 
 // DecodeSmhd - box-specific decode
-func DecodeSmhd(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeSmhd(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -32,14 +33,14 @@ func DecodeSmhd(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeSmhdSR - box-specific decode
-func DecodeSmhdSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeSmhdSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	versionAndFlags := sr.ReadUint32()
 	b := SmhdBox{
-		Version: byte(versionAndFlags >> 24),
-		Flags:   versionAndFlags & flagsMask,
-		Balance: sr.ReadUint16(),
+		Version:  byte(versionAndFlags >> 24),
+		Flags:    versionAndFlags & flagsMask,
+		Balance:  sr.ReadUint16(),
+		Reserved: sr.ReadUint16(),
 	}
-	sr.SkipBytes(2) // Reserved
 	return &b, sr.AccError()
 }
 
@@ -73,7 +74,7 @@ func (b *SmhdBox) EncodeSW(sw bits.SliceWriter) error {
 	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
 	sw.WriteUint32(versionAndFlags)
 	sw.WriteUint16(b.Balance)
-	sw.WriteUint16(0) // Reserved
+	sw.WriteUint16(b.Reserved)
 	return sw.AccError()
 }
 
@@ -0,0 +1,10 @@
+package mp4
+
+import (
+	"testing"
+)
+
+func TestSmhd(t *testing.T) {
+	encBox := &SmhdBox{Balance: 2, Reserved: 1}
+	boxDiffAfterEncodeAndDecode(t, encBox)
+}
@@ -1,7 +1,9 @@
 package mp4
 
 import (
+	"fmt"
 	"io"
+	"math"
 
 	"github.com/edgeware/mp4ff/bits"
 )
@@ -16,8 +18,10 @@ type StblBox struct {
 	Stsd  *StsdBox
 	Stts  *SttsBox
 	Ctts  *CttsBox
+	Cslg  *CslgBox
 	Stsc  *StscBox
 	Stsz  *StszBox
+	Stz2  *Stz2Box
 	Stss  *StssBox
 	Stco  *StcoBox
 	Co64  *Co64Box
@@ -33,6 +37,27 @@ type StblBox struct {
 	Children []Box
 }
 
+// SampleSizer - sample size information provided by both stsz and stz2
+type SampleSizer interface {
+	// GetNrSamples - number of samples
+	GetNrSamples() uint32
+	// GetSampleSize - size of one-based sample nr i
+	GetSampleSize(i int) uint32
+	// GetTotalSampleSize - total size of samples in range [startNr, endNr]
+	GetTotalSampleSize(startNr, endNr uint32) (uint64, error)
+}
+
+// SampleSizes - return stsz or stz2, whichever is present, or nil if none
+func (s *StblBox) SampleSizes() SampleSizer {
+	switch {
+	case s.Stsz != nil:
+		return s.Stsz
+	case s.Stz2 != nil:
+		return s.Stz2
+	}
+	return nil
+}
+
 // NewStblBox - Generate a new empty stbl box
 func NewStblBox() *StblBox {
 	return &StblBox{}
@@ -48,10 +73,14 @@ func (s *StblBox) AddChild(box Box) {
 		s.Stts = box.(*SttsBox)
 	case "ctts":
 		s.Ctts = box.(*CttsBox)
+	case "cslg":
+		s.Cslg = box.(*CslgBox)
 	case "stsc":
 		s.Stsc = box.(*StscBox)
 	case "stsz":
 		s.Stsz = box.(*StszBox)
+	case "stz2":
+		s.Stz2 = box.(*Stz2Box)
 	case "stss":
 		s.Stss = box.(*StssBox)
 	case "stco":
@@ -79,7 +108,7 @@ func (s *StblBox) AddChild(box Box) {
 }
 
 // DecodeStbl - box-specific decode
-func DecodeStbl(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeStbl(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	children, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.size, r)
 	if err != nil {
 		return nil, err
@@ -92,7 +121,7 @@ func DecodeStbl(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeStblSR - box-specific decode
-func DecodeStblSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeStblSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.size, sr)
 	if err != nil {
 		return nil, err
@@ -133,3 +162,75 @@ func (b *StblBox) EncodeSW(sw bits.SliceWriter) error {
 func (s *StblBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
 	return ContainerInfo(s, w, specificBoxLevels, indent, indentStep)
 }
+
+// AdjustChunkOffsets - add delta to all chunk offsets in stco and co64 boxes in moov (or any stbl or box above it).
+// This is needed when boxes before mdat change size in a progressive file.
+// An stco box is replaced by a co64 box if some offset no longer fits in 32 bits.
+// Note that such a replacement makes the stbl box larger, which also moves mdat if moov is before it.
+// Nothing is changed if some offset would become negative.
+func AdjustChunkOffsets(moov Box, delta int64) error {
+	var stbls []Box
+	for _, b := range collectBoxes(moov) {
+		if b.Type() == "stbl" {
+			stbls = append(stbls, b)
+		}
+	}
+	for _, b := range stbls {
+		for _, offset := range b.(*StblBox).chunkOffsets() {
+			if delta < 0 && offset < uint64(-delta) {
+				return fmt.Errorf("chunk offset %d%+d is negative", offset, delta)
+			}
+		}
+	}
+	for _, b := range stbls {
+		stbl := b.(*StblBox)
+		if stco := stbl.Stco; stco != nil {
+			needsCo64 := false
+			for _, offset := range stco.ChunkOffset {
+				if int64(offset)+delta > math.MaxUint32 {
+					needsCo64 = true
+					break
+				}
+			}
+			if !needsCo64 {
+				for i, offset := range stco.ChunkOffset {
+					stco.ChunkOffset[i] = uint32(int64(offset) + delta)
+				}
+				continue
+			}
+			co64 := &Co64Box{ChunkOffset: make([]uint64, len(stco.ChunkOffset))}
+			for i, offset := range stco.ChunkOffset {
+				co64.ChunkOffset[i] = uint64(int64(offset) + delta)
+			}
+			for i, c := range stbl.Children {
+				if c == stco {
+					stbl.Children[i] = co64
+				}
+			}
+			stbl.Stco = nil
+			stbl.Co64 = co64
+			continue
+		}
+		if co64 := stbl.Co64; co64 != nil {
+			for i, offset := range co64.ChunkOffset {
+				co64.ChunkOffset[i] = uint64(int64(offset) + delta)
+			}
+		}
+	}
+	return nil
+}
+
+// chunkOffsets - chunk offsets from stco or co64
+func (s *StblBox) chunkOffsets() []uint64 {
+	if s.Stco != nil {
+		offsets := make([]uint64, len(s.Stco.ChunkOffset))
+		for i, offset := range s.Stco.ChunkOffset {
+			offsets[i] = uint64(offset)
+		}
+		return offsets
+	}
+	if s.Co64 != nil {
+		return s.Co64.ChunkOffset
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package mp4
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestAdjustChunkOffsets(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/prog_8s.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(bytes.NewBuffer(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted := make([][]byte, len(f.Moov.Traks))
+	for i, trak := range f.Moov.Traks {
+		var buf bytes.Buffer
+		err = f.CopySampleData(&buf, nil, trak, 1, trak.GetNrSamples())
+		if err != nil {
+			t.Fatal(err)
+		}
+		wanted[i] = buf.Bytes()
+	}
+
+	// Insert a free box between moov and mdat
+	free := &FreeBox{Name: "free", notDecoded: make([]byte, 992)}
+	for i, b := range f.Children {
+		if b == f.Mdat {
+			f.Children = append(f.Children[:i], append([]Box{free}, f.Children[i:]...)...)
+			break
+		}
+	}
+	err = AdjustChunkOffsets(f.Moov, int64(free.Size()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	err = f.Encode(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() != len(data)+int(free.Size()) {
+		t.Errorf("got file size %d instead of %d", out.Len(), len(data)+int(free.Size()))
+	}
+	f, err = DecodeFile(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, trak := range f.Moov.Traks {
+		var buf bytes.Buffer
+		err = f.CopySampleData(&buf, nil, trak, 1, trak.GetNrSamples())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(buf.Bytes(), wanted[i]) {
+			t.Errorf("track %d: sample data differs after insertion", i+1)
+		}
+	}
+
+	if err = AdjustChunkOffsets(f.Moov, -1<<20); err == nil {
+		t.Error("no error for negative chunk offsets")
+	}
+}
+
+func TestAdjustChunkOffsetsToCo64(t *testing.T) {
+	stbl := NewStblBox()
+	stbl.AddChild(&StcoBox{ChunkOffset: []uint32{1000, math.MaxUint32 - 100}})
+	stbl.AddChild(&StszBox{})
+	err := AdjustChunkOffsets(stbl, 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stbl.Stco != nil || stbl.Co64 == nil || stbl.Children[0] != stbl.Co64 {
+		t.Fatalf("stco not replaced by co64")
+	}
+	if diff := deep.Equal(stbl.Co64.ChunkOffset, []uint64{1200, math.MaxUint32 + 100}); diff != nil {
+		t.Error(diff)
+	}
+}
@@ -46,7 +46,7 @@ func (b *StppBox) AddChild(child Box) {
 }
 
 // DecodeStpp - Decode XMLSubtitleSampleEntry (stpp)
-func DecodeStpp(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeStpp(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -58,14 +58,14 @@ func DecodeStpp(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 	sr.SkipBytes(6) // Skip 6 reserved bytes
 	b.DataReferenceIndex = sr.ReadUint16()
 
-	b.Namespace = sr.ReadZeroTerminatedString(hdr.payloadLen())
+	b.Namespace = sr.ReadZeroTerminatedString(hdr.PayloadLen())
 
 	if sr.NrRemainingBytes() > 0 {
-		b.SchemaLocation = sr.ReadZeroTerminatedString(hdr.payloadLen())
+		b.SchemaLocation = sr.ReadZeroTerminatedString(hdr.PayloadLen())
 	}
 
 	if sr.NrRemainingBytes() > 0 {
-		b.AuxiliaryMimeTypes = sr.ReadZeroTerminatedString(hdr.payloadLen())
+		b.AuxiliaryMimeTypes = sr.ReadZeroTerminatedString(hdr.PayloadLen())
 	}
 	if err := sr.AccError(); err != nil {
 		return nil, fmt.Errorf("DecodeStpp: %w", err)
@@ -76,7 +76,7 @@ func DecodeStpp(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 	restReader := bytes.NewReader(remaining)
 
 	for {
-		box, err := DecodeBox(pos, restReader)
+		box, err := decodeBox(pos, restReader, hdr.opts)
 		if err == io.EOF {
 			break
 		} else if err != nil {
@@ -96,8 +96,8 @@ func DecodeStpp(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeStppSR - Decode XMLSubtitleSampleEntry (stpp)
-func DecodeStppSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
-	payloadLen := hdr.payloadLen()
+func DecodeStppSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	payloadLen := hdr.PayloadLen()
 
 	remainingBytes := func(sr bits.SliceReader, initPos, payloadLen int) int {
 		return payloadLen - (sr.GetPos() - initPos)
@@ -108,7 +108,7 @@ func DecodeStppSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, err
 	initPos := sr.GetPos()
 	sr.SkipBytes(6) // Skip 6 reserved bytes
 	b.DataReferenceIndex = sr.ReadUint16()
-	b.Namespace = sr.ReadZeroTerminatedString(hdr.payloadLen() - 8)
+	b.Namespace = sr.ReadZeroTerminatedString(hdr.PayloadLen() - 8)
 
 	if maxLen := remainingBytes(sr, initPos, payloadLen); maxLen > 0 {
 		b.SchemaLocation = sr.ReadZeroTerminatedString(maxLen)
@@ -126,7 +126,7 @@ func DecodeStppSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, err
 		if rest <= 0 {
 			break
 		}
-		box, err := DecodeBoxSR(pos, sr)
+		box, err := decodeBoxSR(pos, sr, hdr.opts)
 		if err != nil {
 			return nil, err
 		}
@@ -161,6 +161,11 @@ func (b *StppBox) Size() uint64 {
 	return totalSize
 }
 
+// GetChildren - list of child boxes
+func (b *StppBox) GetChildren() []Box {
+	return b.Children
+}
+
 // Encode - write box to w
 func (b *StppBox) Encode(w io.Writer) error {
 	err := EncodeHeader(b, w)
@@ -77,7 +77,7 @@ func (s *StsdBox) GetSampleDescription(index int) (Box, error) {
 }
 
 // DecodeStsd - box-specific decode
-func DecodeStsd(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeStsd(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	var versionAndFlags, sampleCount uint32
 	err := binary.Read(r, binary.BigEndian, &versionAndFlags)
 	if err != nil {
@@ -110,7 +110,7 @@ func DecodeStsd(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeStsdSR - box-specific decode
-func DecodeStsdSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeStsdSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	versionAndFlags := sr.ReadUint32()
 	sampleCount := sr.ReadUint32()
 	//Note higher startPos below since not simple container
@@ -146,6 +146,11 @@ func (s *StsdBox) Size() uint64 {
 	return containerSize(s.Children) + 8
 }
 
+// GetChildren - list of child boxes
+func (s *StsdBox) GetChildren() []Box {
+	return s.Children
+}
+
 // Encode - box-specific encode of stsd - not a usual container
 func (s *StsdBox) Encode(w io.Writer) error {
 	err := EncodeHeader(s, w)
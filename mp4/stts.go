@@ -22,7 +22,7 @@ type SttsBox struct {
 }
 
 // DecodeStts - box-specific decode
-func DecodeStts(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeStts(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -32,7 +32,7 @@ func DecodeStts(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeSttsSR - box-specific decode
-func DecodeSttsSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeSttsSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	versionAndFlags := sr.ReadUint32()
 	entryCount := sr.ReadUint32()
 	b := SttsBox{
@@ -36,6 +36,21 @@ func (b *StypBox) CompatibleBrands() []string {
 	return compatibleBrands
 }
 
+// HasBrand - true if brand is the major brand or one of the compatible brands
+func (b *StypBox) HasBrand(brand string) bool {
+	return b.MajorBrand() == brand || b.HasCompatibleBrand(brand)
+}
+
+// HasCompatibleBrand - true if brand is in the list of compatible brands
+func (b *StypBox) HasCompatibleBrand(brand string) bool {
+	for _, cb := range b.CompatibleBrands() {
+		if cb == brand {
+			return true
+		}
+	}
+	return false
+}
+
 // CreateStyp - Create an Styp box suitable for DASH/CMAF
 func CreateStyp() *StypBox {
 	return NewStyp("cmfs", 0, []string{"dash", "msdh"})
@@ -54,7 +69,7 @@ func NewStyp(majorBrand string, minorVersion uint32, compatibleBrands []string)
 }
 
 // DecodeStyp - box-specific decode
-func DecodeStyp(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeStyp(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -64,7 +79,7 @@ func DecodeStyp(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeStypSR - box-specific decode
-func DecodeStypSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeStypSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	b := StypBox{data: sr.ReadBytes(int(hdr.size) - hdr.hdrlen)}
 	return &b, sr.AccError()
 }
@@ -0,0 +1,18 @@
+package mp4
+
+import "testing"
+
+func TestStyp(t *testing.T) {
+	styp := NewStyp("cmfs", 0, []string{"cmfs", "cmfc", "dash"})
+	boxDiffAfterEncodeAndDecode(t, styp)
+	if styp.MajorBrand() != "cmfs" || len(styp.CompatibleBrands()) != 3 {
+		t.Errorf("got major brand %q and compatible brands %v", styp.MajorBrand(), styp.CompatibleBrands())
+	}
+	if !styp.HasCompatibleBrand("cmfc") || styp.HasCompatibleBrand("msdh") {
+		t.Error("wrong result from HasCompatibleBrand")
+	}
+	lmsg := NewStyp("lmsg", 0, []string{"dash"})
+	if lmsg.HasCompatibleBrand("lmsg") || !lmsg.HasBrand("lmsg") {
+		t.Error("major brand should only be found by HasBrand")
+	}
+}
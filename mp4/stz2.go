@@ -0,0 +1,172 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// Stz2Box - Compact Sample Size Box (stz2)
+//
+// Contained in : Sample Table box (stbl)
+//
+// Compact variant of stsz, where each sample size is stored with FieldSize 4, 8, or 16 bits.
+// The sample count is the length of SampleSize.
+// Use StblBox.SampleSizes() to get sample sizes regardless of which variant is present.
+type Stz2Box struct {
+	Version    byte
+	Flags      uint32
+	FieldSize  byte
+	SampleSize []uint32
+}
+
+// DecodeStz2 - box-specific decode
+func DecodeStz2(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	data, err := readBoxBody(r, hdr)
+	if err != nil {
+		return nil, err
+	}
+	sr := bits.NewFixedSliceReader(data)
+	return DecodeStz2SR(hdr, startPos, sr)
+}
+
+// DecodeStz2SR - box-specific decode
+func DecodeStz2SR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	versionAndFlags := sr.ReadUint32()
+	b := Stz2Box{
+		Version: byte(versionAndFlags >> 24),
+		Flags:   versionAndFlags & flagsMask,
+	}
+	sr.SkipBytes(3) // reserved
+	b.FieldSize = sr.ReadUint8()
+	sampleCount := sr.ReadUint32()
+	if sr.AccError() != nil {
+		return nil, sr.AccError()
+	}
+	if b.FieldSize != 4 && b.FieldSize != 8 && b.FieldSize != 16 {
+		return nil, fmt.Errorf("stz2: field size %d not 4, 8, or 16", b.FieldSize)
+	}
+	if uint64(sampleCount)*uint64(b.FieldSize) > 8*uint64(sr.NrRemainingBytes()) {
+		return nil, fmt.Errorf("stz2: %d samples do not fit in box", sampleCount)
+	}
+	b.SampleSize = make([]uint32, sampleCount)
+	for i := 0; i < int(sampleCount); i++ {
+		switch b.FieldSize {
+		case 4:
+			if i%2 == 1 {
+				continue
+			}
+			val := sr.ReadUint8()
+			b.SampleSize[i] = uint32(val >> 4)
+			if i+1 < int(sampleCount) {
+				b.SampleSize[i+1] = uint32(val & 0x0f)
+			}
+		case 8:
+			b.SampleSize[i] = uint32(sr.ReadUint8())
+		case 16:
+			b.SampleSize[i] = uint32(sr.ReadUint16())
+		}
+	}
+	return &b, sr.AccError()
+}
+
+// Type - box-specific type
+func (b *Stz2Box) Type() string {
+	return "stz2"
+}
+
+// Size - box-specific size
+func (b *Stz2Box) Size() uint64 {
+	nrBits := uint64(b.FieldSize) * uint64(len(b.SampleSize))
+	return uint64(boxHeaderSize+12) + (nrBits+7)/8
+}
+
+// Encode - write box to w
+func (b *Stz2Box) Encode(w io.Writer) error {
+	sw := bits.NewFixedSliceWriter(int(b.Size()))
+	err := b.EncodeSW(sw)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(sw.Bytes())
+	return err
+}
+
+// EncodeSW - box-specific encode to slicewriter
+func (b *Stz2Box) EncodeSW(sw bits.SliceWriter) error {
+	maxSize := uint32(1)<<b.FieldSize - 1
+	switch b.FieldSize {
+	case 4, 8, 16:
+	default:
+		return fmt.Errorf("stz2: field size %d not 4, 8, or 16", b.FieldSize)
+	}
+	for i, size := range b.SampleSize {
+		if size > maxSize {
+			return fmt.Errorf("stz2: sample %d size %d does not fit in %d bits", i+1, size, b.FieldSize)
+		}
+	}
+	err := EncodeHeaderSW(b, sw)
+	if err != nil {
+		return err
+	}
+	versionAndFlags := (uint32(b.Version) << 24) + b.Flags
+	sw.WriteUint32(versionAndFlags)
+	sw.WriteUint32(uint32(b.FieldSize)) // 24 bits reserved + 8 bits fieldSize
+	sw.WriteUint32(uint32(len(b.SampleSize)))
+	for i := 0; i < len(b.SampleSize); i++ {
+		switch b.FieldSize {
+		case 4:
+			if i%2 == 1 {
+				continue
+			}
+			val := byte(b.SampleSize[i] << 4)
+			if i+1 < len(b.SampleSize) {
+				val |= byte(b.SampleSize[i+1])
+			}
+			sw.WriteUint8(val)
+		case 8:
+			sw.WriteUint8(byte(b.SampleSize[i]))
+		case 16:
+			sw.WriteUint16(uint16(b.SampleSize[i]))
+		}
+	}
+	return sw.AccError()
+}
+
+// Info - write box-specific information
+func (b *Stz2Box) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
+	bd := newInfoDumper(w, indent, b, int(b.Version), b.Flags)
+	bd.write(" - fieldSize: %d", b.FieldSize)
+	bd.write(" - sampleCount: %d", b.GetNrSamples())
+	level := getInfoLevel(b, specificBoxLevels)
+	if level >= 1 {
+		for i := range b.SampleSize {
+			bd.write(" - sample[%d] size=%d", i+1, b.SampleSize[i])
+		}
+	}
+	return bd.err
+}
+
+// GetNrSamples - get number of samples
+func (b *Stz2Box) GetNrSamples() uint32 {
+	return uint32(len(b.SampleSize))
+}
+
+// GetSampleSize returns the size (in bytes) of a sample (one-based)
+func (b *Stz2Box) GetSampleSize(i int) uint32 {
+	return b.SampleSize[i-1]
+}
+
+// GetTotalSampleSize - get total size of a range [startNr, endNr] of samples
+func (b *Stz2Box) GetTotalSampleSize(startNr, endNr uint32) (uint64, error) {
+	nrSamples := b.GetNrSamples()
+	if startNr <= 0 || endNr > nrSamples {
+		return 0, fmt.Errorf("startNr or calculated endNr outside range 1-%d", nrSamples)
+	}
+	size := uint64(0)
+	for nr := startNr; nr <= endNr; nr++ {
+		size += uint64(b.SampleSize[nr-1]) // 1-based numbers
+	}
+	return size, nil
+}
@@ -0,0 +1,53 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestStz2EncDec(t *testing.T) {
+	testCases := []struct {
+		fieldSize byte
+		sizes     []uint32
+		boxSize   uint64
+	}{
+		{4, []uint32{1, 15, 7}, 20 + 2},
+		{4, []uint32{1, 15, 7, 0}, 20 + 2},
+		{8, []uint32{112, 234, 120}, 20 + 3},
+		{16, []uint32{1120, 65535, 12}, 20 + 6},
+	}
+	for _, tc := range testCases {
+		stz2 := &Stz2Box{FieldSize: tc.fieldSize, SampleSize: tc.sizes}
+		if stz2.Size() != tc.boxSize {
+			t.Errorf("field size %d: got box size %d instead of %d", tc.fieldSize, stz2.Size(), tc.boxSize)
+		}
+		boxDiffAfterEncodeAndDecode(t, stz2)
+	}
+}
+
+func TestStz2SampleSizer(t *testing.T) {
+	stbl := NewStblBox()
+	stbl.AddChild(&Stz2Box{FieldSize: 8, SampleSize: []uint32{1, 2, 3, 4}})
+	sizes := stbl.SampleSizes()
+	if sizes == nil {
+		t.Fatal("no sample sizes from stz2")
+	}
+	if sizes.GetNrSamples() != 4 || sizes.GetSampleSize(2) != 2 {
+		t.Errorf("got nrSamples %d and size of sample 2 %d", sizes.GetNrSamples(), sizes.GetSampleSize(2))
+	}
+	total, err := sizes.GetTotalSampleSize(1, 3)
+	if err != nil {
+		t.Error(err)
+	}
+	if total != 1+2+3 {
+		t.Errorf("got total size %d instead of 6", total)
+	}
+}
+
+func TestStz2TooBigSize(t *testing.T) {
+	stz2 := &Stz2Box{FieldSize: 4, SampleSize: []uint32{16}}
+	buf := bytes.Buffer{}
+	if err := stz2.Encode(&buf); err == nil {
+		t.Error("no error for size not fitting in field")
+	}
+}
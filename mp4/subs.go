@@ -55,8 +55,24 @@ type SubsSample struct {
 	Discardable             uint8
 }
 
+// GetSubSamples - get the subsamples of one-based sample number sampleNr.
+// nil is returned if there is no entry for the sample, meaning that it has no subsample structure.
+func (b *SubsBox) GetSubSamples(sampleNr uint32) []SubsSample {
+	var nr uint32
+	for _, e := range b.Entries {
+		nr += e.SampleDelta
+		if nr == sampleNr {
+			return e.SubSamples
+		}
+		if nr > sampleNr {
+			break
+		}
+	}
+	return nil
+}
+
 // DecodeSubs - box-specific decode
-func DecodeSubs(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeSubs(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -66,7 +82,7 @@ func DecodeSubs(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeSubsSR - box-specific decode
-func DecodeSubsSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeSubsSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	versionAndFlags := sr.ReadUint32()
 	version := byte(versionAndFlags >> 24)
 
@@ -144,6 +160,9 @@ func (b *SubsBox) EncodeSW(sw bits.SliceWriter) error {
 			if b.Version == 1 {
 				sw.WriteUint32(s.SubsampleSize)
 			} else {
+				if s.SubsampleSize > 0xffff {
+					return fmt.Errorf("subs: subsample size %d does not fit in version 0", s.SubsampleSize)
+				}
 				sw.WriteUint16(uint16(s.SubsampleSize))
 			}
 			sw.WriteUint8(s.SubsamplePriority)
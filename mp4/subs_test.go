@@ -1,6 +1,7 @@
 package mp4
 
 import (
+	"io/ioutil"
 	"testing"
 )
 
@@ -23,3 +24,29 @@ func TestSubsInfo(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestSubsVersion1InTraf(t *testing.T) {
+	subs := &SubsBox{Version: 1}
+	nalus := []SubsSample{{SubsampleSize: 70000}, {SubsampleSize: 12, Discardable: 1}}
+	subs.Entries = append(subs.Entries, SubsEntry{SampleDelta: 1, SubSamples: nalus})
+	subs.Entries = append(subs.Entries, SubsEntry{SampleDelta: 2, SubSamples: nalus[1:]})
+	boxDiffAfterEncodeAndDecode(t, subs)
+	subs.Version = 0
+	if err := subs.Encode(ioutil.Discard); err == nil {
+		t.Error("no error for too large subsample size in version 0")
+	}
+	subs.Version = 1
+
+	traf := &TrafBox{}
+	_ = traf.AddChild(&TfhdBox{TrackID: 1})
+	_ = traf.AddChild(subs)
+	boxDiffAfterEncodeAndDecode(t, traf)
+	if traf.Subs != subs {
+		t.Error("subs not set in traf")
+	}
+	for nr, want := range []int{0, 2, 0, 1, 0} {
+		if got := len(subs.GetSubSamples(uint32(nr))); got != want {
+			t.Errorf("sample %d: got %d subsamples instead of %d", nr, got, want)
+		}
+	}
+}
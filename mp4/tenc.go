@@ -22,7 +22,7 @@ type TencBox struct {
 }
 
 // DecodeTenc - box-specific decode
-func DecodeTenc(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeTenc(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -32,7 +32,7 @@ func DecodeTenc(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeTencSR - box-specific decode
-func DecodeTencSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeTencSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	versionAndFlags := sr.ReadUint32()
 	version := byte(versionAndFlags >> 24)
 
@@ -29,7 +29,7 @@ type TfhdBox struct {
 }
 
 // DecodeTfhd - box-specific decode
-func DecodeTfhd(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeTfhd(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -40,7 +40,7 @@ func DecodeTfhd(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeTfhdSR - box-specific decode
-func DecodeTfhdSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeTfhdSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	versionAndFlags := sr.ReadUint32()
 	version := byte(versionAndFlags >> 24)
 	flags := versionAndFlags & flagsMask
@@ -45,3 +45,46 @@ func GetTopBoxInfoList(rs io.ReadSeeker, stopBoxType string) ([]TopBoxInfo, erro
 
 	return topBoxList, nil
 }
+
+// FragmentByteRange - byte range of a fragment (moof + mdat) in a file
+type FragmentByteRange struct {
+	// StartPos - where in file the moof box starts
+	StartPos uint64
+	// Size - total size of moof and mdat
+	Size uint64
+}
+
+// ByteRangeMap - get byte ranges of all fragments (moof + mdat) in a file.
+// Only box headers are read, so the scan is fast even for big files.
+// The result can be used to generate EXT-X-BYTERANGE entries for HLS.
+func ByteRangeMap(rs io.ReadSeeker) ([]FragmentByteRange, error) {
+	topBoxes, err := GetTopBoxInfoList(rs, "")
+	if err != nil {
+		return nil, err
+	}
+	var ranges []FragmentByteRange
+	var moof *TopBoxInfo
+	for i := range topBoxes {
+		tbi := &topBoxes[i]
+		switch tbi.Type {
+		case "moof":
+			if moof != nil {
+				return nil, fmt.Errorf("moof at %d not followed by mdat", moof.StartPos)
+			}
+			moof = tbi
+		case "mdat":
+			if moof == nil {
+				continue // mdat of progressive file
+			}
+			if moof.StartPos+moof.Size != tbi.StartPos {
+				return nil, fmt.Errorf("mdat at %d does not directly follow moof", tbi.StartPos)
+			}
+			ranges = append(ranges, FragmentByteRange{moof.StartPos, moof.Size + tbi.Size})
+			moof = nil
+		}
+	}
+	if moof != nil {
+		return nil, fmt.Errorf("moof at %d not followed by mdat", moof.StartPos)
+	}
+	return ranges, nil
+}
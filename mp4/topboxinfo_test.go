@@ -42,3 +42,39 @@ func TestTopBoxInfo(t *testing.T) {
 		fh.Close()
 	}
 }
+
+func TestByteRangeMap(t *testing.T) {
+	testCases := []struct {
+		file         string
+		wantedRanges []FragmentByteRange
+	}{
+		{"testdata/1.m4s", []FragmentByteRange{{24, 25568}}},
+		{"testdata/prog_8s_enc_dashinit.mp4", []FragmentByteRange{{1518, 116035}, {117597, 78551}}},
+		{"testdata/init_prog.mp4", nil},
+	}
+
+	for _, tc := range testCases {
+		fh, err := os.Open(tc.file)
+		if err != nil {
+			t.Error(err)
+		}
+		gotRanges, err := ByteRangeMap(fh)
+		if err != nil {
+			t.Error(err)
+		}
+		if diff := deep.Equal(gotRanges, tc.wantedRanges); diff != nil {
+			t.Errorf("file %s: %v", tc.file, diff)
+		}
+		fh.Close()
+	}
+
+	fh, err := os.Open("testdata/moof_enc.m4s")
+	if err != nil {
+		t.Error(err)
+	}
+	defer fh.Close()
+	_, err = ByteRangeMap(fh)
+	if err == nil {
+		t.Error("no error for moof without mdat")
+	}
+}
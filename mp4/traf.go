@@ -1,7 +1,6 @@
 package mp4
 
 import (
-	"errors"
 	"fmt"
 	"io"
 
@@ -20,13 +19,14 @@ type TrafBox struct {
 	Sbgp     *SbgpBox
 	Sgpd     *SgpdBox
 	Senc     *SencBox
+	Subs     *SubsBox
 	Trun     *TrunBox // The first TrunBox
 	Truns    []*TrunBox
 	Children []Box
 }
 
 // DecodeTraf - box-specific decode
-func DecodeTraf(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeTraf(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	children, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.size, r)
 	if err != nil {
 		return nil, err
@@ -42,7 +42,7 @@ func DecodeTraf(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeTrafSR - box-specific decode
-func DecodeTrafSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeTrafSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.size, sr)
 	if err != nil {
 		return nil, err
@@ -126,6 +126,8 @@ func (t *TrafBox) AddChild(b Box) error {
 		t.Sgpd = b.(*SgpdBox)
 	case "senc":
 		t.Senc = b.(*SencBox)
+	case "subs":
+		t.Subs = b.(*SubsBox)
 	case "trun":
 		if t.Trun == nil {
 			t.Trun = b.(*TrunBox)
@@ -137,6 +139,40 @@ func (t *TrafBox) AddChild(b Box) error {
 	return nil
 }
 
+// copy - deep copy of traf and its children.
+// trun and senc are copied field by field, since they may be in a state
+// that cannot be encoded or is lost in decoding. Other children are
+// copied by encoding and decoding.
+func (t *TrafBox) copy() (*TrafBox, error) {
+	c := &TrafBox{}
+	for _, child := range t.Children {
+		switch box := child.(type) {
+		case *TrunBox:
+			trun := *box
+			trun.Samples = append([]Sample(nil), box.Samples...)
+			_ = c.AddChild(&trun)
+		case *SencBox:
+			senc := *box
+			senc.rawData = append([]byte(nil), box.rawData...)
+			senc.IVs = append([]InitializationVector(nil), box.IVs...)
+			senc.SubSamples = append([][]SubSamplePattern(nil), box.SubSamples...)
+			_ = c.AddChild(&senc)
+		default:
+			sw := bits.NewFixedSliceWriter(int(child.Size()))
+			err := child.EncodeSW(sw)
+			if err != nil {
+				return nil, err
+			}
+			b, err := DecodeBoxSR(0, bits.NewFixedSliceReader(sw.Bytes()))
+			if err != nil {
+				return nil, err
+			}
+			_ = c.AddChild(b)
+		}
+	}
+	return c, nil
+}
+
 // Type - return box type
 func (t *TrafBox) Type() string {
 	return "traf"
@@ -170,14 +206,17 @@ func (t *TrafBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string
 // OptimizeTfhdTrun - optimize trun by default values in tfhd box
 // Only look at first trun, even if there is more than one
 // Don't optimize again, if already done so that no data is present
+// The version of every trun is set to 1 if it has a negative composition time offset, and to 0 otherwise.
 func (t *TrafBox) OptimizeTfhdTrun() error {
 	tfhd := t.Tfhd
 	trun := t.Trun
-	if len(trun.Samples) == 0 {
-		return errors.New("No samples in trun")
-	}
-	if len(trun.Samples) == 1 {
-		return nil // No need to optimize
+	defer func() {
+		for _, tr := range t.Truns {
+			tr.setMinimalVersion()
+		}
+	}()
+	if len(trun.Samples) <= 1 {
+		return nil // No need to optimize. Zero samples is allowed for empty fragments
 	}
 
 	if trun.HasSampleDuration() {
@@ -251,6 +290,82 @@ func (t *TrafBox) OptimizeTfhdTrun() error {
 	return nil
 }
 
+// ComputeTrackDefaults - most common duration, size, and flags of samples.
+// The values are suitable as trex or tfhd defaults. If several values are equally common,
+// the one that first reaches the highest count is returned. All values are zero if there are no samples.
+func ComputeTrackDefaults(samples []Sample) (defaultDuration, defaultSize uint32, defaultFlags uint32) {
+	defaultDuration, _ = mostCommonValue(samples, func(s Sample) uint32 { return s.Dur })
+	defaultSize, _ = mostCommonValue(samples, func(s Sample) uint32 { return s.Size })
+	defaultFlags, _ = mostCommonValue(samples, func(s Sample) uint32 { return s.Flags })
+	return defaultDuration, defaultSize, defaultFlags
+}
+
+// mostCommonValue - most common value of samples given by value and its number of occurrences
+func mostCommonValue(samples []Sample, value func(s Sample) uint32) (uint32, int) {
+	counts := make(map[uint32]int)
+	var mostCommon uint32
+	maxCount := 0
+	for _, s := range samples {
+		v := value(s)
+		counts[v]++
+		if counts[v] > maxCount {
+			mostCommon = v
+			maxCount = counts[v]
+		}
+	}
+	return mostCommon, maxCount
+}
+
+// SetExplicitSampleValues - write duration, size, and flags explicitly for every sample in all truns.
+// Values missing in the truns are resolved from tfhd or trex defaults.
+// This is the reverse of OptimizeTfhdTrun and is useful for debugging, since the
+// encoded truns then show the values a player will compute.
+func (t *TrafBox) SetExplicitSampleValues(trex *TrexBox) error {
+	if t.Tfhd == nil {
+		return fmt.Errorf("no tfhd in traf")
+	}
+	for _, trun := range t.Truns {
+		_, _, _, err := ResolveSampleDefaults(trun, t.Tfhd, trex)
+		if err != nil {
+			return fmt.Errorf("track %d: %w", t.Tfhd.TrackID, err)
+		}
+		trun.AddSampleDefaultValues(t.Tfhd, trex)
+		trun.RemoveFirstSampleFlags()
+		trun.flags |= sampleDurationPresentFlag | sampleSizePresentFlag | sampleFlagsPresentFlag
+	}
+	return nil
+}
+
+// nrSamples - total number of samples in all truns
+func (t *TrafBox) nrSamples() int {
+	nr := 0
+	for _, trun := range t.Truns {
+		nr += int(trun.SampleCount())
+	}
+	return nr
+}
+
+// SetFirstSampleAsSync - single sync sample pattern with first_sample_flags in trun and non-sync default flags in tfhd.
+// The first sample is the first one in any trun, so empty truns before it are skipped.
+// All samples in later truns are signaled as non-sync by the tfhd default.
+func (t *TrafBox) SetFirstSampleAsSync() {
+	t.Tfhd.Flags |= defaultSampleFlagsPresent
+	t.Tfhd.DefaultSampleFlags = NonSyncSampleFlags
+	foundFirst := false
+	for _, trun := range t.Truns {
+		if !foundFirst && trun.SampleCount() > 0 {
+			trun.SetFirstSampleAsSync()
+			foundFirst = true
+			continue
+		}
+		trun.RemoveFirstSampleFlags()
+		trun.flags &= ^sampleFlagsPresentFlag
+		for i := range trun.Samples {
+			trun.Samples[i].Flags = NonSyncSampleFlags
+		}
+	}
+}
+
 //RemoveEncryptionBoxes - remove encryption boxes and return number of bytes removed
 func (t *TrafBox) RemoveEncryptionBoxes() uint64 {
 	remainingChildren := make([]Box, 0, len(t.Children))
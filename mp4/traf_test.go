@@ -105,3 +105,68 @@ func runEncodeDecode(t *testing.T, test testSamples, withOptimization bool) {
 			test.name, withOptimization, outSamples, test.samples)
 	}
 }
+
+func TestOptimizeTrunVersion(t *testing.T) {
+	testCases := []struct {
+		ctos          []int32
+		wantedVersion byte
+	}{
+		{[]int32{0, 0, 0}, 0},
+		{[]int32{2000, 0, 1000}, 0},
+		{[]int32{1000, -1000, 0}, 1},
+		{[]int32{-500}, 1},
+	}
+	for _, tc := range testCases {
+		traf := createTestTrafBox()
+		for _, cto := range tc.ctos {
+			traf.Trun.AddSample(NewSample(SyncSampleFlags, 1000, 10, cto))
+		}
+		traf.Trun.DataOffset = 8
+		err := traf.OptimizeTfhdTrun()
+		assertNoError(t, err)
+		if traf.Trun.Version != tc.wantedVersion {
+			t.Errorf("ctos %v: got version %d instead of %d", tc.ctos, traf.Trun.Version, tc.wantedVersion)
+		}
+		var buf bytes.Buffer
+		err = traf.Encode(&buf)
+		assertNoError(t, err)
+		box, err := DecodeBox(0, &buf)
+		assertNoError(t, err)
+		trun := box.(*TrafBox).Trun
+		trun.AddSampleDefaultValues(box.(*TrafBox).Tfhd, nil)
+		for i, s := range trun.Samples {
+			if s.CompositionTimeOffset != tc.ctos[i] {
+				t.Errorf("ctos %v: sample %d has cto %d", tc.ctos, i+1, s.CompositionTimeOffset)
+			}
+		}
+	}
+}
+
+func TestComputeTrackDefaults(t *testing.T) {
+	samples := []Sample{
+		NewSample(SyncSampleFlags, 1024, 371, 0),
+		NewSample(NonSyncSampleFlags, 1024, 371, 0),
+		NewSample(NonSyncSampleFlags, 1024, 371, 0),
+		NewSample(NonSyncSampleFlags, 960, 371, 0),
+	}
+	dur, size, flags := ComputeTrackDefaults(samples)
+	if dur != 1024 || size != 371 || flags != NonSyncSampleFlags {
+		t.Errorf("got defaults %d, %d, %08x", dur, size, flags)
+	}
+
+	traf := createTestTrafBox()
+	for _, s := range samples {
+		traf.Trun.AddSample(s)
+	}
+	err := traf.OptimizeTfhdTrun()
+	assertNoError(t, err)
+	if !traf.Tfhd.HasDefaultSampleSize() || traf.Tfhd.DefaultSampleSize != 371 {
+		t.Errorf("sample size not hoisted to tfhd")
+	}
+	if traf.Trun.HasSampleSize() {
+		t.Errorf("sample size still in trun")
+	}
+	if traf.Tfhd.HasDefaultSampleDuration() || !traf.Trun.HasSampleDuration() {
+		t.Errorf("differing sample durations hoisted to tfhd")
+	}
+}
@@ -18,6 +18,7 @@ const DefaultTrakID = 1
 type TrakBox struct {
 	Tkhd     *TkhdBox
 	Edts     *EdtsBox
+	Tref     *TrefBox
 	Mdia     *MdiaBox
 	Children []Box
 }
@@ -36,12 +37,14 @@ func (t *TrakBox) AddChild(box Box) {
 		t.Mdia = box.(*MdiaBox)
 	case "edts":
 		t.Edts = box.(*EdtsBox)
+	case "tref":
+		t.Tref = box.(*TrefBox)
 	}
 	t.Children = append(t.Children, box)
 }
 
 // DecodeTrak - box-specific decode
-func DecodeTrak(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeTrak(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	children, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.size, r)
 	if err != nil {
 		return nil, err
@@ -54,7 +57,7 @@ func DecodeTrak(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeTrakSR - box-specific decode
-func DecodeTrakSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeTrakSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.size, sr)
 	if err != nil {
 		return nil, err
@@ -99,14 +102,15 @@ func (t *TrakBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string
 // GetNrSamples - get number of samples for this track defined in the parent moov box.
 func (t *TrakBox) GetNrSamples() uint32 {
 	stbl := t.Mdia.Minf.Stbl
-	return stbl.Stsz.GetNrSamples()
+	return stbl.SampleSizes().GetNrSamples()
 }
 
 // GetSampleData - get sample metadata for a specific interval of samples defined in moov.
 // If going outside the range of available samples, an error is returned.
 func (t *TrakBox) GetSampleData(startSampleNr, endSampleNr uint32) ([]Sample, error) {
 	stbl := t.Mdia.Minf.Stbl
-	nrSamples := stbl.Stsz.GetNrSamples()
+	sampleSizes := stbl.SampleSizes()
+	nrSamples := sampleSizes.GetNrSamples()
 	if startSampleNr < 1 || endSampleNr > nrSamples {
 		return nil, fmt.Errorf("Samples interval %d-%d not inside available %d-%d", startSampleNr, endSampleNr, 1, nrSamples)
 	}
@@ -124,7 +128,7 @@ func (t *TrakBox) GetSampleData(startSampleNr, endSampleNr uint32) ([]Sample, er
 		samples[nr] = Sample{
 			Flags:                 createSampleFlagsFromProgressiveBoxes(stss, sdtp, nr),
 			Dur:                   stts.GetDur(nr),
-			Size:                  stbl.Stsz.GetSampleSize(int(nr)),
+			Size:                  sampleSizes.GetSampleSize(int(nr)),
 			CompositionTimeOffset: cto,
 		}
 	}
@@ -162,8 +166,8 @@ func (t *TrakBox) GetRangesForSampleInterval(startSampleNr, endSampleNr uint32)
 	stsc := stbl.Stsc
 	stco := stbl.Stco
 	co64 := stbl.Co64
-	stsz := stbl.Stsz
-	nrSamples := stbl.Stsz.GetNrSamples()
+	stsz := stbl.SampleSizes()
+	nrSamples := stsz.GetNrSamples()
 	if startSampleNr < 1 || endSampleNr > nrSamples {
 		return nil, fmt.Errorf("Samples interval %d-%d not inside available %d-%d", startSampleNr, endSampleNr, 1, nrSamples)
 	}
@@ -18,7 +18,7 @@ func (b *TrefBox) AddChild(box Box) {
 }
 
 // DecodeTref - box-specific decode
-func DecodeTref(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeTref(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	children, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.size, r)
 	if err != nil {
 		return nil, err
@@ -31,7 +31,7 @@ func DecodeTref(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeTrefSR - box-specific decode
-func DecodeTrefSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeTrefSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.size, sr)
 	if err != nil {
 		return nil, err
@@ -73,6 +73,26 @@ func (b *TrefBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string
 	return ContainerInfo(b, w, specificBoxLevels, indent, indentStep)
 }
 
+// GetTrackIDs - referenced track IDs of all children of type refType (e.g. "cdsc") in order
+func (b *TrefBox) GetTrackIDs(refType string) []uint32 {
+	var trackIDs []uint32
+	for _, c := range b.Children {
+		if tt, ok := c.(*TrefTypeBox); ok && tt.Name == refType {
+			trackIDs = append(trackIDs, tt.TrackIDs...)
+		}
+	}
+	return trackIDs
+}
+
+// GetReferencedTrackIDs - track IDs referenced by the track with reference type refType.
+// For a subtitle track, refType "cdsc" gives the video track(s) it describes.
+func (t *TrakBox) GetReferencedTrackIDs(refType string) []uint32 {
+	if t.Tref == nil {
+		return nil
+	}
+	return t.Tref.GetTrackIDs(refType)
+}
+
 // TrefTypeBox - TrackReferenceTypeBox - ISO/IEC 14496-12 Ed. 9 Sec. 8.3
 // Name can be one of hint, cdsc, font, hind, vdep, vplx, subt (ISO/IEC 14496-12)
 // dpnd, ipir, mpod, sync (ISO/IEC 14496-14)
@@ -82,7 +102,7 @@ type TrefTypeBox struct {
 }
 
 // DecodeTrefType - box-specific decode
-func DecodeTrefType(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeTrefType(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -92,8 +112,8 @@ func DecodeTrefType(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeTrefTypeSR - box-specific decode
-func DecodeTrefTypeSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
-	nrIds := hdr.payloadLen() / 4
+func DecodeTrefTypeSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	nrIds := hdr.PayloadLen() / 4
 	b := TrefTypeBox{
 		Name:     hdr.name,
 		TrackIDs: make([]uint32, nrIds),
@@ -1,7 +1,10 @@
 package mp4
 
 import (
+	"bytes"
 	"testing"
+
+	"github.com/go-test/deep"
 )
 
 func TestTref(t *testing.T) {
@@ -20,3 +23,36 @@ func TestTref(t *testing.T) {
 	tref.AddChild(&TrefTypeBox{Name: "sync", TrackIDs: []uint32{12, 13}})
 	boxDiffAfterEncodeAndDecode(t, &tref)
 }
+
+func TestTrakReferences(t *testing.T) {
+	trak := CreateEmptyTrak(2, 1000, "wvtt", "en")
+	_ = trak.SetWvttDescriptor("")
+	if ids := trak.GetReferencedTrackIDs("cdsc"); ids != nil {
+		t.Errorf("got %v without tref", ids)
+	}
+	tref := &TrefBox{}
+	tref.AddChild(&TrefTypeBox{Name: "cdsc", TrackIDs: []uint32{1}})
+	tref.AddChild(&TrefTypeBox{Name: "font", TrackIDs: []uint32{3}})
+	tref.AddChild(&TrefTypeBox{Name: "cdsc", TrackIDs: []uint32{4, 5}})
+	trak.AddChild(tref)
+
+	var buf bytes.Buffer
+	err := trak.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	box, err := DecodeBox(0, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decTrak := box.(*TrakBox)
+	if diff := deep.Equal(decTrak.GetReferencedTrackIDs("cdsc"), []uint32{1, 4, 5}); diff != nil {
+		t.Errorf("cdsc: %v", diff)
+	}
+	if diff := deep.Equal(decTrak.GetReferencedTrackIDs("font"), []uint32{3}); diff != nil {
+		t.Errorf("font: %v", diff)
+	}
+	if ids := decTrak.GetReferencedTrackIDs("hint"); ids != nil {
+		t.Errorf("got %v for hint", ids)
+	}
+}
@@ -21,8 +21,13 @@ func (b *TrepBox) AddChild(child Box) {
 	b.Children = append(b.Children, child)
 }
 
+// GetChildren - list of child boxes
+func (b *TrepBox) GetChildren() []Box {
+	return b.Children
+}
+
 // DecodeTrep - box-specific decode
-func DecodeTrep(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeTrep(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -32,7 +37,7 @@ func DecodeTrep(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeTrepSR - box-specific decode
-func DecodeTrepSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeTrepSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	versionAndFlags := sr.ReadUint32()
 	trackID := sr.ReadUint32()
 	b := TrepBox{
@@ -9,3 +9,21 @@ func TestTrep(t *testing.T) {
 	trep.AddChild(&KindBox{SchemeURI: "X", Value: "Y"})
 	boxDiffAfterEncodeAndDecode(t, trep)
 }
+
+func TestMvexWithTrep(t *testing.T) {
+	mvex := NewMvexBox()
+	mvex.AddChild(CreateTrex(1))
+	trep := &TrepBox{TrackID: 1}
+	trep.AddChild(&KindBox{SchemeURI: "urn:mpeg:dash:role:2011", Value: "main"})
+	mvex.AddChild(trep)
+	boxDiffAfterEncodeAndDecode(t, mvex)
+
+	gotTrep, ok := mvex.GetTrep(1)
+	if !ok || gotTrep != trep {
+		t.Error("trep for trackID 1 not found")
+	}
+	_, ok = mvex.GetTrep(2)
+	if ok {
+		t.Error("trep found for trackID 2")
+	}
+}
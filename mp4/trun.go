@@ -19,6 +19,7 @@ type TrunBox struct {
 	firstSampleFlags uint32 // interpreted same way as SampleFlags
 	Samples          []Sample
 	writeOrderNr     uint32 // Used for multi trun offsets
+	decodedPayload   int    // Payload length of decoded box, 0 if not decoded or changed after decode
 }
 
 const dataOffsetPresentFlag uint32 = 0x01
@@ -27,9 +28,11 @@ const sampleDurationPresentFlag uint32 = 0x100
 const sampleSizePresentFlag uint32 = 0x200
 const sampleFlagsPresentFlag uint32 = 0x400
 const sampleCompositionTimeOffsetPresentFlag uint32 = 0x800
+const definedTrunFlags = dataOffsetPresentFlag | firstSampleFlagsPresentFlag | sampleDurationPresentFlag |
+	sampleSizePresentFlag | sampleFlagsPresentFlag | sampleCompositionTimeOffsetPresentFlag
 
 // DecodeTrun - box-specific decode
-func DecodeTrun(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeTrun(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -38,11 +41,22 @@ func DecodeTrun(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 	versionAndFlags := s.ReadUint32()
 	sampleCount := s.ReadUint32()
 	t := &TrunBox{
-		Version:     byte(versionAndFlags >> 24),
-		flags:       versionAndFlags & flagsMask,
-		sampleCount: sampleCount,
-		Samples:     make([]Sample, sampleCount),
+		Version:        byte(versionAndFlags >> 24),
+		flags:          versionAndFlags & flagsMask,
+		sampleCount:    sampleCount,
+		decodedPayload: hdr.PayloadLen(),
+	}
+	if t.Size()-boxHeaderSize > uint64(hdr.PayloadLen()) {
+		if !hdr.opts.lenientTrun() {
+			return nil, fmt.Errorf("trun: flags %#x and sample count %d need %d bytes, but box size is %d",
+				t.flags, sampleCount, t.Size(), hdr.size)
+		}
+		if err := t.NormalizeFlags(); err != nil {
+			return nil, err
+		}
+		hdr.opts.addRepairedTrun()
 	}
+	t.Samples = make([]Sample, sampleCount)
 
 	if t.HasDataOffset() {
 		t.DataOffset = s.ReadInt32()
@@ -77,15 +91,26 @@ func DecodeTrun(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeTrun - box-specific decode
-func DecodeTrunSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeTrunSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	versionAndFlags := sr.ReadUint32()
 	sampleCount := sr.ReadUint32()
 	t := &TrunBox{
-		Version:     byte(versionAndFlags >> 24),
-		flags:       versionAndFlags & flagsMask,
-		sampleCount: sampleCount,
-		Samples:     make([]Sample, sampleCount),
+		Version:        byte(versionAndFlags >> 24),
+		flags:          versionAndFlags & flagsMask,
+		sampleCount:    sampleCount,
+		decodedPayload: hdr.PayloadLen(),
+	}
+	if t.Size()-boxHeaderSize > uint64(hdr.PayloadLen()) {
+		if !hdr.opts.lenientTrun() {
+			return nil, fmt.Errorf("trun: flags %#x and sample count %d need %d bytes, but box size is %d",
+				t.flags, sampleCount, t.Size(), hdr.size)
+		}
+		if err := t.NormalizeFlags(); err != nil {
+			return nil, err
+		}
+		hdr.opts.addRepairedTrun()
 	}
+	t.Samples = make([]Sample, sampleCount)
 
 	if t.HasDataOffset() {
 		t.DataOffset = sr.ReadInt32()
@@ -175,6 +200,93 @@ func (t *TrunBox) AddSampleDefaultValues(tfhd *TfhdBox, trex *TrexBox) (totalDur
 	return totalDur
 }
 
+// withDefaultValues - copy of t with sample values set from tfhd and trex like in AddSampleDefaultValues.
+// t itself is not changed.
+func (t *TrunBox) withDefaultValues(tfhd *TfhdBox, trex *TrexBox) (filled *TrunBox, totalDur uint64) {
+	c := *t
+	c.Samples = make([]Sample, len(t.Samples))
+	copy(c.Samples, t.Samples)
+	totalDur = c.AddSampleDefaultValues(tfhd, trex)
+	return &c, totalDur
+}
+
+// ResolveSampleDefaults - resolve default sample duration, size, and flags for trun.
+// Values are taken from tfhd if present there, and otherwise from trex.
+// An error is returned if a value is not present in the trun samples and
+// cannot be found in either tfhd or trex, or if tfhd is missing.
+func ResolveSampleDefaults(trun *TrunBox, tfhd *TfhdBox, trex *TrexBox) (dur, size, flags uint32, err error) {
+	if tfhd == nil {
+		return 0, 0, 0, fmt.Errorf("no tfhd")
+	}
+	if tfhd.HasDefaultSampleDuration() {
+		dur = tfhd.DefaultSampleDuration
+	} else if trex != nil {
+		dur = trex.DefaultSampleDuration
+	} else if trun.sampleCount > 0 && !trun.HasSampleDuration() {
+		return 0, 0, 0, fmt.Errorf("no sample duration in trun, tfhd, or trex")
+	}
+	if tfhd.HasDefaultSampleSize() {
+		size = tfhd.DefaultSampleSize
+	} else if trex != nil {
+		size = trex.DefaultSampleSize
+	} else if trun.sampleCount > 0 && !trun.HasSampleSize() {
+		return 0, 0, 0, fmt.Errorf("no sample size in trun, tfhd, or trex")
+	}
+	if tfhd.HasDefaultSampleFlags() {
+		flags = tfhd.DefaultSampleFlags
+	} else if trex != nil {
+		flags = trex.DefaultSampleFlags
+	} else if !trun.HasSampleFlags() {
+		onlyFirstSample := trun.sampleCount == 1 && trun.HasFirstSampleFlags()
+		if trun.sampleCount > 0 && !onlyFirstSample {
+			return 0, 0, 0, fmt.Errorf("no sample flags in trun, tfhd, or trex")
+		}
+	}
+	return dur, size, flags, nil
+}
+
+// NormalizeFlags - make the sample-present flags consistent with the size of the decoded box.
+// Undefined flag bits are always cleared. For a decoded box, the number of per-sample fields is inferred
+// as (payload size - fixed fields) / sampleCount. If the flags signal one field more than that, and it is
+// the only signaled per-sample field, so that there is no doubt about which field is missing, that field is
+// marked as not present. Otherwise, an error is returned, as it is if the payload size does not match
+// any field combination. Boxes that are created or changed after decode are only cleared of undefined bits.
+// Decoding with DecodeOptions.LenientTrun calls this method for truns which are too short for their flags.
+func (t *TrunBox) NormalizeFlags() error {
+	t.flags &= definedTrunFlags
+	if t.decodedPayload == 0 || t.sampleCount == 0 || t.Size()-boxHeaderSize == uint64(t.decodedPayload) {
+		return nil
+	}
+	fixedSize := 8 // version, flags and sample count
+	if t.HasDataOffset() {
+		fixedSize += 4
+	}
+	if t.HasFirstSampleFlags() {
+		fixedSize += 4
+	}
+	sampleDataSize := t.decodedPayload - fixedSize
+	if sampleDataSize < 0 || sampleDataSize%(4*int(t.sampleCount)) != 0 {
+		return fmt.Errorf("trun: payload size %d does not fit %d samples", t.decodedPayload, t.sampleCount)
+	}
+	nrFields := sampleDataSize / (4 * int(t.sampleCount))
+	var signaled []uint32
+	for _, f := range []uint32{sampleDurationPresentFlag, sampleSizePresentFlag, sampleFlagsPresentFlag,
+		sampleCompositionTimeOffsetPresentFlag} {
+		if t.flags&f != 0 {
+			signaled = append(signaled, f)
+		}
+	}
+	if nrFields > len(signaled) {
+		return fmt.Errorf("trun: %d per-sample fields in payload, but only %d signaled", nrFields, len(signaled))
+	}
+	if nrFields != 0 || len(signaled) != 1 {
+		return fmt.Errorf("trun: %d per-sample fields in payload, but %d signaled, so the missing fields are ambiguous",
+			nrFields, len(signaled))
+	}
+	t.flags &= ^signaled[0]
+	return nil
+}
+
 // FirstSampleFlags - return firstSampleFlags and indicator if present
 func (t *TrunBox) FirstSampleFlags() (flags uint32, present bool) {
 	return t.firstSampleFlags, t.flags&firstSampleFlagsPresentFlag != 0
@@ -192,6 +304,22 @@ func (t *TrunBox) RemoveFirstSampleFlags() {
 	t.flags &= ^firstSampleFlagsPresentFlag
 }
 
+// SetFirstSampleAsSync - signal the first sample as sync sample via first_sample_flags,
+// and all other samples as non-sync. Per-sample flags are removed from the trun,
+// so the default sample flags in tfhd or trex must signal non-sync samples.
+// TrafBox.SetFirstSampleAsSync() sets such tfhd defaults as well.
+func (t *TrunBox) SetFirstSampleAsSync() {
+	t.SetFirstSampleFlags(SyncSampleFlags)
+	t.flags &= ^sampleFlagsPresentFlag
+	for i := range t.Samples {
+		if i == 0 {
+			t.Samples[i].Flags = SyncSampleFlags
+			continue
+		}
+		t.Samples[i].Flags = NonSyncSampleFlags
+	}
+}
+
 // SampleCount - return how many samples are defined
 func (t *TrunBox) SampleCount() uint32 {
 	return t.sampleCount
@@ -343,7 +471,7 @@ func (t *TrunBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string
 // offsetInMdat is offset in mdat data (data normally starts 8 or 16 bytes after start of mdat box)
 // baseDecodeTime is decodeTime in tfdt in track timescale (timescale in mfhd)
 // To fill missing individual values from tfhd and trex defaults, call trun.AddSampleDefaultValues() before this call
-func (t *TrunBox) GetFullSamples(offsetInMdat uint32, baseDecodeTime uint64, mdat *MdatBox) []FullSample {
+func (t *TrunBox) GetFullSamples(offsetInMdat uint64, baseDecodeTime uint64, mdat *MdatBox) []FullSample {
 	samples := make([]FullSample, 0, t.SampleCount())
 	var accDur uint64 = 0
 	for _, s := range t.Samples {
@@ -352,11 +480,11 @@ func (t *TrunBox) GetFullSamples(offsetInMdat uint32, baseDecodeTime uint64, mda
 		newSample := FullSample{
 			Sample:     s,
 			DecodeTime: dTime,
-			Data:       mdat.Data[offsetInMdat : offsetInMdat+s.Size],
+			Data:       mdat.Data[offsetInMdat : offsetInMdat+uint64(s.Size)],
 		}
 		samples = append(samples, newSample)
 		accDur += uint64(s.Dur)
-		offsetInMdat += s.Size
+		offsetInMdat += uint64(s.Size)
 	}
 	return samples
 }
@@ -378,7 +506,7 @@ func (t *TrunBox) GetSampleRange(startSampleNr, endSampleNr uint32) []Sample {
 // baseDecodeTime is decodeTime in tfdt in track timescale (timescale from mfhd).
 // To fill missing individual values from tfhd and trex defaults, call AddSampleDefaultValues() before this call.
 func (t *TrunBox) GetSampleInterval(startSampleNr, endSampleNr uint32, baseDecodeTime uint64,
-	mdat *MdatBox, offsetInMdat uint32) (SampleInterval, error) {
+	mdat *MdatBox, offsetInMdat uint64) (SampleInterval, error) {
 	si := SampleInterval{}
 	if startSampleNr < 1 {
 		return si, fmt.Errorf("startSegNr < 1")
@@ -402,33 +530,68 @@ func (t *TrunBox) GetSampleInterval(startSampleNr, endSampleNr uint32, baseDecod
 			continue
 		}
 		decTime += uint64(s.Dur)
-		offsetInMdat += s.Size
+		offsetInMdat += uint64(s.Size)
 	}
 	si.Samples = t.Samples[startSampleNr-1 : endSampleNr]
 	si.OffsetInMdat = offsetInMdat
 	si.Size = size
 	if mdat != nil && !mdat.IsLazy() {
-		si.Data = mdat.Data[si.OffsetInMdat : si.OffsetInMdat+si.Size]
+		si.Data = mdat.Data[si.OffsetInMdat : si.OffsetInMdat+uint64(si.Size)]
 	}
 	return si, nil
 }
 
 // AddFullSample - add Sample part of FullSample
 func (t *TrunBox) AddFullSample(s *FullSample) {
-	t.Samples = append(t.Samples, s.Sample)
-	t.sampleCount++
+	t.AddSample(s.Sample)
 }
 
 // AddSample - add a Sample
+// Version is set to 1 if the composition time offset is negative, since version 0 offsets are unsigned.
 func (t *TrunBox) AddSample(s Sample) {
 	t.Samples = append(t.Samples, s)
 	t.sampleCount++
+	t.decodedPayload = 0
+	if s.CompositionTimeOffset < 0 {
+		t.Version = 1
+	}
 }
 
-// AddSamples - add a a slice of Sample
+// AddSamples - add a slice of Sample in one call.
+// The sample slice grows at most once. If the trun has no samples before the call, the flags
+// for sample duration, size, flags, and composition time offset present are set once for all samples.
+// Otherwise, the flags are left as they are like in AddSample, since the values of existing samples
+// may be given by tfhd or trex defaults.
+// Like for AddSample, version is set to 1 if any composition time offset is negative.
 func (t *TrunBox) AddSamples(s []Sample) {
+	if t.sampleCount == 0 {
+		t.flags |= sampleDurationPresentFlag | sampleSizePresentFlag | sampleFlagsPresentFlag |
+			sampleCompositionTimeOffsetPresentFlag
+	}
 	t.Samples = append(t.Samples, s...)
 	t.sampleCount += uint32(len(s))
+	t.decodedPayload = 0
+	for i := range s {
+		if s[i].CompositionTimeOffset < 0 {
+			t.Version = 1
+			break
+		}
+	}
+}
+
+// setMinimalVersion - set version 1 if a composition time offset is negative and version 0 otherwise.
+// Without composition time offsets in the trun, the version does not matter and is set to 0.
+func (t *TrunBox) setMinimalVersion() {
+	t.Version = 0
+	if !t.HasSampleCompositionTimeOffset() {
+		return
+	}
+	for _, s := range t.Samples {
+		if s.CompositionTimeOffset < 0 {
+			t.Version = 1
+			return
+		}
+	}
 }
 
 // Duration - calculate total duration of all samples given defaultSampleDuration
@@ -1,8 +1,10 @@
 package mp4
 
 import (
+	"bytes"
 	"testing"
 
+	"github.com/edgeware/mp4ff/bits"
 	"github.com/go-test/deep"
 )
 
@@ -98,7 +100,7 @@ func TestGetSampleInterval(t *testing.T) {
 		endSampleNr    uint32
 		baseDecodeTime uint64
 		mdat           *MdatBox
-		offsetInMdat   uint32
+		offsetInMdat   uint64
 		wantedSItvl    SampleInterval
 	}{
 		{
@@ -107,6 +109,11 @@ func TestGetSampleInterval(t *testing.T) {
 		{
 			3, 4, 10000, &mdat, 0, SampleInterval{10300, []Sample{{0, 300, 3000, 0}, {0, 400, 4000, 0}}, 3000, 7000, nil},
 		},
+		{
+			// Offset beyond 4GiB in a lazy mdat
+			3, 4, 10000, &MdatBox{lazyDataSize: 5 << 30}, 5<<30 - 100000, SampleInterval{10300,
+				[]Sample{{0, 300, 3000, 0}, {0, 400, 4000, 0}}, 5<<30 - 97000, 7000, nil},
+		},
 	}
 
 	for i, tc := range testCases {
@@ -153,3 +160,311 @@ func TestFirstSampleFlags(t *testing.T) {
 		t.Error("firstSampleFlags present after removal")
 	}
 }
+
+func TestResolveSampleDefaults(t *testing.T) {
+	trex := CreateTrex(1)
+	trex.DefaultSampleDuration = 1024
+	trex.DefaultSampleSize = 100
+	trex.DefaultSampleFlags = NonSyncSampleFlags
+
+	trun := CreateTrun(0)
+	trun.AddSample(Sample{SyncSampleFlags, 512, 200, 0})
+	trun.AddSample(Sample{NonSyncSampleFlags, 512, 300, 0})
+
+	tfhd := CreateTfhd(1)
+	tfhd.Flags |= defaultSampleDurationPresent
+	tfhd.DefaultSampleDuration = 2048
+
+	dur, size, flags, err := ResolveSampleDefaults(trun, tfhd, trex)
+	if err != nil {
+		t.Error(err)
+	}
+	if dur != 2048 || size != 100 || flags != NonSyncSampleFlags {
+		t.Errorf("got dur=%d size=%d flags=%08x", dur, size, flags)
+	}
+
+	// All values present in trun, so no defaults needed
+	_, _, _, err = ResolveSampleDefaults(trun, CreateTfhd(1), nil)
+	if err != nil {
+		t.Error(err)
+	}
+
+	trun.flags &= ^sampleSizePresentFlag
+	_, _, _, err = ResolveSampleDefaults(trun, CreateTfhd(1), nil)
+	if err == nil {
+		t.Error("no error for missing default sample size")
+	}
+
+	tfhd = CreateTfhd(1)
+	tfhd.Flags |= defaultSampleSizePresent
+	tfhd.DefaultSampleSize = 250
+	_, size, _, err = ResolveSampleDefaults(trun, tfhd, nil)
+	if err != nil {
+		t.Error(err)
+	}
+	if size != 250 {
+		t.Errorf("got size %d instead of 250", size)
+	}
+
+	_, _, _, err = ResolveSampleDefaults(trun, nil, CreateTrex(1))
+	if err == nil {
+		t.Error("no error for missing tfhd")
+	}
+}
+
+func TestAddSamplesSameAsAddSample(t *testing.T) {
+	samples := []Sample{
+		{SyncSampleFlags, 1024, 100, 0},
+		{NonSyncSampleFlags, 1024, 200, 512},
+		{NonSyncSampleFlags, 1000, 300, -512},
+	}
+	trunOne := CreateTrun(0)
+	for _, s := range samples {
+		trunOne.AddSample(s)
+	}
+	trunAll := CreateTrun(0)
+	trunAll.AddSamples(samples)
+	if diff := deep.Equal(trunAll, trunOne); diff != nil {
+		t.Error(diff)
+	}
+
+	trunNoFlags := CreateTrun(0)
+	trunNoFlags.flags = dataOffsetPresentFlag
+	trunNoFlags.AddSamples(samples)
+	if trunNoFlags.flags != trunOne.flags {
+		t.Errorf("got flags %06x instead of %06x", trunNoFlags.flags, trunOne.flags)
+	}
+
+	// Decoded, optimized trun where the values come from tfhd defaults
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		frag.AddFullSample(FullSample{Sample: Sample{SyncSampleFlags, 1000, 4, 0},
+			DecodeTime: uint64(i * 1000), Data: []byte{1, 2, 3, 4}})
+	}
+	frag.EncOptimize = OptimizeTrun
+	buf := bytes.Buffer{}
+	err = frag.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	traf := f.Segments[0].Fragments[0].Moof.Traf
+	traf.Trun.AddSamples([]Sample{{SyncSampleFlags, 1000, 4, 0}})
+	buf.Reset()
+	err = traf.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	box, err := DecodeBox(0, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decTraf := box.(*TrafBox)
+	filled, _ := decTraf.Trun.withDefaultValues(decTraf.Tfhd, nil)
+	for i, s := range filled.Samples {
+		if s.Dur != 1000 || s.Size != 4 || s.Flags != SyncSampleFlags {
+			t.Errorf("sample %d: got %v after AddSamples on optimized trun", i+1, s)
+		}
+	}
+}
+
+func TestSetFirstSampleAsSync(t *testing.T) {
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		frag.AddFullSample(FullSample{Sample: Sample{NonSyncSampleFlags, 1000, 4, 0},
+			DecodeTime: uint64(i * 1000), Data: []byte{1, 2, 3, 4}})
+	}
+	traf := frag.Moof.Traf
+	traf.SetFirstSampleAsSync()
+	trun := traf.Trun
+	if trun.HasSampleFlags() {
+		t.Error("per-sample flags still present")
+	}
+	if flags, present := trun.FirstSampleFlags(); !present || flags != SyncSampleFlags {
+		t.Errorf("got firstSampleFlags %08x present=%t", flags, present)
+	}
+	buf := bytes.Buffer{}
+	err = frag.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotSync, err := f.Segments[0].Fragments[0].SyncSamples(nil)
+	if err != nil {
+		t.Error(err)
+	}
+	if diff := deep.Equal(gotSync, []uint32{0}); diff != nil {
+		t.Error(diff)
+	}
+
+	// First sample in second trun, since the first trun is empty
+	traf = &TrafBox{}
+	_ = traf.AddChild(CreateTfhd(1))
+	for i := 0; i < 3; i++ {
+		trun := CreateTrun(uint32(i))
+		if i > 0 {
+			trun.AddSample(Sample{SyncSampleFlags, 1000, 4, 0})
+			trun.AddSample(Sample{SyncSampleFlags, 1000, 4, 0})
+		}
+		_ = traf.AddChild(trun)
+	}
+	traf.SetFirstSampleAsSync()
+	var gotFlags []uint32
+	for _, trun := range traf.Truns {
+		if trun.HasSampleFlags() {
+			t.Error("per-sample flags still present")
+		}
+		filled, _ := trun.withDefaultValues(traf.Tfhd, nil)
+		for _, s := range filled.Samples {
+			gotFlags = append(gotFlags, s.Flags)
+		}
+	}
+	wantedFlags := []uint32{SyncSampleFlags, NonSyncSampleFlags, NonSyncSampleFlags, NonSyncSampleFlags}
+	if diff := deep.Equal(gotFlags, wantedFlags); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestTrunNormalizeFlags(t *testing.T) {
+	trun := CreateTrun(0)
+	trun.DataOffset = 100
+	trun.flags |= 0x1000 // Undefined bit
+	trun.AddSample(Sample{SyncSampleFlags, 1000, 0, 0})
+	trun.AddSample(Sample{NonSyncSampleFlags, 1000, 0, 0})
+	err := trun.NormalizeFlags()
+	if err != nil {
+		t.Error(err)
+	}
+	// Zero values do not affect the flags of a created trun
+	wantedFlags := dataOffsetPresentFlag | sampleDurationPresentFlag | sampleSizePresentFlag |
+		sampleFlagsPresentFlag | sampleCompositionTimeOffsetPresentFlag
+	if trun.flags != wantedFlags {
+		t.Errorf("got flags %#x instead of %#x", trun.flags, wantedFlags)
+	}
+	boxDiffAfterEncodeAndDecode(t, trun)
+}
+
+func TestDecodeTrunTooShort(t *testing.T) {
+	trun := CreateTrun(0)
+	trun.DataOffset = 100
+	trun.flags = dataOffsetPresentFlag | sampleDurationPresentFlag | sampleFlagsPresentFlag
+	trun.AddSample(Sample{SyncSampleFlags, 1000, 0, 0})
+	trun.AddSample(Sample{NonSyncSampleFlags, 1001, 0, 0})
+	buf := bytes.Buffer{}
+	err := trun.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	data[8+2] |= 0x02 // Claim sample sizes without room for them
+
+	if _, err = DecodeBox(0, bytes.NewBuffer(data)); err == nil {
+		t.Error("no error decoding trun with inconsistent flags")
+	}
+	if _, err = DecodeBoxSR(0, bits.NewFixedSliceReader(data)); err == nil {
+		t.Error("no error decoding trun with inconsistent flags (SR)")
+	}
+	// Any of the three signaled fields may be the missing one
+	var repaired int
+	opts := DecodeOptions{LenientTrun: true, RepairedTruns: &repaired}
+	if _, err = DecodeBoxWithOptions(0, bytes.NewBuffer(data), opts); err == nil {
+		t.Error("no error for ambiguous missing field")
+	}
+
+	// Only sample sizes are signaled, so they must be the missing field
+	trun = CreateTrun(0)
+	trun.DataOffset = 100
+	trun.flags = dataOffsetPresentFlag
+	trun.AddSample(Sample{SyncSampleFlags, 0, 0, 0})
+	trun.AddSample(Sample{0, 0, 0, 0})
+	trun.SetFirstSampleFlags(SyncSampleFlags)
+	buf.Reset()
+	err = trun.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := buf.Bytes()
+	data = make([]byte, len(orig))
+	copy(data, orig)
+	data[8+2] |= 0x02
+	box, err := DecodeBoxWithOptions(0, bytes.NewBuffer(data), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	boxSR, err := DecodeBoxSRWithOptions(0, bits.NewFixedSliceReader(data), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repaired != 2 {
+		t.Errorf("got %d repaired truns instead of 2", repaired)
+	}
+	for _, b := range []Box{box, boxSR} {
+		decTrun := b.(*TrunBox)
+		if decTrun.flags != trun.flags {
+			t.Errorf("got flags %#x instead of %#x", decTrun.flags, trun.flags)
+		}
+		if diff := deep.Equal(decTrun.Samples, trun.Samples); diff != nil {
+			t.Errorf("samples: %v", diff)
+		}
+		out := bytes.Buffer{}
+		err = decTrun.Encode(&out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(out.Bytes(), orig) {
+			t.Error("re-encoded trun differs from the one with correct flags")
+		}
+	}
+
+	data[8+2] |= 0x08 // Also claim composition time offsets, so that two fields are missing
+	if _, err = DecodeBoxWithOptions(0, bytes.NewBuffer(data), opts); err == nil {
+		t.Error("no error for two missing fields")
+	}
+	data[8+2] &= ^byte(0x0a)
+	data[8+3] &= ^byte(0x04) // No first sample flags, so that the payload fits no field combination
+	data[8+2] |= 0x02
+	if _, err = DecodeBoxWithOptions(0, bytes.NewBuffer(data), opts); err == nil {
+		t.Error("no error for trun payload not matching any flags")
+	}
+}
+
+func TestTrunNegativeCompositionTimeOffsets(t *testing.T) {
+	// Decode order I P B B with presentation order I B B P
+	ctos := []int32{0, 20, -10, -10}
+	trun := CreateTrun(0)
+	trun.Version = 0
+	for i, cto := range ctos {
+		trun.AddSample(NewSample(0, 10, 1, cto))
+		if cto < 0 && trun.Version != 1 {
+			t.Errorf("sample %d: version not set to 1 for negative offset", i+1)
+		}
+	}
+	trun.DataOffset = 8
+	buf := bytes.Buffer{}
+	assertNoError(t, trun.Encode(&buf))
+	box, err := DecodeBox(0, &buf)
+	assertNoError(t, err)
+	decTrun := box.(*TrunBox)
+	if decTrun.Version != 1 {
+		t.Errorf("got trun version %d instead of 1", decTrun.Version)
+	}
+	samples := decTrun.GetFullSamples(0, 1000, &MdatBox{Data: []byte{0, 1, 2, 3}})
+	wantedPTS := []uint64{1000, 1030, 1010, 1020}
+	for i, s := range samples {
+		if s.CompositionTimeOffset != ctos[i] || s.PresentationTime() != wantedPTS[i] {
+			t.Errorf("sample %d: got cto %d and pts %d", i+1, s.CompositionTimeOffset, s.PresentationTime())
+		}
+	}
+}
@@ -20,7 +20,7 @@ func (b *UdtaBox) AddChild(box Box) {
 }
 
 // DecodeUdta - box-specific decode
-func DecodeUdta(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeUdta(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	children, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.size, r)
 	if err != nil {
 		return nil, err
@@ -33,7 +33,7 @@ func DecodeUdta(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeUdtaSR - box-specific decode
-func DecodeUdtaSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeUdtaSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.size, sr)
 	if err != nil {
 		return nil, err
@@ -5,9 +5,8 @@ import "testing"
 func TestUdta(t *testing.T) {
 	udta := &UdtaBox{}
 	unknown := &UnknownBox{
-		name:       "\xa9enc",
-		size:       12,
-		notDecoded: []byte{0, 0, 0, 0},
+		BoxType: "\xa9enc",
+		RawData: []byte{0, 0, 0, 0},
 	}
 
 	udta.AddChild(unknown) // Any arbitrary box
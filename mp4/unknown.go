@@ -7,15 +7,16 @@ import (
 	"github.com/edgeware/mp4ff/bits"
 )
 
-// UnknownBox - box that we don't know how to parse
+// UnknownBox - box that we don't know how to parse.
+// The payload and header size are kept as they are, so that the box is written back unchanged.
 type UnknownBox struct {
-	name       string
-	size       uint64
-	notDecoded []byte
+	BoxType   string
+	RawData   []byte
+	LargeSize bool // 64-bit largesize header field is used
 }
 
 // DecodeUnknown - decode an unknown box
-func DecodeUnknown(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeUnknown(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -25,18 +26,23 @@ func DecodeUnknown(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeUnknown - decode an unknown box
-func DecodeUnknownSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
-	return &UnknownBox{hdr.name, hdr.size, sr.ReadBytes(hdr.payloadLen())}, sr.AccError()
+func DecodeUnknownSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	largeSize := hdr.hdrlen > boxHeaderSize
+	return &UnknownBox{hdr.name, sr.ReadBytes(hdr.PayloadLen()), largeSize}, sr.AccError()
 }
 
 // Type - return box type
 func (b *UnknownBox) Type() string {
-	return b.name
+	return b.BoxType
 }
 
-// Size - return calculated size
+// Size - return calculated size, depending on LargeSize set or not
 func (b *UnknownBox) Size() uint64 {
-	return b.size
+	hdrSize := boxHeaderSize
+	if b.LargeSize {
+		hdrSize += largeSizeLen
+	}
+	return uint64(hdrSize + len(b.RawData))
 }
 
 // Encode - write box to w
@@ -52,11 +58,11 @@ func (b *UnknownBox) Encode(w io.Writer) error {
 
 // EncodeSW - box-specific encode to slicewriter
 func (b *UnknownBox) EncodeSW(sw bits.SliceWriter) error {
-	err := EncodeHeaderSW(b, sw)
+	err := EncodeHeaderWithSizeSW(b.BoxType, b.Size(), b.LargeSize, sw)
 	if err != nil {
 		return err
 	}
-	sw.WriteBytes(b.notDecoded)
+	sw.WriteBytes(b.RawData)
 	return sw.AccError()
 }
 
@@ -66,7 +72,7 @@ func (b *UnknownBox) Info(w io.Writer, specificBoxLevels, indent, indentStep str
 	bd.write(" - not implemented or unknown box")
 	level := getInfoLevel(b, specificBoxLevels)
 	if level > 0 {
-		bd.write(" - %s", hex.EncodeToString(b.notDecoded))
+		bd.write(" - %s", hex.EncodeToString(b.RawData))
 	}
 
 	return bd.err
@@ -1,17 +1,138 @@
 package mp4
 
 import (
+	"bytes"
 	"testing"
+
+	"github.com/edgeware/mp4ff/bits"
+	"github.com/go-test/deep"
 )
 
 // TestUnknown including non-ascii character in name (box typs is uint32 according to spec)
 func TestUnknown(t *testing.T) {
 
 	unknownBox := &UnknownBox{
-		name:       "\xa9enc",
-		size:       12,
-		notDecoded: []byte{0, 0, 0, 0},
+		BoxType: "\xa9enc",
+		RawData: []byte{0, 0, 0, 0},
 	}
 
 	boxDiffAfterEncodeAndDecode(t, unknownBox)
 }
+
+func TestUnknownLargeSize(t *testing.T) {
+	data := []byte{0, 0, 0, 1, 'a', 'b', 'c', 'd', 0, 0, 0, 0, 0, 0, 0, 19, 1, 2, 3}
+	box, err := DecodeBox(0, bytes.NewBuffer(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !box.(*UnknownBox).LargeSize || box.Size() != uint64(len(data)) {
+		t.Errorf("got size %d and largesize %t", box.Size(), box.(*UnknownBox).LargeSize)
+	}
+	buf := bytes.Buffer{}
+	err = box.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("got %v instead of %v", buf.Bytes(), data)
+	}
+	boxDiffAfterEncodeAndDecode(t, box)
+}
+
+func TestUnknownBoxStats(t *testing.T) {
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	init.Moov.AddChild(&UnknownBox{BoxType: "abcd", RawData: []byte{0, 0, 0, 0}})
+	init.Moov.AddChild(&UnknownBox{BoxType: "abcd"})
+	stsd := init.Moov.Trak.Mdia.Minf.Stbl.Stsd
+	stsd.AddChild(CreateVisualSampleEntryBox("avc1", 1280, 720, nil))
+	stsd.AvcX.AddChild(&UnknownBox{BoxType: "efgh", RawData: []byte{1, 2}})
+	buf := bytes.Buffer{}
+	err := init.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	wanted := map[string]UnknownBoxStat{
+		"abcd": {Count: 2, TotalBytes: 20},
+		"efgh": {Count: 1, TotalBytes: 10},
+	}
+	stats := make(map[string]UnknownBoxStat)
+	_, err = DecodeFile(bytes.NewBuffer(data), WithUnknownBoxStats(stats))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(stats, wanted); diff != nil {
+		t.Errorf("file stats: %v", diff)
+	}
+
+	stats = make(map[string]UnknownBoxStat)
+	r := bytes.NewBuffer(data)
+	var pos uint64
+	for r.Len() > 0 {
+		box, err := DecodeBoxWithOptions(pos, r, DecodeOptions{UnknownStats: stats})
+		if err != nil {
+			t.Fatal(err)
+		}
+		pos += box.Size()
+	}
+	if diff := deep.Equal(stats, wanted); diff != nil {
+		t.Errorf("box stats: %v", diff)
+	}
+
+	stats = make(map[string]UnknownBoxStat)
+	_, err = DecodeFileSR(bits.NewFixedSliceReader(data), WithUnknownBoxStats(stats))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := deep.Equal(stats, wanted); diff != nil {
+		t.Errorf("file SR stats: %v", diff)
+	}
+
+	stats = make(map[string]UnknownBoxStat)
+	sr := bits.NewFixedSliceReader(data)
+	pos = 0
+	for sr.NrRemainingBytes() > 0 {
+		box, err := DecodeBoxSRWithOptions(pos, sr, DecodeOptions{UnknownStats: stats})
+		if err != nil {
+			t.Fatal(err)
+		}
+		pos += box.Size()
+	}
+	if diff := deep.Equal(stats, wanted); diff != nil {
+		t.Errorf("box SR stats: %v", diff)
+	}
+}
+
+func TestUnknownBoxFileRoundTrip(t *testing.T) {
+	init := CreateEmptyInit()
+	init.AddEmptyTrack(90000, "video", "und")
+	init.AddChild(&UnknownBox{BoxType: "xyzw", RawData: []byte("vendor data")})
+	init.Moov.Trak.AddChild(&UnknownBox{BoxType: "abcd", RawData: []byte{0, 1, 2, 3, 4}})
+	buf := bytes.Buffer{}
+	err := init.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	f, err := DecodeFile(bytes.NewBuffer(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	top := f.Children[len(f.Children)-1]
+	if top.(*UnknownBox).BoxType != "xyzw" || string(top.(*UnknownBox).RawData) != "vendor data" {
+		t.Errorf("top-level unknown box not decoded correctly")
+	}
+	// Segment mode only writes known top-level boxes for fragmented files
+	f.FragEncMode = EncModeBoxTree
+	out := bytes.Buffer{}
+	err = f.Encode(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), data) {
+		t.Errorf("re-encoded file differs from original")
+	}
+}
@@ -13,11 +13,13 @@ const (
 )
 
 // UUIDBox - Used as container for MSS boxes tfxd and tfrf
+// For other user types, the data after the UUID is kept in Payload.
 type UUIDBox struct {
 	UUID    string // 16 bytes
 	SubType string
 	Tfxd    *TfxdData
 	Tfrf    *TfrfData
+	Payload []byte
 }
 
 // TfxdData - MSS TfxdBox data after UUID part
@@ -40,7 +42,7 @@ type TfrfData struct {
 }
 
 // DecodeUUIDBox - decode a UUID box including tfxd or tfrf
-func DecodeUUIDBox(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeUUIDBox(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -50,7 +52,7 @@ func DecodeUUIDBox(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeUUIDBoxSR - decode a UUID box including tfxd or tfrf
-func DecodeUUIDBoxSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeUUIDBoxSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	b := &UUIDBox{}
 	b.UUID = string(sr.ReadBytes(16))
 	switch b.UUID {
@@ -69,13 +71,22 @@ func DecodeUUIDBoxSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box,
 		}
 		b.Tfrf = tfrf
 	default:
-		// err := fmt.Errorf("Unknown uuid=%s", b.UUID)
-		// return nil, err
+		payloadLen := hdr.PayloadLen() - 16
+		if payloadLen > 0 {
+			b.Payload = sr.ReadBytes(payloadLen)
+		}
 	}
 
 	return b, sr.AccError()
 }
 
+// UserType - the 16-byte extended type following the box header
+func (b *UUIDBox) UserType() [16]byte {
+	var userType [16]byte
+	copy(userType[:], b.UUID)
+	return userType
+}
+
 // Type - return box type
 func (b *UUIDBox) Type() string {
 	return "uuid"
@@ -89,6 +100,8 @@ func (b *UUIDBox) Size() uint64 {
 		size += b.Tfxd.size()
 	case "tfrf":
 		size += b.Tfrf.size()
+	default:
+		size += uint64(len(b.Payload))
 	}
 	return size
 }
@@ -115,6 +128,9 @@ func (b *UUIDBox) EncodeSW(sw bits.SliceWriter) error {
 		err = b.Tfxd.encode(sw)
 	} else if b.SubType == "tfrf" {
 		err = b.Tfrf.encode(sw)
+	} else {
+		sw.WriteBytes(b.Payload)
+		err = sw.AccError()
 	}
 	return err
 }
@@ -206,7 +222,11 @@ func (t *TfrfData) encode(sw bits.SliceWriter) error {
 func (b *UUIDBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
 	bd := newInfoDumper(w, indent, b, -1, 0)
 	bd.write(" - uuid: %s", hex.EncodeToString([]byte(b.UUID)))
-	bd.write(" - subType: %s", b.SubType)
+	if b.SubType != "" {
+		bd.write(" - subType: %s", b.SubType)
+	} else {
+		bd.write(" - payload: %d bytes", len(b.Payload))
+	}
 	level := getInfoLevel(b, specificBoxLevels)
 	if level > 0 {
 		switch b.SubType {
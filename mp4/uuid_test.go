@@ -69,3 +69,16 @@ func TestTfrf(t *testing.T) {
 		t.Error("Non-matching in and out binaries")
 	}
 }
+
+func TestUnknownUUID(t *testing.T) {
+	userType, _ := hex.DecodeString("0102030405060708090a0b0c0d0e0f10")
+	uuid := &UUIDBox{UUID: string(userType), Payload: []byte{0xde, 0xad, 0xbe, 0xef}}
+	if uuid.Size() != 28 {
+		t.Errorf("got size %d instead of 28", uuid.Size())
+	}
+	ut := uuid.UserType()
+	if !bytes.Equal(ut[:], userType) {
+		t.Errorf("got user type %x", ut)
+	}
+	boxDiffAfterEncodeAndDecode(t, uuid)
+}
@@ -19,9 +19,11 @@ type VisualSampleEntryBox struct {
 	CompressorName     string
 	AvcC               *AvcCBox
 	HvcC               *HvcCBox
+	Av1C               *Av1CBox
 	Btrt               *BtrtBox
 	Clap               *ClapBox
 	Pasp               *PaspBox
+	Colr               *ColrBox
 	Sinf               *SinfBox
 	Children           []Box
 }
@@ -33,7 +35,7 @@ func NewVisualSampleEntryBox(name string) *VisualSampleEntryBox {
 	return b
 }
 
-// CreateVisualSampleEntryBox - Create new VisualSampleEntry such as avc1, avc3, hev1, hvc1
+// CreateVisualSampleEntryBox - Create new VisualSampleEntry such as avc1, avc3, hev1, hvc1, av01
 func CreateVisualSampleEntryBox(name string, width, height uint16, sampleEntry Box) *VisualSampleEntryBox {
 	b := &VisualSampleEntryBox{
 		name:               name,
@@ -59,12 +61,16 @@ func (b *VisualSampleEntryBox) AddChild(child Box) {
 		b.AvcC = child.(*AvcCBox)
 	case "hvcC":
 		b.HvcC = child.(*HvcCBox)
+	case "av1C":
+		b.Av1C = child.(*Av1CBox)
 	case "btrt":
 		b.Btrt = child.(*BtrtBox)
 	case "clap":
 		b.Clap = child.(*ClapBox)
 	case "pasp":
 		b.Pasp = child.(*PaspBox)
+	case "colr":
+		b.Colr = child.(*ColrBox)
 	case "sinf":
 		b.Sinf = child.(*SinfBox)
 	}
@@ -73,7 +79,7 @@ func (b *VisualSampleEntryBox) AddChild(child Box) {
 }
 
 // DecodeVisualSampleEntry - decode avc1/avc3/... box
-func DecodeVisualSampleEntry(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeVisualSampleEntry(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -83,7 +89,7 @@ func DecodeVisualSampleEntry(hdr boxHeader, startPos uint64, r io.Reader) (Box,
 }
 
 // DecodeVisualSampleEntrySR - decode avc1/avc3/hvc1/hev1... box
-func DecodeVisualSampleEntrySR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeVisualSampleEntrySR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	b := VisualSampleEntryBox{name: hdr.name}
 
 	// 14496-12 8.5.2.2 Sample entry (8 bytes)
@@ -114,12 +120,12 @@ func DecodeVisualSampleEntrySR(hdr boxHeader, startPos uint64, sr bits.SliceRead
 	// Now there may be clap and pasp boxes
 	// 14496-15  5.4.2.1.2 avcC should be inside avc1, avc3 box
 	pos := startPos + 86 // Size of all previous data
-	endPos := startPos + uint64(hdr.hdrlen) + uint64(hdr.payloadLen())
+	endPos := startPos + uint64(hdr.hdrlen) + uint64(hdr.PayloadLen())
 	for {
 		if pos >= endPos {
 			break
 		}
-		box, err := DecodeBoxSR(pos, sr)
+		box, err := decodeBoxSR(pos, sr, hdr.opts)
 		if err != nil {
 			return nil, fmt.Errorf("Error decoding childBox of VisualSampleEntry: %w", err)
 		}
@@ -147,6 +153,11 @@ func (b *VisualSampleEntryBox) Size() uint64 {
 	return totalSize
 }
 
+// GetChildren - list of child boxes
+func (b *VisualSampleEntryBox) GetChildren() []Box {
+	return b.Children
+}
+
 // Encode - write box to w
 func (b *VisualSampleEntryBox) Encode(w io.Writer) error {
 	err := EncodeHeader(b, w)
@@ -241,6 +252,22 @@ func (b *VisualSampleEntryBox) Info(w io.Writer, specificBoxLevels, indent, inde
 	return nil
 }
 
+// OriginalFormat - original (unencrypted) sample entry type from sinf/frma if present, otherwise the box type
+func (b *VisualSampleEntryBox) OriginalFormat() string {
+	if b.Sinf != nil && b.Sinf.Frma != nil {
+		return b.Sinf.Frma.DataFormat
+	}
+	return b.name
+}
+
+// Tenc - tenc box in sinf/schi, or nil if not present
+func (b *VisualSampleEntryBox) Tenc() *TencBox {
+	if b.Sinf == nil {
+		return nil
+	}
+	return b.Sinf.Tenc()
+}
+
 // RemoveEncryption - remove sinf box and set type to unencrypted type
 func (b *VisualSampleEntryBox) RemoveEncryption() (*SinfBox, error) {
 	if b.name != "encv" {
@@ -3,6 +3,8 @@ package mp4
 import (
 	"fmt"
 	"io"
+	"math"
+	"strings"
 
 	"github.com/edgeware/mp4ff/bits"
 )
@@ -45,7 +47,7 @@ func (b *WvttBox) AddChild(child Box) {
 const nrWvttBytesBeforeChildren = 16
 
 // DecodeWvtt - Decoder wvtt Sample Entry (wvtt)
-func DecodeWvtt(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeWvtt(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -55,18 +57,24 @@ func DecodeWvtt(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeWvttSR - Decoder wvtt Sample Entry (wvtt)
-func DecodeWvttSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeWvttSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	if hdr.PayloadLen() < 8 {
+		return nil, fmt.Errorf("wvtt payload size %d is less than 8", hdr.PayloadLen())
+	}
 	w := WvttBox{}
 	// 14496-12 8.5.2.2 Sample entry (8 bytes)
 	sr.SkipBytes(6) // Skip 6 reserved bytes
 	w.DataReferenceIndex = sr.ReadUint16()
+	if err := sr.AccError(); err != nil {
+		return nil, err
+	}
 	pos := startPos + nrWvttBytesBeforeChildren
-	endPos := startPos + uint64(hdr.hdrlen+hdr.payloadLen())
+	endPos := startPos + uint64(hdr.hdrlen+hdr.PayloadLen())
 	for {
 		if pos >= endPos {
 			break
 		}
-		box, err := DecodeBoxSR(pos, sr)
+		box, err := decodeBoxSR(pos, sr, hdr.opts)
 		if err != nil {
 			return nil, err
 		}
@@ -77,9 +85,47 @@ func DecodeWvttSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, err
 			return nil, fmt.Errorf("no child of wvtt")
 		}
 	}
+	if pos != endPos {
+		return nil, fmt.Errorf("wvtt children end at %d instead of %d", pos, endPos)
+	}
 	return &w, nil
 }
 
+// SourceLabel - source label from vlab box, or empty string if no vlab box
+func (b *WvttBox) SourceLabel() string {
+	if b.Vlab == nil {
+		return ""
+	}
+	return b.Vlab.SourceLabel
+}
+
+// CueSource - source of a vttc cue given by the track source label and the cue vsid box
+type CueSource struct {
+	SourceLabel string
+	SourceID    uint32
+	HasSourceID bool // false if the cue has no vsid box
+}
+
+// CueSources - source label and source ID for every vttc box among the boxes of a wvtt sample.
+// The boxes are typically the output of DecodeWvttSample. Other boxes like vtta are skipped.
+func (b *WvttBox) CueSources(sampleBoxes []Box) []CueSource {
+	label := b.SourceLabel()
+	var sources []CueSource
+	for _, box := range sampleBoxes {
+		vttc, ok := box.(*VttcBox)
+		if !ok {
+			continue
+		}
+		cs := CueSource{SourceLabel: label}
+		if vttc.Vsid != nil {
+			cs.SourceID = vttc.Vsid.SourceID
+			cs.HasSourceID = true
+		}
+		sources = append(sources, cs)
+	}
+	return sources
+}
+
 // Type - return box type
 func (b *WvttBox) Type() string {
 	return "wvtt"
@@ -94,6 +140,11 @@ func (b *WvttBox) Size() uint64 {
 	return totalSize
 }
 
+// GetChildren - list of child boxes
+func (b *WvttBox) GetChildren() []Box {
+	return b.Children
+}
+
 // Encode - write box to w
 func (b *WvttBox) Encode(w io.Writer) error {
 	err := EncodeHeader(b, w)
@@ -142,12 +193,15 @@ func (b *WvttBox) EncodeSW(sw bits.SliceWriter) error {
 // Info - write box-specific information
 func (b *WvttBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
 	bd := newInfoDumper(w, indent, b, -1, 0)
+	if getInfoLevel(b, specificBoxLevels) > 0 {
+		bd.write(" - dataReferenceIndex: %d", b.DataReferenceIndex)
+	}
 	if bd.err != nil {
 		return bd.err
 	}
 	var err error
 	for _, child := range b.Children {
-		err = child.Info(w, specificBoxLevels, indent+indentStep, indent)
+		err = child.Info(w, specificBoxLevels, indent+indentStep, indentStep)
 		if err != nil {
 			return err
 		}
@@ -163,7 +217,7 @@ type VttCBox struct {
 }
 
 // DecodeVttC - box-specific decode
-func DecodeVttC(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeVttC(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -173,8 +227,8 @@ func DecodeVttC(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeVttCSR - box-specific decode
-func DecodeVttCSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
-	return &VttCBox{Config: sr.ReadFixedLengthString(hdr.payloadLen())}, sr.AccError()
+func DecodeVttCSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	return &VttCBox{Config: sr.ReadFixedLengthString(hdr.PayloadLen())}, sr.AccError()
 }
 
 // Type - box-specific type
@@ -211,7 +265,7 @@ func (b *VttCBox) EncodeSW(sw bits.SliceWriter) error {
 // Info - write box-specific information
 func (b *VttCBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
 	bd := newInfoDumper(w, indent, b, -1, 0)
-	bd.write(" - config: %q", b.Config)
+	bd.write(" - config: %s", infoString(b.Config, true))
 	return bd.err
 }
 
@@ -223,7 +277,7 @@ type VlabBox struct {
 }
 
 // DecodeVlab - box-specific decode
-func DecodeVlab(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeVlab(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -233,8 +287,8 @@ func DecodeVlab(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeVlabSR - box-specific decode
-func DecodeVlabSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
-	return &VlabBox{SourceLabel: sr.ReadFixedLengthString(hdr.payloadLen())}, sr.AccError()
+func DecodeVlabSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	return &VlabBox{SourceLabel: sr.ReadFixedLengthString(hdr.PayloadLen())}, sr.AccError()
 }
 
 // Type - box-specific type
@@ -271,12 +325,53 @@ func (b *VlabBox) EncodeSW(sw bits.SliceWriter) error {
 // Info - write box-specific information
 func (b *VlabBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
 	bd := newInfoDumper(w, indent, b, -1, 0)
-	bd.write(" - sourceLabel: %s", b.SourceLabel)
+	bd.write(" - sourceLabel: %s", infoString(b.SourceLabel, false))
 	return bd.err
 }
 
 // wvtt Sample boxes
-// A sample is either one vtte box or one or more vttc or vta boxes
+// A sample is either one vtte box or one or more vttc or vtta boxes
+
+// DecodeWvttSample - decode and validate the boxes of a wvtt sample
+func DecodeWvttSample(data []byte) ([]Box, error) {
+	sr := bits.NewFixedSliceReader(data)
+	var boxes []Box
+	var pos uint64
+	for sr.NrRemainingBytes() > 0 {
+		box, err := DecodeBoxSR(pos, sr)
+		if err != nil {
+			return nil, err
+		}
+		boxes = append(boxes, box)
+		pos += box.Size()
+	}
+	err := ValidateWvttSample(boxes)
+	if err != nil {
+		return nil, err
+	}
+	return boxes, nil
+}
+
+// ValidateWvttSample - check that sample boxes are either one vtte box or one or more vttc/vtta boxes
+func ValidateWvttSample(boxes []Box) error {
+	if len(boxes) == 0 {
+		return fmt.Errorf("empty wvtt sample")
+	}
+	nrVtte := 0
+	for _, box := range boxes {
+		switch box.Type() {
+		case "vtte":
+			nrVtte++
+		case "vttc", "vtta":
+		default:
+			return fmt.Errorf("box %s not allowed in wvtt sample", box.Type())
+		}
+	}
+	if nrVtte > 0 && len(boxes) > 1 {
+		return fmt.Errorf("vtte mixed with %d other boxes in wvtt sample", len(boxes)-1)
+	}
+	return nil
+}
 
 ////////////////////////////// vtte //////////////////////////////
 
@@ -290,12 +385,18 @@ func (b *VtteBox) Type() string {
 }
 
 // DecodeVtte - box-specific decode
-func DecodeVtte(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeVtte(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
+	if hdr.PayloadLen() != 0 {
+		return nil, fmt.Errorf("vtte payload size %d is not 0", hdr.PayloadLen())
+	}
 	return &VtteBox{}, nil
 }
 
 // DecodeVtteSR - box-specific decode
-func DecodeVtteSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeVtteSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	if hdr.PayloadLen() != 0 {
+		return nil, fmt.Errorf("vtte payload size %d is not 0", hdr.PayloadLen())
+	}
 	return &VtteBox{}, nil
 }
 
@@ -329,6 +430,8 @@ type VttcBox struct {
 	Ctim     *CtimBox
 	Sttg     *SttgBox
 	Payl     *PaylBox
+	Vtta     *VttaBox   // The first
+	Vttas    []*VttaBox // All
 	Children []Box
 }
 
@@ -346,14 +449,107 @@ func (b *VttcBox) AddChild(child Box) {
 		b.Sttg = box
 	case *PaylBox:
 		b.Payl = box
+	case *VttaBox:
+		if b.Vtta == nil {
+			b.Vtta = box
+		}
+		b.Vttas = append(b.Vttas, box)
 	default:
 		// Type outside ISO/IEC 14496-30 spec
 	}
 	b.Children = append(b.Children, child)
 }
 
+// CreateVttcBox - create a vttc box with optional iden and sttg boxes, and a payl box.
+// cueID and settings are left out if empty. If normalizeLineEndings is true,
+// CRLF and CR line endings in settings and cueText are replaced by LF as required by WebVTT.
+func CreateVttcBox(cueID, settings, cueText string, normalizeLineEndings bool) *VttcBox {
+	if normalizeLineEndings {
+		settings = NormalizeVTTLineEndings(settings)
+		cueText = NormalizeVTTLineEndings(cueText)
+	}
+	b := &VttcBox{}
+	if cueID != "" {
+		b.AddChild(&IdenBox{CueID: cueID})
+	}
+	if settings != "" {
+		b.AddChild(&SttgBox{Settings: settings})
+	}
+	b.AddChild(&PaylBox{CueText: cueText})
+	return b
+}
+
+// BuildSubtitleSegment - create a one-sample wvtt fragment with a vttc cue with optional settings.
+// The sample starts at startTime and lasts duration, both in the track timescale.
+func BuildSubtitleSegment(seqNr, trackID uint32, startTime, duration uint64, cueText, settings string) (*Fragment, error) {
+	if duration > math.MaxUint32 {
+		return nil, fmt.Errorf("duration %d too big for a sample", duration)
+	}
+	frag, err := CreateFragment(seqNr, trackID)
+	if err != nil {
+		return nil, err
+	}
+	vttc := CreateVttcBox("", settings, cueText, true)
+	sw := bits.NewFixedSliceWriter(int(vttc.Size()))
+	err = vttc.EncodeSW(sw)
+	if err != nil {
+		return nil, err
+	}
+	data := sw.Bytes()
+	frag.AddFullSample(FullSample{
+		Sample:     NewSample(SyncSampleFlags, uint32(duration), uint32(len(data)), 0),
+		DecodeTime: startTime,
+		Data:       data,
+	})
+	frag.SetTrunDataOffsets()
+	return frag, nil
+}
+
+// CreateEmptyWvttSample - create sample with a single vtte box lasting dur ticks.
+// DecodeTime is zero and should be set by the caller.
+func CreateEmptyWvttSample(dur uint32) *FullSample {
+	return createWvttSample(&VtteBox{}, dur)
+}
+
+// CreateWvttCueSample - create sample with a single vttc box lasting dur ticks.
+// Empty iden and settings result in no iden and sttg boxes.
+// DecodeTime is zero and should be set by the caller.
+func CreateWvttCueSample(iden, settings, payload string, dur uint32) *FullSample {
+	return createWvttSample(CreateVttcBox(iden, settings, payload, false), dur)
+}
+
+// createWvttSample - create sync sample with box encoded as data
+func createWvttSample(box Box, dur uint32) *FullSample {
+	size := box.Size()
+	sw := bits.NewFixedSliceWriter(int(size))
+	_ = box.EncodeSW(sw) // Cannot fail since the slice has the size of the box
+	return &FullSample{
+		Sample: NewSample(SyncSampleFlags, dur, uint32(size), 0),
+		Data:   sw.Bytes(),
+	}
+}
+
+// NormalizeLineEndings - replace CRLF and CR line endings by LF in sttg settings and payl cue text
+func (b *VttcBox) NormalizeLineEndings() {
+	if b.Sttg != nil {
+		b.Sttg.Settings = NormalizeVTTLineEndings(b.Sttg.Settings)
+	}
+	if b.Payl != nil {
+		b.Payl.CueText = NormalizeVTTLineEndings(b.Payl.CueText)
+	}
+}
+
+// NormalizeVTTLineEndings - replace CRLF and CR line endings by LF
+func NormalizeVTTLineEndings(s string) string {
+	if !strings.Contains(s, "\r") {
+		return s
+	}
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(s, "\r", "\n")
+}
+
 // DecodeVttc - box-specific decode
-func DecodeVttc(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeVttc(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	children, err := DecodeContainerChildren(hdr, startPos+8, startPos+hdr.size, r)
 	if err != nil {
 		return nil, err
@@ -366,7 +562,7 @@ func DecodeVttc(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeVttcSR - box-specific decode
-func DecodeVttcSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeVttcSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
 	children, err := DecodeContainerChildrenSR(hdr, startPos+8, startPos+hdr.size, sr)
 	if err != nil {
 		return nil, err
@@ -416,7 +612,7 @@ type VsidBox struct {
 }
 
 // DecodeVsid - box-specific decode
-func DecodeVsid(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeVsid(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -426,7 +622,10 @@ func DecodeVsid(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeVsidSR - box-specific decode
-func DecodeVsidSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+func DecodeVsidSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	if hdr.PayloadLen() != 4 {
+		return nil, fmt.Errorf("vsid payload size %d is not 4", hdr.PayloadLen())
+	}
 	return &VsidBox{SourceID: sr.ReadUint32()}, sr.AccError()
 }
 
@@ -477,7 +676,7 @@ type CtimBox struct {
 }
 
 // DecodeCtim - box-specific decode
-func DecodeCtim(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeCtim(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -487,8 +686,8 @@ func DecodeCtim(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeCtimSR - box-specific decode
-func DecodeCtimSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
-	return &CtimBox{CueCurrentTime: sr.ReadFixedLengthString(hdr.payloadLen())}, sr.AccError()
+func DecodeCtimSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	return &CtimBox{CueCurrentTime: sr.ReadFixedLengthString(hdr.PayloadLen())}, sr.AccError()
 }
 
 // Type - box-specific type
@@ -525,7 +724,7 @@ func (b *CtimBox) EncodeSW(sw bits.SliceWriter) error {
 // Info - write box-specific information
 func (b *CtimBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
 	bd := newInfoDumper(w, indent, b, -1, 0)
-	bd.write(" - cueCurrentTime: %s", b.CueCurrentTime)
+	bd.write(" - cueCurrentTime: %s", infoString(b.CueCurrentTime, false))
 	return bd.err
 }
 
@@ -537,7 +736,7 @@ type IdenBox struct {
 }
 
 // DecodeIden - box-specific decode
-func DecodeIden(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeIden(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -547,8 +746,8 @@ func DecodeIden(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeIdenSR - box-specific decode
-func DecodeIdenSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
-	return &IdenBox{CueID: sr.ReadFixedLengthString(hdr.payloadLen())}, sr.AccError()
+func DecodeIdenSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	return &IdenBox{CueID: sr.ReadFixedLengthString(hdr.PayloadLen())}, sr.AccError()
 }
 
 // Type - box-specific type
@@ -585,7 +784,7 @@ func (b *IdenBox) EncodeSW(sw bits.SliceWriter) error {
 // Info - write box-specific information
 func (b *IdenBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
 	bd := newInfoDumper(w, indent, b, -1, 0)
-	bd.write(" - cueID: %s", b.CueID)
+	bd.write(" - cueID: %s", infoString(b.CueID, false))
 	return bd.err
 }
 
@@ -597,7 +796,7 @@ type SttgBox struct {
 }
 
 // DecodeSttg - box-specific decode
-func DecodeSttg(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeSttg(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -607,8 +806,8 @@ func DecodeSttg(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeSttgSR - box-specific decode
-func DecodeSttgSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
-	return &SttgBox{Settings: sr.ReadFixedLengthString(hdr.payloadLen())}, sr.AccError()
+func DecodeSttgSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	return &SttgBox{Settings: sr.ReadFixedLengthString(hdr.PayloadLen())}, sr.AccError()
 }
 
 // Type - box-specific type
@@ -645,7 +844,7 @@ func (b *SttgBox) EncodeSW(sw bits.SliceWriter) error {
 // Info - write box-specific information
 func (b *SttgBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
 	bd := newInfoDumper(w, indent, b, -1, 0)
-	bd.write(" - settings: %s", b.Settings)
+	bd.write(" - settings: %s", infoString(b.Settings, false))
 	return bd.err
 }
 
@@ -657,7 +856,7 @@ type PaylBox struct {
 }
 
 // DecodePayl - box-specific decode
-func DecodePayl(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodePayl(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -667,8 +866,8 @@ func DecodePayl(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodePaylSR - box-specific decode
-func DecodePaylSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
-	return &PaylBox{CueText: sr.ReadFixedLengthString(hdr.payloadLen())}, sr.AccError()
+func DecodePaylSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	return &PaylBox{CueText: sr.ReadFixedLengthString(hdr.PayloadLen())}, sr.AccError()
 }
 
 // Type - box-specific type
@@ -705,7 +904,7 @@ func (b *PaylBox) EncodeSW(sw bits.SliceWriter) error {
 // Info - write box-specific information
 func (b *PaylBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
 	bd := newInfoDumper(w, indent, b, -1, 0)
-	bd.write(" - cueText: %q", b.CueText)
+	bd.write(" - cueText: %s", infoString(b.CueText, true))
 	return bd.err
 }
 
@@ -717,7 +916,7 @@ type VttaBox struct {
 }
 
 // DecodeVtta - box-specific decode
-func DecodeVtta(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
+func DecodeVtta(hdr BoxHeader, startPos uint64, r io.Reader) (Box, error) {
 	data, err := readBoxBody(r, hdr)
 	if err != nil {
 		return nil, err
@@ -727,8 +926,8 @@ func DecodeVtta(hdr boxHeader, startPos uint64, r io.Reader) (Box, error) {
 }
 
 // DecodeVttaSR - box-specific decode
-func DecodeVttaSR(hdr boxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
-	return &VttaBox{CueAdditionalText: sr.ReadFixedLengthString(hdr.payloadLen())}, sr.AccError()
+func DecodeVttaSR(hdr BoxHeader, startPos uint64, sr bits.SliceReader) (Box, error) {
+	return &VttaBox{CueAdditionalText: sr.ReadFixedLengthString(hdr.PayloadLen())}, sr.AccError()
 }
 
 // Type - box-specific type
@@ -765,6 +964,6 @@ func (b *VttaBox) EncodeSW(sw bits.SliceWriter) error {
 // Info - write box-specific information
 func (b *VttaBox) Info(w io.Writer, specificBoxLevels, indent, indentStep string) error {
 	bd := newInfoDumper(w, indent, b, -1, 0)
-	bd.write(" - cueAdditionalText: %q", b.CueAdditionalText)
+	bd.write(" - cueAdditionalText: %s", infoString(b.CueAdditionalText, true))
 	return bd.err
 }
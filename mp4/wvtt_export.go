@@ -0,0 +1,232 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteWebVTT - write wvtt samples as a WebVTT file to w.
+// The file header is taken from vttC if present, and is otherwise "WEBVTT".
+// Cue times are presentation times of the samples converted from timescale to HH:MM:SS.mmm.
+// Every vttc box in a sample gives a cue, and cues split over consecutive samples
+// are merged into one by MergeSplitCues.
+// Empty samples (vtte) give no cue, while vtta boxes are written as they are.
+func WriteWebVTT(w io.Writer, samples []*FullSample, vttC *VttCBox, timescale uint32) error {
+	return WriteWebVTTWithOptions(w, samples, vttC, timescale, WebVTTWriteOptions{})
+}
+
+// WebVTTWriteOptions - options for WriteWebVTTWithOptions
+type WebVTTWriteOptions struct {
+	StripCueTags bool // Write cue text as plain text by applying StripCueTags
+}
+
+// WriteWebVTTWithOptions - write wvtt samples as a WebVTT file like WriteWebVTT, with changes given by opts.
+func WriteWebVTTWithOptions(w io.Writer, samples []*FullSample, vttC *VttCBox, timescale uint32, opts WebVTTWriteOptions) error {
+	if timescale == 0 {
+		return fmt.Errorf("timescale is zero")
+	}
+	cues, notes, err := extractWebVTTBlocks(samples)
+	if err != nil {
+		return err
+	}
+	cues = MergeSplitCues(cues)
+	_, err = fmt.Fprintf(w, "%s\n", webVTTHeaderBlock(vttC))
+	if err != nil {
+		return err
+	}
+	for len(cues) > 0 || len(notes) > 0 {
+		if len(notes) > 0 && (len(cues) == 0 || notes[0].Start < cues[0].Start) {
+			_, err = fmt.Fprintf(w, "\n%s\n", strings.TrimRight(notes[0].Text, "\n"))
+			if err != nil {
+				return err
+			}
+			notes = notes[1:]
+			continue
+		}
+		c := cues[0]
+		msg := "\n"
+		if c.ID != "" {
+			msg += c.ID + "\n"
+		}
+		msg += webVTTTimestamp(c.Start, timescale) + " --> " + webVTTTimestamp(c.End, timescale)
+		if c.Settings != "" {
+			msg += " " + c.Settings
+		}
+		text := c.Text
+		if opts.StripCueTags {
+			text = StripCueTags(text)
+		}
+		msg += "\n" + strings.TrimRight(text, "\n") + "\n"
+		_, err = io.WriteString(w, msg)
+		if err != nil {
+			return err
+		}
+		cues = cues[1:]
+	}
+	return nil
+}
+
+// WebVTTHeader - WebVTT file header from the vttC config, ending with an empty line.
+// The vlab source label, if any, is added after "WEBVTT" on the first line
+// unless that line already has text. Without vttC, the header is "WEBVTT\n\n".
+func (b *WvttBox) WebVTTHeader() string {
+	if b.VttC == nil {
+		return "WEBVTT\n\n"
+	}
+	header := webVTTHeaderBlock(b.VttC)
+	if b.Vlab != nil && b.Vlab.SourceLabel != "" {
+		if header == "WEBVTT" || strings.HasPrefix(header, "WEBVTT\n") {
+			header = "WEBVTT " + b.Vlab.SourceLabel + header[len("WEBVTT"):]
+		}
+	}
+	return header + "\n\n"
+}
+
+// webVTTHeaderBlock - header block from vttC without trailing newlines, or "WEBVTT" if there is no config
+func webVTTHeaderBlock(vttC *VttCBox) string {
+	if vttC == nil || vttC.Config == "" {
+		return "WEBVTT"
+	}
+	return strings.TrimRight(vttC.Config, "\n")
+}
+
+// WebVTTCue - WebVTT cue extracted from a wvtt sample.
+// Start and End are presentation times in the track timescale.
+// CueCurrentTime is the value of the ctim box, which is present in samples
+// that continue a cue which started in an earlier sample.
+type WebVTTCue struct {
+	ID             string
+	Settings       string
+	Text           string
+	Start          uint64
+	End            uint64
+	CueCurrentTime string
+}
+
+// ExtractWebVTTCues - one cue per vttc box in the samples, in sample order.
+// Cues split over multiple samples are not merged. Use MergeSplitCues for that.
+func ExtractWebVTTCues(samples []*FullSample) ([]WebVTTCue, error) {
+	cues, _, err := extractWebVTTBlocks(samples)
+	return cues, err
+}
+
+// extractWebVTTBlocks - cues from vttc boxes and notes from vtta boxes in sample order.
+// Only Start and Text are set for the notes.
+func extractWebVTTBlocks(samples []*FullSample) (cues, notes []WebVTTCue, err error) {
+	for i, s := range samples {
+		boxes, err := DecodeWvttSample(s.Data)
+		if err != nil {
+			return nil, nil, fmt.Errorf("sample %d: %w", i+1, err)
+		}
+		start := s.PresentationTime()
+		for _, box := range boxes {
+			switch b := box.(type) {
+			case *VttcBox:
+				c := WebVTTCue{Start: start, End: start + uint64(s.Dur)}
+				if b.Iden != nil {
+					c.ID = b.Iden.CueID
+				}
+				if b.Sttg != nil {
+					c.Settings = b.Sttg.Settings
+				}
+				if b.Payl != nil {
+					c.Text = b.Payl.CueText
+				}
+				if b.Ctim != nil {
+					c.CueCurrentTime = b.Ctim.CueCurrentTime
+				}
+				cues = append(cues, c)
+			case *VttaBox:
+				notes = append(notes, WebVTTCue{Start: start, Text: b.CueAdditionalText})
+			}
+		}
+	}
+	return cues, notes, nil
+}
+
+// MergeSplitCues - coalesce cues that are split over consecutive samples into one cue.
+// A cue continues an earlier cue if it starts where the earlier one ends and
+// has the same iden, settings, and payload. A cue with iden must also have a ctim box,
+// which marks the later parts of a split cue, while cues without iden are matched
+// on payload and adjacency only.
+// A merged cue spans the full time range and has the CueCurrentTime of its first part.
+// The order of first appearance is kept.
+func MergeSplitCues(cues []WebVTTCue) []WebVTTCue {
+	merged := make([]WebVTTCue, 0, len(cues))
+	for _, c := range cues {
+		prev := -1
+		if c.ID == "" || c.CueCurrentTime != "" {
+			for i := len(merged) - 1; i >= 0; i-- {
+				m := &merged[i]
+				if m.End == c.Start && m.ID == c.ID && m.Settings == c.Settings && m.Text == c.Text {
+					prev = i
+					break
+				}
+			}
+		}
+		if prev >= 0 {
+			merged[prev].End = c.End
+			continue
+		}
+		merged = append(merged, c)
+	}
+	return merged
+}
+
+// webVTTEntities - character references allowed in WebVTT cue text and their values
+var webVTTEntities = []struct {
+	ref   string
+	value string
+}{
+	{"&amp;", "&"},
+	{"&lt;", "<"},
+	{"&gt;", ">"},
+	{"&lrm;", "\u200e"},
+	{"&rlm;", "\u200f"},
+	{"&nbsp;", "\u00a0"},
+}
+
+// StripCueTags - cue text without WebVTT markup, for plain-text use such as search indexing.
+// Span tags like <c.classname>, <v Speaker>, <i>, and their end tags are removed
+// as well as timestamp tags like <00:01.500>. Character references such as &amp; and &lt;
+// are decoded. A "<" without a closing ">" on the same line is kept as text, and so is
+// an "&" which does not start a known character reference.
+func StripCueTags(text string) string {
+	var sb strings.Builder
+	for i := 0; i < len(text); {
+		switch text[i] {
+		case '<':
+			end := strings.IndexAny(text[i:], ">\n")
+			if end > 0 && text[i+end] == '>' {
+				i += end + 1
+				continue
+			}
+		case '&':
+			decoded := false
+			for _, e := range webVTTEntities {
+				if strings.HasPrefix(text[i:], e.ref) {
+					sb.WriteString(e.value)
+					i += len(e.ref)
+					decoded = true
+					break
+				}
+			}
+			if decoded {
+				continue
+			}
+		}
+		sb.WriteByte(text[i])
+		i++
+	}
+	return sb.String()
+}
+
+// webVTTTimestamp - time in timescale as WebVTT timestamp HH:MM:SS.mmm
+func webVTTTimestamp(t uint64, timescale uint32) string {
+	ms := (t*1000 + uint64(timescale)/2) / uint64(timescale)
+	hours := ms / 3600000
+	minutes := (ms / 60000) % 60
+	seconds := (ms / 1000) % 60
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", hours, minutes, seconds, ms%1000)
+}
@@ -0,0 +1,174 @@
+package mp4
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/edgeware/mp4ff/bits"
+	"github.com/go-test/deep"
+)
+
+// wvttSample - create a wvtt sample with the given boxes
+func wvttSample(t *testing.T, decodeTime uint64, dur uint32, boxes ...Box) *FullSample {
+	t.Helper()
+	var size uint64
+	for _, b := range boxes {
+		size += b.Size()
+	}
+	sw := bits.NewFixedSliceWriter(int(size))
+	for _, b := range boxes {
+		err := b.EncodeSW(sw)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return &FullSample{
+		Sample:     NewSample(SyncSampleFlags, dur, uint32(size), 0),
+		DecodeTime: decodeTime,
+		Data:       sw.Bytes(),
+	}
+}
+
+func TestWriteWebVTT(t *testing.T) {
+	samples := []*FullSample{
+		wvttSample(t, 0, 1000, CreateVttcBox("", "", "A", false)),
+		wvttSample(t, 1000, 1000, CreateVttcBox("", "", "A", false), CreateVttcBox("", "", "B", false)),
+		wvttSample(t, 2000, 1000, &VtteBox{}),
+		wvttSample(t, 3000, 1000, CreateVttcBox("c1", "align:left", "C\nline 2", false)),
+		wvttSample(t, 3600000, 500, &VttaBox{CueAdditionalText: "NOTE end"}),
+	}
+	buf := bytes.Buffer{}
+	err := WriteWebVTT(&buf, samples, &VttCBox{Config: "WEBVTT\n\nSTYLE\n::cue { color: lime }"}, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `WEBVTT
+
+STYLE
+::cue { color: lime }
+
+00:00:00.000 --> 00:00:02.000
+A
+
+00:00:01.000 --> 00:00:02.000
+B
+
+c1
+00:00:03.000 --> 00:00:04.000 align:left
+C
+line 2
+
+NOTE end
+`
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+	tagged := []*FullSample{
+		wvttSample(t, 0, 1000, CreateVttcBox("c1", "", "<v Roger>Tom &amp; <i>Jerry</i>", false)),
+	}
+	for _, strip := range []bool{false, true} {
+		buf.Reset()
+		err = WriteWebVTTWithOptions(&buf, tagged, nil, 1000, WebVTTWriteOptions{StripCueTags: strip})
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantText := "<v Roger>Tom &amp; <i>Jerry</i>"
+		if strip {
+			wantText = "Tom & Jerry"
+		}
+		want = "WEBVTT\n\nc1\n00:00:00.000 --> 00:00:01.000\n" + wantText + "\n"
+		if buf.String() != want {
+			t.Errorf("strip=%t: got %q instead of %q", strip, buf.String(), want)
+		}
+	}
+	if ts := webVTTTimestamp(3723004, 1000); ts != "01:02:03.004" {
+		t.Errorf("got timestamp %s", ts)
+	}
+}
+
+func TestMergeSplitCues(t *testing.T) {
+	splitCue := func(ctim string) *VttcBox {
+		b := &VttcBox{}
+		if ctim != "" {
+			b.AddChild(&CtimBox{CueCurrentTime: ctim})
+		}
+		b.AddChild(&IdenBox{CueID: "c1"})
+		b.AddChild(&PaylBox{CueText: "Split"})
+		return b
+	}
+	samples := []*FullSample{
+		wvttSample(t, 0, 1000, splitCue(""), CreateVttcBox("", "", "A", false)),
+		wvttSample(t, 1000, 1000, splitCue("00:00:01.000"), CreateVttcBox("", "", "A", false)),
+		wvttSample(t, 2000, 1000, splitCue("00:00:02.000")),
+		wvttSample(t, 3000, 1000, &VtteBox{}),
+		wvttSample(t, 4000, 1000, CreateVttcBox("", "", "A", false)),
+		// Same iden and payload but no ctim, so new cues
+		wvttSample(t, 5000, 1000, splitCue("")),
+		wvttSample(t, 6000, 1000, splitCue("")),
+	}
+	cues, err := ExtractWebVTTCues(samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cues) != 8 {
+		t.Fatalf("got %d cues instead of 8", len(cues))
+	}
+	if cues[2].CueCurrentTime != "00:00:01.000" {
+		t.Errorf("got ctim %q", cues[2].CueCurrentTime)
+	}
+	got := MergeSplitCues(cues)
+	want := []WebVTTCue{
+		{ID: "c1", Text: "Split", Start: 0, End: 3000},
+		{Text: "A", Start: 0, End: 2000},
+		{Text: "A", Start: 4000, End: 5000},
+		{ID: "c1", Text: "Split", Start: 5000, End: 6000},
+		{ID: "c1", Text: "Split", Start: 6000, End: 7000},
+	}
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestWebVTTHeader(t *testing.T) {
+	testCases := []struct {
+		wvtt   *WvttBox
+		wanted string
+	}{
+		{&WvttBox{}, "WEBVTT\n\n"},
+		{&WvttBox{VttC: &VttCBox{Config: "WEBVTT\n\nSTYLE\n::cue { color: lime }\n"}},
+			"WEBVTT\n\nSTYLE\n::cue { color: lime }\n\n"},
+		{&WvttBox{VttC: &VttCBox{Config: "WEBVTT\n\nREGION\nid:r1"}, Vlab: &VlabBox{SourceLabel: "src"}},
+			"WEBVTT src\n\nREGION\nid:r1\n\n"},
+		{&WvttBox{VttC: &VttCBox{Config: "WEBVTT - Title"}, Vlab: &VlabBox{SourceLabel: "src"}},
+			"WEBVTT - Title\n\n"},
+	}
+	for i, tc := range testCases {
+		if got := tc.wvtt.WebVTTHeader(); got != tc.wanted {
+			t.Errorf("case %d: got %q instead of %q", i, got, tc.wanted)
+		}
+	}
+}
+
+func TestStripCueTags(t *testing.T) {
+	testCases := []struct {
+		in   string
+		want string
+	}{
+		{"plain text", "plain text"},
+		{"<v Roger Bingham>We are in New York City", "We are in New York City"},
+		{"<c.yellow.bg_blue>Yellow</c> and <i>italic <b>bold</b></i>", "Yellow and italic bold"},
+		{"<00:00:01.000>karaoke <00:00:01.500>style", "karaoke style"},
+		{"Tom &amp; Jerry &lt;3 &gt;&gt;", "Tom & Jerry <3 >>"},
+		{"&amp;lt; is not decoded twice", "&lt; is not decoded twice"},
+		{"&lrm;left&rlm;", "\u200eleft\u200f"},
+		{"a & b &unknown;", "a & b &unknown;"},
+		{"1 < 2\nnext line", "1 < 2\nnext line"},
+		{"unclosed <b", "unclosed <b"},
+		{"<v Speaker>two\n<i>lines</i>", "two\nlines"},
+	}
+	for _, tc := range testCases {
+		if got := StripCueTags(tc.in); got != tc.want {
+			t.Errorf("StripCueTags(%q) = %q instead of %q", tc.in, got, tc.want)
+		}
+	}
+}
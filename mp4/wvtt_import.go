@@ -0,0 +1,210 @@
+package mp4
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/edgeware/mp4ff/bits"
+)
+
+// ParseWebVTT - parse a WebVTT file into a vttC box and wvtt samples with times in timescale.
+// The header, STYLE, and REGION blocks are put in the vttC config, while NOTE blocks are skipped.
+// Every sample covers an interval where the set of active cues does not change,
+// so overlapping cues are split into several samples with one vttc box per active cue.
+// The later parts of a split cue have a ctim box with the sample start time.
+// Intervals without cues, including the one before the first cue, get empty vtte samples.
+func ParseWebVTT(r io.Reader, timescale uint32) (vttC *VttCBox, samples []*FullSample, err error) {
+	if timescale == 0 {
+		return nil, nil, fmt.Errorf("timescale is zero")
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	text := strings.TrimPrefix(NormalizeVTTLineEndings(string(data)), "\ufeff")
+	blocks := splitWebVTTBlocks(text)
+	if len(blocks) == 0 || !isWebVTTHeader(blocks[0]) {
+		return nil, nil, fmt.Errorf("no WEBVTT header")
+	}
+	configBlocks := []string{blocks[0]}
+	var cues []WebVTTCue
+	for _, block := range blocks[1:] {
+		switch {
+		case hasWebVTTKeyword(block, "NOTE"):
+			continue
+		case hasWebVTTKeyword(block, "STYLE") || hasWebVTTKeyword(block, "REGION"):
+			if len(cues) > 0 {
+				return nil, nil, fmt.Errorf("%s block after first cue", strings.Fields(block)[0])
+			}
+			configBlocks = append(configBlocks, block)
+		default:
+			c, err := parseWebVTTCue(block, timescale)
+			if err != nil {
+				return nil, nil, err
+			}
+			cues = append(cues, c)
+		}
+	}
+	vttC = &VttCBox{Config: strings.Join(configBlocks, "\n\n")}
+	samples, err = webVTTCuesToSamples(cues, timescale)
+	if err != nil {
+		return nil, nil, err
+	}
+	return vttC, samples, nil
+}
+
+// isWebVTTHeader - does the block start with WEBVTT followed by end, space, tab, or newline
+func isWebVTTHeader(block string) bool {
+	return hasWebVTTKeyword(block, "WEBVTT")
+}
+
+// hasWebVTTKeyword - does the block start with keyword followed by end, space, tab, or newline.
+// A cue identifier like NOTES1 is thus not taken as a NOTE block.
+func hasWebVTTKeyword(block, keyword string) bool {
+	if !strings.HasPrefix(block, keyword) {
+		return false
+	}
+	rest := block[len(keyword):]
+	return rest == "" || rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\n'
+}
+
+// splitWebVTTBlocks - split text with LF line endings into blocks separated by blank lines
+func splitWebVTTBlocks(text string) []string {
+	var blocks []string
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			if len(lines) > 0 {
+				blocks = append(blocks, strings.Join(lines, "\n"))
+				lines = nil
+			}
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) > 0 {
+		blocks = append(blocks, strings.Join(lines, "\n"))
+	}
+	return blocks
+}
+
+// parseWebVTTCue - parse cue block with optional identifier, timing line with optional settings, and payload
+func parseWebVTTCue(block string, timescale uint32) (WebVTTCue, error) {
+	var c WebVTTCue
+	lines := strings.Split(block, "\n")
+	if !strings.Contains(lines[0], "-->") {
+		c.ID = lines[0]
+		lines = lines[1:]
+	}
+	if len(lines) == 0 || !strings.Contains(lines[0], "-->") {
+		return c, fmt.Errorf("no timing line in cue block %q", block)
+	}
+	parts := strings.SplitN(lines[0], "-->", 2)
+	startStr := strings.TrimSpace(parts[0])
+	endFields := strings.Fields(parts[1])
+	if len(endFields) == 0 {
+		return c, fmt.Errorf("no end time in %q", lines[0])
+	}
+	var err error
+	c.Start, err = parseWebVTTTimestamp(startStr, timescale)
+	if err != nil {
+		return c, err
+	}
+	c.End, err = parseWebVTTTimestamp(endFields[0], timescale)
+	if err != nil {
+		return c, err
+	}
+	if c.End <= c.Start {
+		return c, fmt.Errorf("cue end %s not after start %s", endFields[0], startStr)
+	}
+	c.Settings = strings.Join(endFields[1:], " ")
+	c.Text = strings.Join(lines[1:], "\n")
+	return c, nil
+}
+
+// parseWebVTTTimestamp - parse [hh:]mm:ss.ttt and convert to timescale
+func parseWebVTTTimestamp(ts string, timescale uint32) (uint64, error) {
+	parts := strings.Split(ts, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("bad WebVTT timestamp %q", ts)
+	}
+	secParts := strings.Split(parts[len(parts)-1], ".")
+	if len(secParts) != 2 || len(secParts[0]) != 2 || len(secParts[1]) != 3 {
+		return 0, fmt.Errorf("bad WebVTT timestamp %q", ts)
+	}
+	var hours uint64
+	var err error
+	if len(parts) == 3 {
+		hours, err = strconv.ParseUint(parts[0], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("bad WebVTT timestamp %q", ts)
+		}
+	}
+	fields := []string{parts[len(parts)-2], secParts[0], secParts[1]}
+	var values [3]uint64
+	for i, f := range fields {
+		values[i], err = strconv.ParseUint(f, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("bad WebVTT timestamp %q", ts)
+		}
+	}
+	if values[0] > 59 || values[1] > 59 {
+		return 0, fmt.Errorf("bad WebVTT timestamp %q", ts)
+	}
+	ms := ((hours*60+values[0])*60+values[1])*1000 + values[2]
+	return (ms*uint64(timescale) + 500) / 1000, nil
+}
+
+// webVTTCuesToSamples - create samples for all intervals between cue start and end times
+func webVTTCuesToSamples(cues []WebVTTCue, timescale uint32) ([]*FullSample, error) {
+	times := []uint64{0}
+	for _, c := range cues {
+		times = append(times, c.Start, c.End)
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	var samples []*FullSample
+	for i := 1; i < len(times); i++ {
+		start, end := times[i-1], times[i]
+		if start == end {
+			continue
+		}
+		if end-start > 1<<32-1 {
+			return nil, fmt.Errorf("sample duration %d too big", end-start)
+		}
+		var boxes []Box
+		for _, c := range cues {
+			if c.Start <= start && end <= c.End {
+				vttc := CreateVttcBox(c.ID, c.Settings, c.Text, false)
+				if c.Start < start { // Later part of a split cue
+					vttc.Ctim = &CtimBox{CueCurrentTime: webVTTTimestamp(start, timescale)}
+					vttc.Children = append([]Box{vttc.Ctim}, vttc.Children...)
+				}
+				boxes = append(boxes, vttc)
+			}
+		}
+		if len(boxes) == 0 {
+			boxes = append(boxes, &VtteBox{})
+		}
+		var size uint64
+		for _, b := range boxes {
+			size += b.Size()
+		}
+		sw := bits.NewFixedSliceWriter(int(size))
+		for _, b := range boxes {
+			err := b.EncodeSW(sw)
+			if err != nil {
+				return nil, err
+			}
+		}
+		samples = append(samples, &FullSample{
+			Sample:     NewSample(SyncSampleFlags, uint32(end-start), uint32(size), 0),
+			DecodeTime: start,
+			Data:       sw.Bytes(),
+		})
+	}
+	return samples, nil
+}
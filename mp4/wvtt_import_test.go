@@ -0,0 +1,98 @@
+package mp4
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseWebVTT(t *testing.T) {
+	input := "WEBVTT\r\n\r\nSTYLE\r\n::cue { color: lime }\r\n\r\nNOTE skipped\r\n\r\n" +
+		"00:01.000 --> 00:03.000\r\nA\r\n\r\n" +
+		"c2\r\n00:00:02.000 --> 00:00:04.000 align:left\r\nB\r\nline 2\r\n"
+	vttC, samples, err := ParseWebVTT(strings.NewReader(input), 90000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vttC.Config != "WEBVTT\n\nSTYLE\n::cue { color: lime }" {
+		t.Errorf("got config %q", vttC.Config)
+	}
+	wantTimes := []uint64{0, 90000, 180000, 270000}
+	wantNrCues := []int{0, 1, 2, 1}
+	if len(samples) != len(wantTimes) {
+		t.Fatalf("got %d samples instead of %d", len(samples), len(wantTimes))
+	}
+	for i, s := range samples {
+		if s.DecodeTime != wantTimes[i] || s.Dur != 90000 || int(s.Size) != len(s.Data) {
+			t.Errorf("sample %d: decodeTime=%d dur=%d size=%d", i, s.DecodeTime, s.Dur, s.Size)
+		}
+		boxes, err := DecodeWvttSample(s.Data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nrCues := 0
+		for _, b := range boxes {
+			if b.Type() == "vttc" {
+				nrCues++
+			}
+		}
+		if nrCues != wantNrCues[i] {
+			t.Errorf("sample %d: got %d cues instead of %d", i, nrCues, wantNrCues[i])
+		}
+	}
+	cues, err := ExtractWebVTTCues(samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCtims := []string{"", "00:00:02.000", "", "00:00:03.000"}
+	for i, c := range cues {
+		if c.CueCurrentTime != wantCtims[i] {
+			t.Errorf("cue %d: got ctim %q instead of %q", i, c.CueCurrentTime, wantCtims[i])
+		}
+	}
+
+	buf := bytes.Buffer{}
+	err = WriteWebVTT(&buf, samples, vttC, 90000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "WEBVTT\n\nSTYLE\n::cue { color: lime }\n\n" +
+		"00:00:01.000 --> 00:00:03.000\nA\n\n" +
+		"c2\n00:00:02.000 --> 00:00:04.000 align:left\nB\nline 2\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	// Cue identifiers starting with a keyword are cues
+	input = "WEBVTT\n\nNOTE\nskipped\n\nNOTES1\n00:01.000 --> 00:02.000\nA\n\n" +
+		"STYLE2\n00:02.000 --> 00:03.000\nB\n\nREGIONAL\n00:03.000 --> 00:04.000\nC\n"
+	_, samples, err = ParseWebVTT(strings.NewReader(input), 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cues, err = ExtractWebVTTCues(samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotIDs []string
+	for _, c := range cues {
+		gotIDs = append(gotIDs, c.ID)
+	}
+	if strings.Join(gotIDs, ",") != "NOTES1,STYLE2,REGIONAL" {
+		t.Errorf("got cue ids %v", gotIDs)
+	}
+
+	badInputs := []string{
+		"",
+		"WEBVTTX\n",
+		"WEBVTT\n\n00:02.000 --> 00:01.000\nA\n",
+		"WEBVTT\n\n00:01.00 --> 00:02.000\nA\n",
+		"WEBVTT\n\n00:01.000 --> 00:02.000\nA\n\nSTYLE\n::cue {}\n",
+	}
+	for _, in := range badInputs {
+		_, _, err = ParseWebVTT(strings.NewReader(in), 1000)
+		if err == nil {
+			t.Errorf("no error for %q", in)
+		}
+	}
+}
@@ -1,7 +1,12 @@
 package mp4
 
 import (
+	"bytes"
+	"fmt"
+	"strings"
 	"testing"
+
+	"github.com/go-test/deep"
 )
 
 func TestVttc(t *testing.T) {
@@ -41,3 +46,254 @@ func TestVtta(t *testing.T) {
 	vtta := &VttaBox{CueAdditionalText: "This is a comment"}
 	boxDiffAfterEncodeAndDecode(t, vtta)
 }
+
+func TestDecodeWvttSample(t *testing.T) {
+	testCases := []struct {
+		name        string
+		boxes       []Box
+		wantedError bool
+	}{
+		{"empty", []Box{&VtteBox{}}, false},
+		{"cues", []Box{&VttcBox{}, &VttaBox{CueAdditionalText: "NOTE"}, &VttcBox{}}, false},
+		{"mixed", []Box{&VtteBox{}, &VttcBox{}}, true},
+		{"twoEmpty", []Box{&VtteBox{}, &VtteBox{}}, true},
+		{"otherBox", []Box{&VttcBox{}, &FreeBox{}}, true},
+	}
+	for _, tc := range testCases {
+		buf := bytes.Buffer{}
+		for _, box := range tc.boxes {
+			err := box.Encode(&buf)
+			if err != nil {
+				t.Error(err)
+			}
+		}
+		gotBoxes, err := DecodeWvttSample(buf.Bytes())
+		if tc.wantedError {
+			if err == nil {
+				t.Errorf("%s: no error", tc.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tc.name, err)
+		}
+		if len(gotBoxes) != len(tc.boxes) {
+			t.Errorf("%s: got %d boxes instead of %d", tc.name, len(gotBoxes), len(tc.boxes))
+		}
+	}
+	_, err := DecodeWvttSample(nil)
+	if err == nil {
+		t.Error("no error for empty sample data")
+	}
+}
+
+func TestVttcLineEndings(t *testing.T) {
+	vttc := CreateVttcBox("1", "align:start\r\n", "Line one\r\nLine two\rLine three\n", true)
+	if vttc.Sttg.Settings != "align:start\n" {
+		t.Errorf("got settings %q", vttc.Sttg.Settings)
+	}
+	wantedText := "Line one\nLine two\nLine three\n"
+	if vttc.Payl.CueText != wantedText {
+		t.Errorf("got cue text %q instead of %q", vttc.Payl.CueText, wantedText)
+	}
+	boxDiffAfterEncodeAndDecode(t, vttc)
+
+	raw := CreateVttcBox("", "", "a\r\nb", false)
+	if raw.Iden != nil || raw.Sttg != nil {
+		t.Error("empty iden or sttg created")
+	}
+	if raw.Payl.CueText != "a\r\nb" {
+		t.Errorf("cue text changed without normalization: %q", raw.Payl.CueText)
+	}
+	raw.NormalizeLineEndings()
+	if raw.Payl.CueText != "a\nb" {
+		t.Errorf("got cue text %q after normalization", raw.Payl.CueText)
+	}
+}
+
+func TestBuildSubtitleSegment(t *testing.T) {
+	frag, err := BuildSubtitleSegment(3, 2, 9000, 1000, "Hello\r\nworld", "align:left")
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := bytes.Buffer{}
+	err = frag.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := DecodeFile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decFrag := f.Segments[0].Fragments[0]
+	if decFrag.Moof.Mfhd.SequenceNumber != 3 || decFrag.Moof.Traf.Tfhd.TrackID != 2 {
+		t.Errorf("wrong sequence number or trackID")
+	}
+	samples, err := decFrag.GetFullSamples(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 1 || samples[0].DecodeTime != 9000 || samples[0].Dur != 1000 {
+		t.Fatalf("unexpected samples %v", samples)
+	}
+	boxes, err := DecodeWvttSample(samples[0].Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vttc := boxes[0].(*VttcBox)
+	if vttc.Payl.CueText != "Hello\nworld" || vttc.Sttg.Settings != "align:left" {
+		t.Errorf("got cue %q with settings %q", vttc.Payl.CueText, vttc.Sttg.Settings)
+	}
+}
+
+func TestWvttCueSources(t *testing.T) {
+	wvtt := NewWvttBox()
+	if wvtt.SourceLabel() != "" {
+		t.Errorf("got source label %q without vlab", wvtt.SourceLabel())
+	}
+	wvtt.AddChild(&VttCBox{Config: "WEBVTT"})
+	wvtt.AddChild(&VlabBox{SourceLabel: "urn:example:source"})
+	withVsid := CreateVttcBox("", "", "first", false)
+	withVsid.AddChild(&VsidBox{SourceID: 7})
+	boxes := []Box{withVsid, CreateVttcBox("", "", "second", false), &VttaBox{CueAdditionalText: "note"}}
+	got := wvtt.CueSources(boxes)
+	want := []CueSource{
+		{SourceLabel: "urn:example:source", SourceID: 7, HasSourceID: true},
+		{SourceLabel: "urn:example:source"},
+	}
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestVttcWithVtta(t *testing.T) {
+	vttc := CreateVttcBox("cue1", "align:left", "Hello", false)
+	vttc.AddChild(&VttaBox{CueAdditionalText: "NOTE first"})
+	vttc.AddChild(&VttaBox{CueAdditionalText: "NOTE second"})
+	boxDiffAfterEncodeAndDecode(t, vttc)
+
+	buf := bytes.Buffer{}
+	err := vttc.Encode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	box, err := DecodeBox(0, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decVttc := box.(*VttcBox)
+	if len(decVttc.Vttas) != 2 || decVttc.Vtta != decVttc.Vttas[0] {
+		t.Fatalf("got %d vtta boxes", len(decVttc.Vttas))
+	}
+	if decVttc.Vttas[1].CueAdditionalText != "NOTE second" {
+		t.Errorf("got vtta text %q", decVttc.Vttas[1].CueAdditionalText)
+	}
+	if decVttc.Children[len(decVttc.Children)-1] != decVttc.Vttas[1] {
+		t.Error("vtta boxes not last in children")
+	}
+}
+
+func TestCreateWvttSamples(t *testing.T) {
+	empty := CreateEmptyWvttSample(2000)
+	if empty.Dur != 2000 || empty.Size != 8 || !bytes.Equal(empty.Data, []byte{0, 0, 0, 8, 'v', 't', 't', 'e'}) {
+		t.Errorf("got empty sample dur=%d size=%d data=%x", empty.Dur, empty.Size, empty.Data)
+	}
+	cue := CreateWvttCueSample("1", "align:start", "Hello", 1500)
+	if cue.Dur != 1500 || int(cue.Size) != len(cue.Data) || !cue.IsSync() {
+		t.Errorf("got cue sample dur=%d size=%d len=%d", cue.Dur, cue.Size, len(cue.Data))
+	}
+	boxes, err := DecodeWvttSample(cue.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vttc := boxes[0].(*VttcBox)
+	if vttc.Iden.CueID != "1" || vttc.Sttg.Settings != "align:start" || vttc.Payl.CueText != "Hello" {
+		t.Errorf("got vttc with unexpected content")
+	}
+	frag, err := CreateFragment(1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cue.DecodeTime = 2000
+	frag.AddFullSample(*empty)
+	frag.AddFullSample(*cue)
+	if frag.Mdat.DataLength() != uint64(empty.Size+cue.Size) {
+		t.Errorf("got mdat length %d", frag.Mdat.DataLength())
+	}
+}
+
+func TestWvttInfo(t *testing.T) {
+	wvtt := NewWvttBox()
+	wvtt.AddChild(&VttCBox{Config: "WEBVTT"})
+	wvtt.AddChild(&VlabBox{SourceLabel: "src"})
+	wanted := "[wvtt] size=41\n" +
+		" - dataReferenceIndex: 1\n" +
+		"  [vttC] size=14\n" +
+		"   - config: \"WEBVTT\"\n" +
+		"  [vlab] size=11\n" +
+		"   - sourceLabel: src\n"
+	buf := bytes.Buffer{}
+	err := wvtt.Info(&buf, "wvtt:1", "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != wanted {
+		t.Errorf("got info\n%s\ninstead of\n%s", buf.String(), wanted)
+	}
+}
+
+func TestDecodeMalformedWvttSample(t *testing.T) {
+	testCases := []struct {
+		desc string
+		data []byte
+	}{
+		{"vsid without payload", []byte{0, 0, 0, 24, 'v', 't', 't', 'c', 0, 0, 0, 8, 'v', 's', 'i', 'd',
+			0, 0, 0, 8, 'p', 'a', 'y', 'l'}},
+		{"vtte with payload", []byte{0, 0, 0, 10, 'v', 't', 't', 'e', 0, 0}},
+		{"payl beyond end", []byte{0, 0, 0, 16, 'v', 't', 't', 'c', 0, 0, 0, 20, 'p', 'a', 'y', 'l'}},
+		{"truncated header", []byte{0, 0, 0, 8, 'v', 't'}},
+	}
+	for _, tc := range testCases {
+		_, err := DecodeWvttSample(tc.data)
+		if err == nil {
+			t.Errorf("%s: no error", tc.desc)
+		}
+	}
+}
+
+func TestWvttInfoMaxStringLen(t *testing.T) {
+	cueText := strings.Repeat("å", 100) // 200 bytes
+	payl := &PaylBox{CueText: cueText}
+	buf := bytes.Buffer{}
+	err := payl.Info(&buf, "", "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	wanted := fmt.Sprintf("[payl] size=208\n - cueText: %q... (200 bytes total)\n", strings.Repeat("å", 64))
+	if buf.String() != wanted {
+		t.Errorf("got info\n%s\ninstead of\n%s", buf.String(), wanted)
+	}
+	if payl.CueText != cueText {
+		t.Errorf("cue text changed by Info")
+	}
+
+	defer func(maxLen int) { DumpMaxStringLen = maxLen }(DumpMaxStringLen)
+	DumpMaxStringLen = 3 // Cut inside a two-byte character
+	buf.Reset()
+	err = (&VttCBox{Config: "åäö"}).Info(&buf, "", "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wanted := "[vttC] size=14\n - config: \"å\"... (6 bytes total)\n"; buf.String() != wanted {
+		t.Errorf("got info\n%s\ninstead of\n%s", buf.String(), wanted)
+	}
+	DumpMaxStringLen = 0
+	buf.Reset()
+	err = payl.Info(&buf, "", "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), cueText) {
+		t.Errorf("cue text cut without limit")
+	}
+}